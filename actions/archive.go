@@ -0,0 +1,324 @@
+package actions
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/qri-io/cafs"
+	"github.com/qri-io/dataset/dsfs"
+	"github.com/qri-io/qri/chunk"
+	"github.com/qri-io/qri/repo"
+)
+
+// ArchiveManifestFilename is the name of the file that describes the contents
+// of a dataset archive
+const ArchiveManifestFilename = "qri_archive.json"
+
+// ArchiveMaxFileSize is the largest file ImportArchive will read from an
+// archive, checked against both the size the archive claims & the bytes
+// actually read
+var ArchiveMaxFileSize int64 = 1 << 30
+
+// ArchiveManifest lists the contents of a dataset archive. Archives store
+// every file of every version of a dataset at the zip path matching its
+// content-addressed store path, so importing an archive recreates exactly
+// the same paths in any store of the same kind
+type ArchiveManifest struct {
+	// Ref is the dataset reference the archive was made from
+	Ref repo.DatasetRef `json:"ref"`
+	// Versions lists the path of every dataset version, newest first
+	Versions []string `json:"versions"`
+	// Files lists the store paths of all files in the archive
+	Files []string `json:"files"`
+}
+
+// WriteArchive writes a zip archive of the full history of a dataset to w
+func (act Dataset) WriteArchive(ref repo.DatasetRef, w io.Writer) error {
+	store := act.Store()
+	mf := ArchiveManifest{
+		Ref: repo.DatasetRef{
+			Peername:  ref.Peername,
+			ProfileID: ref.ProfileID,
+			Name:      ref.Name,
+			Path:      ref.Path,
+		},
+	}
+
+	files := map[string]bool{}
+	for path := ref.Path; path != "" && path != "/"; {
+		root := packageRoot(path)
+		mf.Versions = append(mf.Versions, root)
+
+		paths, prev, err := versionFiles(store, root)
+		if err != nil {
+			return fmt.Errorf("reading version %s: %s", root, err.Error())
+		}
+		for _, p := range paths {
+			files[p] = true
+		}
+		path = prev
+	}
+
+	for p := range files {
+		mf.Files = append(mf.Files, p)
+	}
+	sort.Strings(mf.Files)
+
+	zw := zip.NewWriter(w)
+	mfw, err := zw.Create(ArchiveManifestFilename)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(mfw)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(mf); err != nil {
+		return err
+	}
+
+	for _, p := range mf.Files {
+		f, err := store.Get(datastore.NewKey(p))
+		if err != nil {
+			return fmt.Errorf("getting %s: %s", p, err.Error())
+		}
+		fw, err := zw.Create(strings.TrimPrefix(p, "/"))
+		if err != nil {
+			f.Close()
+			return err
+		}
+		_, err = io.Copy(fw, f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("archiving %s: %s", p, err.Error())
+		}
+	}
+
+	return zw.Close()
+}
+
+// ImportArchive adds every version of the dataset in a zip archive to the
+// store, adding a reference to the latest version to the repo. Each file &
+// version is re-hashed as it's added, and any path that doesn't match the
+// archive manifest fails the import. Files are only pinned once every hash
+// matches
+func (act Dataset) ImportArchive(r io.ReaderAt, size int64) (ref repo.DatasetRef, err error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return ref, fmt.Errorf("reading archive: %s", err.Error())
+	}
+
+	entries := map[string]*zip.File{}
+	for _, f := range zr.File {
+		entries[f.Name] = f
+	}
+
+	mfe, ok := entries[ArchiveManifestFilename]
+	if !ok {
+		return ref, fmt.Errorf("archive has no %s. is this a qri dataset archive?", ArchiveManifestFilename)
+	}
+	mf := ArchiveManifest{}
+	if err = readZipJSON(mfe, &mf); err != nil {
+		return ref, fmt.Errorf("reading archive manifest: %s", err.Error())
+	}
+	if len(mf.Versions) == 0 {
+		return ref, fmt.Errorf("archive has no dataset versions")
+	}
+	// the reference must point at the newest version the archive verifies
+	if packageRoot(mf.Ref.Path) != mf.Versions[0] {
+		return ref, fmt.Errorf("archive reference path %s doesn't match its latest version %s", mf.Ref.Path, mf.Versions[0])
+	}
+
+	store := act.Store()
+	prefix := "/" + store.PathPrefix() + "/"
+	for _, p := range mf.Files {
+		if !strings.HasPrefix(p, prefix) {
+			return ref, fmt.Errorf("archive file %s can't be added to a /%s store", p, store.PathPrefix())
+		}
+	}
+
+	// files inside version packages are added as directories, all
+	// others are added one at a time
+	var pins []datastore.Key
+	packages := map[string]*cafs.Memdir{}
+	for _, v := range mf.Versions {
+		packages[v] = cafs.NewMemdir("/package")
+	}
+
+	for _, p := range mf.Files {
+		ze, ok := entries[strings.TrimPrefix(p, "/")]
+		if !ok {
+			return ref, fmt.Errorf("archive is missing file %s", p)
+		}
+		data, err := readZipFile(ze)
+		if err != nil {
+			return ref, fmt.Errorf("reading %s: %s", p, err.Error())
+		}
+
+		if root := packageRoot(p); root != p && packages[root] != nil {
+			packages[root].AddChildren(cafs.NewMemfileBytes(strings.TrimPrefix(p, root+"/"), data))
+			continue
+		}
+
+		name := p[strings.LastIndex(p, "/")+1:]
+		key, err := store.Put(cafs.NewMemfileBytes(name, data), false)
+		if err != nil {
+			return ref, fmt.Errorf("adding %s: %s", p, err.Error())
+		}
+		if key.String() != p {
+			return ref, fmt.Errorf("hash mismatch for %s: store computed %s", p, key.String())
+		}
+		pins = append(pins, key)
+	}
+
+	for _, v := range mf.Versions {
+		key, err := store.Put(packages[v], false)
+		if err != nil {
+			return ref, fmt.Errorf("adding version %s: %s", v, err.Error())
+		}
+		if key.String() != v {
+			return ref, fmt.Errorf("hash mismatch for version %s: store computed %s", v, key.String())
+		}
+		pins = append(pins, key)
+	}
+
+	if pinner, ok := store.(cafs.Pinner); ok {
+		for _, key := range pins {
+			if err = pinner.Pin(key, true); err != nil {
+				return ref, fmt.Errorf("pinning %s: %s", key.String(), err.Error())
+			}
+		}
+	}
+
+	ref = mf.Ref
+	ds, err := dsfs.LoadDataset(store, datastore.NewKey(ref.Path))
+	if err != nil {
+		return ref, fmt.Errorf("loading imported dataset: %s", err.Error())
+	}
+
+	// an existing reference can only be replaced by a later version of the
+	// same history
+	if prev, e := act.GetRef(repo.DatasetRef{Peername: ref.Peername, Name: ref.Name}); e == nil {
+		if !hasVersion(mf.Versions, packageRoot(prev.Path)) {
+			return ref, fmt.Errorf("dataset %s/%s already exists with a different history", ref.Peername, ref.Name)
+		}
+		if err = act.DeleteRef(prev); err != nil {
+			return ref, err
+		}
+	}
+
+	if err = act.PutRef(ref); err != nil {
+		return ref, fmt.Errorf("error putting dataset name in repo: %s", err.Error())
+	}
+	if err = act.LogEvent(repo.ETDsAdded, ref); err != nil {
+		return ref, err
+	}
+
+	ref.Dataset = ds.Encode()
+	return ref, nil
+}
+
+// versionFiles lists the store paths of all files that make up a single
+// dataset version, returning the path of the previous version
+func versionFiles(store cafs.Filestore, root string) (paths []string, prev string, err error) {
+	ds, err := dsfs.LoadDatasetRefs(store, datastore.NewKey(root))
+	if err != nil {
+		return nil, "", err
+	}
+	full, err := dsfs.LoadDataset(store, datastore.NewKey(root))
+	if err != nil {
+		return nil, "", err
+	}
+
+	paths = []string{root + "/" + dsfs.PackageFileDataset.String(), full.BodyPath}
+	if ds.Commit != nil {
+		paths = append(paths, ds.Commit.Path().String())
+	}
+	if ds.Meta != nil {
+		paths = append(paths, ds.Meta.Path().String())
+	}
+	if ds.Structure != nil {
+		paths = append(paths, ds.Structure.Path().String())
+	}
+	if ds.Transform != nil {
+		paths = append(paths, ds.Transform.Path().String())
+	}
+	if ds.Viz != nil {
+		paths = append(paths, ds.Viz.Path().String())
+	}
+	if full.Transform != nil {
+		paths = append(paths, full.Transform.ScriptPath)
+	}
+	if full.Viz != nil {
+		paths = append(paths, full.Viz.ScriptPath)
+	}
+	m, err := chunk.LoadManifest(store, full)
+	if err != nil {
+		return nil, "", err
+	}
+	if m != nil {
+		paths = append(paths, m.Paths()...)
+	}
+
+	// only keep paths that point into the store, dropping empty paths
+	prefix := "/" + store.PathPrefix() + "/"
+	kept := paths[:0]
+	for _, p := range paths {
+		if strings.HasPrefix(p, prefix) {
+			kept = append(kept, p)
+		}
+	}
+	return kept, ds.PreviousPath, nil
+}
+
+func hasVersion(versions []string, path string) bool {
+	for _, v := range versions {
+		if v == path {
+			return true
+		}
+	}
+	return false
+}
+
+// packageRoot trims a path to the root of the dataset package it belongs to,
+// eg: /ipfs/QmFoo/dataset.json -> /ipfs/QmFoo
+func packageRoot(path string) string {
+	parts := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 3)
+	if len(parts) < 2 {
+		return path
+	}
+	return "/" + parts[0] + "/" + parts[1]
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	if f.UncompressedSize64 > uint64(ArchiveMaxFileSize) {
+		return nil, fmt.Errorf("file is %d bytes, larger than the %d byte limit", f.UncompressedSize64, ArchiveMaxFileSize)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	// the uncompressed size is read from the archive, so don't trust it
+	data, err := ioutil.ReadAll(io.LimitReader(rc, ArchiveMaxFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > ArchiveMaxFileSize {
+		return nil, fmt.Errorf("file is larger than the %d byte limit", ArchiveMaxFileSize)
+	}
+	return data, nil
+}
+
+func readZipJSON(f *zip.File, v interface{}) error {
+	data, err := readZipFile(f)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,124 @@
+package actions
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/cafs"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/repo"
+	"github.com/qri-io/qri/repo/profile"
+)
+
+func testArchive(t *testing.T, rmf RepoMakerFunc) {
+	r, ref := createDataset(t, rmf)
+	act := Dataset{r}
+
+	// save a second version so the archive has history
+	ds := &dataset.Dataset{
+		PreviousPath: ref.Path,
+		Commit:       &dataset.Commit{Title: "update cities"},
+		Meta:         &dataset.Meta{Title: "cities, again"},
+		Structure:    &dataset.Structure{Format: dataset.CSVDataFormat, FormatConfig: &dataset.CSVOptions{HeaderRow: true}},
+	}
+	data, err := ioutil.ReadFile(testdataPath("cities/body.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref2, err := act.CreateDataset(ref.Name, ds, cafs.NewMemfileBytes("body.csv", data), CreateDatasetOptions{Pin: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := act.WriteArchive(ref2, buf); err != nil {
+		t.Fatalf("writing archive: %s", err.Error())
+	}
+
+	dst := Dataset{rmf(t)}
+	got, err := dst.ImportArchive(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("importing archive: %s", err.Error())
+	}
+	if got.Path != ref2.Path {
+		t.Errorf("imported path mismatch. expected: %s, got: %s", ref2.Path, got.Path)
+	}
+	if got.Dataset == nil || got.Dataset.PreviousPath != ref.Path {
+		t.Errorf("expected imported dataset to keep its history")
+	}
+	if err := dst.ReadDataset(&repo.DatasetRef{Path: ref.Path}); err != nil {
+		t.Errorf("expected previous version to be imported: %s", err.Error())
+	}
+	if _, err := dst.GetRef(got); err != nil {
+		t.Errorf("expected imported reference to be in the repo: %s", err.Error())
+	}
+
+	// tampering with any file should fail hash verification
+	tampered := rewriteArchive(t, buf.Bytes(), func(name string, data []byte) []byte {
+		if strings.HasSuffix(name, ".csv") {
+			data = append(data, []byte("\nnowhere,0,0,false")...)
+		}
+		return data
+	})
+	store := &pinStore{Filestore: cafs.NewMapstore(), pinned: map[string]bool{}}
+	tr, err := repo.NewMemRepo(testPeerProfile, store, profile.NewMemStore(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Dataset{tr}.ImportArchive(bytes.NewReader(tampered), int64(len(tampered)))
+	if err == nil || !strings.Contains(err.Error(), "hash mismatch") {
+		t.Errorf("expected tampered archive to fail with a hash mismatch, got: %v", err)
+	}
+	if len(store.pinned) != 0 {
+		t.Errorf("expected a failed import to pin nothing, got %d pins", len(store.pinned))
+	}
+
+	// the manifest reference has to point at the latest verified version
+	forged := rewriteArchive(t, buf.Bytes(), func(name string, data []byte) []byte {
+		if name == ArchiveManifestFilename {
+			return bytes.Replace(data, []byte(`"path": "`+ref2.Path), []byte(`"path": "`+ref.Path), 1)
+		}
+		return data
+	})
+	_, err = Dataset{rmf(t)}.ImportArchive(bytes.NewReader(forged), int64(len(forged)))
+	if err == nil || !strings.Contains(err.Error(), "doesn't match its latest version") {
+		t.Errorf("expected a forged reference to fail, got: %v", err)
+	}
+
+	// files over the size limit aren't read
+	defer func(max int64) { ArchiveMaxFileSize = max }(ArchiveMaxFileSize)
+	ArchiveMaxFileSize = 64
+	_, err = Dataset{rmf(t)}.ImportArchive(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err == nil || !strings.Contains(err.Error(), "byte limit") {
+		t.Errorf("expected files over the size limit to fail, got: %v", err)
+	}
+}
+
+// rewriteArchive copies a zip archive, replacing each file's contents with
+// the result of calling edit
+func rewriteArchive(t *testing.T, archive []byte, edit func(name string, data []byte) []byte) []byte {
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	for _, f := range zr.File {
+		data, err := readZipFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w, err := zw.Create(f.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(edit(f.Name, data))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
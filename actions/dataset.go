@@ -8,8 +8,11 @@ import (
 	"github.com/qri-io/cafs"
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/dataset/dsfs"
+	"github.com/qri-io/qri/chunk"
+	"github.com/qri-io/qri/compress"
 	"github.com/qri-io/qri/repo"
 	"github.com/qri-io/qri/repo/profile"
+	"github.com/qri-io/qri/validation"
 )
 
 // Dataset wraps a repo.Repo, adding actions related to working
@@ -18,8 +21,32 @@ type Dataset struct {
 	repo.Repo
 }
 
+// CreateDatasetOptions configures how CreateDataset stores a dataset. The
+// zero value stores an unpinned, uncompressed & unchunked body under the
+// default validation policy
+type CreateDatasetOptions struct {
+	// Secrets are passed to the dataset's transform
+	Secrets map[string]string
+	// Pin pins the dataset in the store
+	Pin bool
+	// Policy sets how bodies with validation errors are treated. under
+	// validation.PolicyStrict they're refused with a validation.PolicyError,
+	// other policies save them, recording the number of errors in the
+	// structure. validation.PolicyOff only differs in what callers report
+	Policy validation.Policy
+	// Compression is the format the body is stored compressed in, which is
+	// recorded in the dataset's structure
+	Compression compress.Format
+	// ChunkSize splits the body into chunks of roughly ChunkSize bytes if
+	// greater than zero
+	ChunkSize int
+	// NoTransformCache always executes the dataset's transform, instead of
+	// reusing a result from the repo's transform cache
+	NoTransformCache bool
+}
+
 // CreateDataset initializes a dataset from a dataset pointer and data file
-func (act Dataset) CreateDataset(name string, ds *dataset.Dataset, data cafs.File, secrets map[string]string, pin bool) (ref repo.DatasetRef, err error) {
+func (act Dataset) CreateDataset(name string, ds *dataset.Dataset, data cafs.File, opts CreateDatasetOptions) (ref repo.DatasetRef, err error) {
 	log.Debugf("CreateDataset: %s", name)
 	var (
 		path datastore.Key
@@ -47,9 +74,10 @@ func (act Dataset) CreateDataset(name string, ds *dataset.Dataset, data cafs.Fil
 		ds.Commit.Author = &dataset.User{ID: pro.ID.String()}
 	}
 
+	var tfCacheKey string
 	if ds.Transform != nil {
 		log.Info("running transformation...")
-		data, err = act.ExecTransform(ds, data, secrets)
+		data, tfCacheKey, err = act.execTransformCached(repo.DatasetRef{Peername: pro.Peername, Name: name}, ds, data, opts.Secrets, !opts.NoTransformCache)
 		if err != nil {
 			return
 		}
@@ -57,11 +85,31 @@ func (act Dataset) CreateDataset(name string, ds *dataset.Dataset, data cafs.Fil
 		ds.Assign(userSet)
 	}
 
+	if opts.Policy == validation.PolicyStrict {
+		var cleanup func()
+		if data, cleanup, err = validateStrict(ds, data); err != nil {
+			return
+		}
+		defer cleanup()
+	}
+
 	if err = act.PrepareViz(ds); err != nil {
 		return
 	}
 
-	path, err = dsfs.CreateDataset(act.Store(), ds, data, act.PrivateKey(), pin)
+	store := act.Store()
+	if data != nil {
+		// a new body replaces any compression the previous version recorded
+		compress.SetStructureFormat(ds, opts.Compression)
+		if opts.Compression != compress.None {
+			store = compress.NewStore(store, opts.Compression, data.FileName())
+		}
+	}
+	if opts.ChunkSize > 0 && data != nil {
+		store = chunk.NewStore(store, ds, data.FileName(), opts.ChunkSize)
+	}
+
+	path, err = dsfs.CreateDataset(store, ds, data, act.PrivateKey(), opts.Pin)
 	if err != nil {
 		return
 	}
@@ -95,8 +143,15 @@ func (act Dataset) CreateDataset(name string, ds *dataset.Dataset, data cafs.Fil
 		return
 	}
 
+	if tc, ok := act.Repo.(repo.TransformCache); ok && tfCacheKey != "" {
+		if err = tc.PutTransformResult(tfCacheKey, ref.Path); err != nil {
+			log.Debug(err.Error())
+			return
+		}
+	}
+
 	_, storeIsPinner := act.Store().(cafs.Pinner)
-	if pin && storeIsPinner {
+	if opts.Pin && storeIsPinner {
 		act.LogEvent(repo.ETDsPinned, ref)
 	}
 	return
@@ -139,6 +194,12 @@ func (act Dataset) AddDataset(ref *repo.DatasetRef) (err error) {
 		return fmt.Errorf("error loading newly saved dataset path: %s", path.String())
 	}
 
+	// chunked bodies are only a manifest until their chunks are fetched
+	if _, err = chunk.Fetch(act.Store(), ds); err != nil {
+		log.Debug(err.Error())
+		return fmt.Errorf("error fetching body chunks: %s", err.Error())
+	}
+
 	ref.Dataset = ds.Encode()
 	return
 }
@@ -181,12 +242,64 @@ func (act Dataset) PinDataset(ref repo.DatasetRef) error {
 // UnpinDataset unmarks a dataset for retention in a store
 func (act Dataset) UnpinDataset(ref repo.DatasetRef) error {
 	if pinner, ok := act.Store().(cafs.Pinner); ok {
+		// body chunks are pinned on their own when they're added, they aren't
+		// part of the dataset they belong to
+		chunks, err := act.unsharedChunks(ref.Path)
+		if err != nil {
+			log.Debug(err.Error())
+		}
+		for _, path := range chunks {
+			pinner.Unpin(datastore.NewKey(path), true)
+		}
 		pinner.Unpin(datastore.NewKey(ref.Path), true)
 		return act.LogEvent(repo.ETDsUnpinned, ref)
 	}
 	return repo.ErrNotPinner
 }
 
+// unsharedChunks lists the body chunks of the dataset at path that previous
+// versions of the dataset don't also use, returning nil if the body isn't
+// chunked. Previous versions stay pinned, so the chunks they share have to
+// stay pinned with them
+func (act Dataset) unsharedChunks(path string) ([]string, error) {
+	store := act.Store()
+	ds, err := dsfs.LoadDataset(store, datastore.NewKey(path))
+	if err != nil {
+		return nil, err
+	}
+	m, err := chunk.LoadManifest(store, ds)
+	if err != nil || m == nil {
+		return nil, err
+	}
+
+	shared := map[string]bool{}
+	for prev := ds.PreviousPath; prev != "" && prev != "/"; {
+		// history that isn't in the store isn't pinned here either
+		pds, err := dsfs.LoadDataset(store, datastore.NewKey(prev))
+		if err != nil {
+			break
+		}
+		pm, err := chunk.LoadManifest(store, pds)
+		if err != nil {
+			return nil, err
+		}
+		if pm != nil {
+			for _, p := range pm.Paths() {
+				shared[p] = true
+			}
+		}
+		prev = pds.PreviousPath
+	}
+
+	var chunks []string
+	for _, p := range m.Paths() {
+		if !shared[p] {
+			chunks = append(chunks, p)
+		}
+	}
+	return chunks, nil
+}
+
 // DeleteDataset removes a dataset from the store
 func (act Dataset) DeleteDataset(ref repo.DatasetRef) error {
 	pro, err := act.Profile()
@@ -5,11 +5,16 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/ipfs/go-datastore"
 	"github.com/libp2p/go-libp2p-crypto"
 	"github.com/qri-io/cafs"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsfs"
 	"github.com/qri-io/dataset/dstest"
+	"github.com/qri-io/qri/chunk"
 	"github.com/qri-io/qri/repo"
 	"github.com/qri-io/qri/repo/profile"
 	"github.com/qri-io/registry/regserver/mock"
@@ -71,6 +76,9 @@ func DatasetTests(t *testing.T, rmf RepoMakerFunc) {
 		testDatasetPinning,
 		testDeleteDataset,
 		testEventsLog,
+		testTransformCache,
+		testValidationPolicy,
+		testArchive,
 	} {
 		test(t, rmf)
 	}
@@ -91,7 +99,7 @@ func createDataset(t *testing.T, rmf RepoMakerFunc) (repo.Repo, repo.DatasetRef)
 		return r, repo.DatasetRef{}
 	}
 
-	ref, err := act.CreateDataset(tc.Name, tc.Input, tc.BodyFile(), nil, true)
+	ref, err := act.CreateDataset(tc.Name, tc.Input, tc.BodyFile(), CreateDatasetOptions{Pin: true})
 	if err != nil {
 		t.Error(err.Error())
 	}
@@ -160,7 +168,7 @@ func testDatasetPinning(t *testing.T, rmf RepoMakerFunc) {
 		return
 	}
 
-	ref2, err := act.CreateDataset(tc.Name, tc.Input, tc.BodyFile(), nil, false)
+	ref2, err := act.CreateDataset(tc.Name, tc.Input, tc.BodyFile(), CreateDatasetOptions{})
 	if err != nil {
 		t.Error(err.Error())
 		return
@@ -182,6 +190,93 @@ func testDatasetPinning(t *testing.T, rmf RepoMakerFunc) {
 	}
 }
 
+// pinStore is a store that keeps track of pins
+type pinStore struct {
+	cafs.Filestore
+	pinned map[string]bool
+}
+
+func (s *pinStore) Put(file cafs.File, pin bool) (datastore.Key, error) {
+	key, err := s.Filestore.Put(file, pin)
+	if err == nil && pin {
+		s.pinned[key.String()] = true
+	}
+	return key, err
+}
+
+func (s *pinStore) Pin(key datastore.Key, recursive bool) error {
+	s.pinned[key.String()] = true
+	return nil
+}
+
+func (s *pinStore) Unpin(key datastore.Key, recursive bool) error {
+	delete(s.pinned, key.String())
+	return nil
+}
+
+func TestUnpinChunks(t *testing.T) {
+	store := &pinStore{Filestore: cafs.NewMapstore(), pinned: map[string]bool{}}
+	r, err := repo.NewMemRepo(testPeerProfile, store, profile.NewMemStore(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	act := Dataset{r}
+
+	body := func(n int) cafs.File {
+		rows := make([]string, n)
+		for i := range rows {
+			rows[i] = fmt.Sprintf(`{"id":%d,"name":"row %d"}`, i, i)
+		}
+		return cafs.NewMemfileBytes("body.json", []byte("["+strings.Join(rows, ",")+"]"))
+	}
+	newDs := func(prev string) *dataset.Dataset {
+		return &dataset.Dataset{
+			PreviousPath: prev,
+			Commit:       &dataset.Commit{Title: "chunked"},
+			Structure:    &dataset.Structure{Format: dataset.JSONDataFormat, Schema: dataset.BaseSchemaArray},
+		}
+	}
+
+	a, err := act.CreateDataset("chunked", newDs(""), body(500), CreateDatasetOptions{Pin: true, ChunkSize: 256})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := act.CreateDataset("chunked", newDs(a.Path), body(520), CreateDatasetOptions{Pin: true, ChunkSize: 256})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manifest := func(path string) *chunk.Manifest {
+		ds, err := dsfs.LoadDataset(store, datastore.NewKey(path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, err := chunk.LoadManifest(store, ds)
+		if err != nil || m == nil {
+			t.Fatalf("expected a chunked body. error: %v", err)
+		}
+		return m
+	}
+	ma, mb := manifest(a.Path), manifest(b.Path)
+
+	if err := act.UnpinDataset(b); err != nil {
+		t.Fatal(err)
+	}
+
+	shared := map[string]bool{}
+	for _, p := range ma.Paths() {
+		shared[p] = true
+		if !store.pinned[p] {
+			t.Errorf("expected chunk %s of the previous version to stay pinned", p)
+		}
+	}
+	for _, p := range mb.Paths() {
+		if !shared[p] && store.pinned[p] {
+			t.Errorf("expected chunk %s to be unpinned", p)
+		}
+	}
+}
+
 func testDeleteDataset(t *testing.T, rmf RepoMakerFunc) {
 	r, ref := createDataset(t, rmf)
 	act := Dataset{r}
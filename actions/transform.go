@@ -1,18 +1,41 @@
 package actions
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"sort"
+	"time"
 
+	"github.com/ipfs/go-datastore"
 	"github.com/qri-io/cafs"
 	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsfs"
 	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/qri/chunk"
+	"github.com/qri-io/qri/metrics"
 	"github.com/qri-io/qri/repo"
 	"github.com/qri-io/skytf"
 )
 
+// transformDuration tracks how long transforms take to execute, by whether
+// they succeeded. cached results aren't counted
+var transformDuration = metrics.Default.NewHistogram("qri_transform_duration_seconds", "transform execution durations", metrics.DefaultBuckets, "status")
+
 // ExecTransform executes a designated transformation
 func (act Dataset) ExecTransform(ds *dataset.Dataset, infile cafs.File, secrets map[string]string) (file cafs.File, err error) {
+	start := time.Now()
+	defer func() {
+		status := "ok"
+		if err != nil {
+			status = "error"
+		}
+		transformDuration.Observe(time.Since(start).Seconds(), status)
+	}()
+
 	filepath := ds.Transform.ScriptPath
 	rr, err := skytf.ExecFile(ds, filepath, infile, func(o *skytf.ExecOpts) {
 		if secrets != nil {
@@ -74,3 +97,127 @@ func (act Dataset) ExecTransform(ds *dataset.Dataset, infile cafs.File, secrets
 	ds.Structure = st
 	return cafs.NewMemfileBytes(fmt.Sprintf("data.%s", st.Format.String()), buf.Bytes()), nil
 }
+
+// execTransformCached checks the repo's transform cache for a result before
+// executing a transform. On a hit ds is populated from the cached result &
+// cacheKey is empty. On a miss the transform is executed & the returned
+// cacheKey should be recorded once the result dataset has a path.
+// repos that don't implement repo.TransformCache always execute, as do
+// transforms run with useCache false. transforms that read inputs the cache
+// key can't see, like remote data, should skip the cache
+func (act Dataset) execTransformCached(ref repo.DatasetRef, ds *dataset.Dataset, infile cafs.File, secrets map[string]string, useCache bool) (file cafs.File, cacheKey string, err error) {
+	tc, ok := act.Repo.(repo.TransformCache)
+	if !ok || !useCache {
+		file, err = act.ExecTransform(ds, infile, secrets)
+		return
+	}
+
+	cacheKey, infile, err = transformCacheKey(ds, infile, secrets)
+	if err != nil {
+		return
+	}
+
+	if file, err = act.cachedTransformResult(tc, cacheKey, ds); err == nil {
+		return file, "", act.LogEvent(repo.ETTransformCacheHit, ref)
+	}
+
+	if err = act.LogEvent(repo.ETTransformCacheMiss, ref); err != nil {
+		return
+	}
+	file, err = act.ExecTransform(ds, infile, secrets)
+	return
+}
+
+// cachedTransformResult loads a previous transform result, setting the
+// structure & metadata the transform produced on ds
+func (act Dataset) cachedTransformResult(tc repo.TransformCache, key string, ds *dataset.Dataset) (cafs.File, error) {
+	path, err := tc.TransformResult(key)
+	if err != nil {
+		return nil, err
+	}
+
+	prev, err := dsfs.LoadDataset(act.Store(), datastore.NewKey(path))
+	if err != nil {
+		log.Debugf("error loading cached transform result %s: %s", path, err.Error())
+		return nil, err
+	}
+	if prev.Structure == nil || prev.BodyPath == "" {
+		return nil, repo.ErrNotFound
+	}
+
+	file, err := chunk.LoadBody(act.Store(), prev)
+	if err != nil {
+		log.Debugf("error loading cached transform body %s: %s", prev.BodyPath, err.Error())
+		return nil, err
+	}
+
+	// transforms can set metadata, which needs to carry over from the cached run
+	if prev.Meta != nil {
+		prev.Meta.SetPath("")
+		ds.Meta = prev.Meta
+	}
+	ds.Structure = &dataset.Structure{
+		Format: dataset.JSONDataFormat,
+		Schema: prev.Structure.Schema,
+	}
+
+	return cafs.NewMemfileReader(fmt.Sprintf("data.%s", dataset.JSONDataFormat.String()), file), nil
+}
+
+// transformCacheKey hashes everything that can change the output of a transform:
+// script bytes, the input body, the schema & transform config the script is
+// given, and the provided secrets. Secret values only ever contribute to the
+// hash & are never stored. infile is consumed while hashing, so a replacement
+// file is returned
+func transformCacheKey(ds *dataset.Dataset, infile cafs.File, secrets map[string]string) (key string, file cafs.File, err error) {
+	script, err := ioutil.ReadFile(ds.Transform.ScriptPath)
+	if err != nil {
+		return
+	}
+
+	h := sha256.New()
+	h.Write(script)
+
+	if infile != nil {
+		var data []byte
+		if data, err = ioutil.ReadAll(infile); err != nil {
+			return
+		}
+		bodyHash := sha256.Sum256(data)
+		h.Write([]byte("\nbody:"))
+		h.Write(bodyHash[:])
+		file = cafs.NewMemfileBytes(infile.FileName(), data)
+	}
+
+	if ds.Structure != nil && ds.Structure.Schema != nil {
+		var schema []byte
+		if schema, err = json.Marshal(ds.Structure.Schema); err != nil {
+			return
+		}
+		h.Write([]byte("\nschema:"))
+		h.Write(schema)
+	}
+
+	if ds.Transform.Config != nil {
+		// json encoding sorts map keys, so equal configs hash the same
+		var cfg []byte
+		if cfg, err = json.Marshal(ds.Transform.Config); err != nil {
+			return
+		}
+		h.Write([]byte("\nconfig:"))
+		h.Write(cfg)
+	}
+
+	names := make([]string, 0, len(secrets))
+	for name := range secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		valueHash := sha256.Sum256([]byte(secrets[name]))
+		h.Write([]byte("\nsecret:" + name + ":"))
+		h.Write(valueHash[:])
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), file, nil
+}
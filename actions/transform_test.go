@@ -0,0 +1,94 @@
+package actions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/jsonschema"
+	"github.com/qri-io/qri/repo"
+)
+
+func testTransformCache(t *testing.T, rmf RepoMakerFunc) {
+	r := rmf(t)
+	r.SetProfile(testPeerProfile)
+	act := Dataset{r}
+
+	if _, ok := r.(repo.TransformCache); !ok {
+		return
+	}
+
+	scriptPath := filepath.Join(os.Getenv("GOPATH"), "/src/github.com/qri-io/qri/lib/testdata/tf/transform.sky")
+	newDs := func() *dataset.Dataset {
+		return &dataset.Dataset{
+			Commit:    &dataset.Commit{Title: "transform"},
+			Structure: &dataset.Structure{Format: dataset.JSONDataFormat, Schema: jsonschema.Must(`{"type":"array"}`)},
+			Transform: &dataset.Transform{ScriptPath: scriptPath},
+		}
+	}
+
+	timed := transformDuration.Count("ok")
+	a, err := act.CreateDataset("tf_a", newDs(), nil, CreateDatasetOptions{})
+	if err != nil {
+		t.Fatalf("error creating first transform dataset: %s", err.Error())
+	}
+	b, err := act.CreateDataset("tf_b", newDs(), nil, CreateDatasetOptions{Secrets: map[string]string{"token": "secret"}})
+	if err != nil {
+		t.Fatalf("error creating second transform dataset: %s", err.Error())
+	}
+	c, err := act.CreateDataset("tf_c", newDs(), nil, CreateDatasetOptions{})
+	if err != nil {
+		t.Fatalf("error creating third transform dataset: %s", err.Error())
+	}
+	if _, err := act.CreateDataset("tf_d", newDs(), nil, CreateDatasetOptions{Secrets: map[string]string{"token": "other secret"}}); err != nil {
+		t.Fatalf("error creating transform dataset with a different secret: %s", err.Error())
+	}
+	configured := newDs()
+	configured.Transform.Config = map[string]interface{}{"city": "toronto"}
+	if _, err := act.CreateDataset("tf_e", configured, nil, CreateDatasetOptions{}); err != nil {
+		t.Fatalf("error creating configured transform dataset: %s", err.Error())
+	}
+	if _, err := act.CreateDataset("tf_f", newDs(), nil, CreateDatasetOptions{NoTransformCache: true}); err != nil {
+		t.Fatalf("error creating uncached transform dataset: %s", err.Error())
+	}
+
+	events, err := r.Events(100, 0)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	hits, misses, executed := 0, 0, 0
+	for _, e := range events {
+		switch e.Type {
+		case repo.ETTransformCacheHit:
+			hits++
+		case repo.ETTransformCacheMiss:
+			misses++
+		case repo.ETTransformExecuted:
+			executed++
+		}
+	}
+	// different secrets & config are a different set of inputs, and skipping
+	// the cache always executes
+	if misses != 4 || executed != 5 {
+		t.Errorf("expected 4 cache misses & 5 transform executions, got: %d misses, %d executions", misses, executed)
+	}
+	if hits != 1 {
+		t.Errorf("expected 1 cache hit, got: %d", hits)
+	}
+	if got := transformDuration.Count("ok") - timed; got != 5 {
+		t.Errorf("expected 5 timed transform executions, got: %d", got)
+	}
+
+	for _, ref := range []*repo.DatasetRef{&a, &b, &c} {
+		if err := act.ReadDataset(ref); err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+	if a.Dataset.BodyPath != c.Dataset.BodyPath {
+		t.Errorf("expected cached result to reuse body path. expected: '%s', got: '%s'", a.Dataset.BodyPath, c.Dataset.BodyPath)
+	}
+	if c.Dataset.Meta == nil || c.Dataset.Meta.Title != "bar" {
+		t.Errorf("expected cached result to carry over transform metadata")
+	}
+}
@@ -0,0 +1,59 @@
+package actions
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/qri-io/cafs"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/qri/validation"
+)
+
+// validateStrict checks a body against the dataset schema, returning a
+// validation.PolicyError if the body has any errors. The body is consumed
+// while validating, so it's spooled to a temp file & a replacement file
+// reading from the spool is returned, along with a func that removes the
+// spool. Bodies without a schema pass, their schema will be detected from the
+// body itself
+func validateStrict(ds *dataset.Dataset, data cafs.File) (cafs.File, func(), error) {
+	noop := func() {}
+	if data == nil || ds.Structure == nil || ds.Structure.Schema == nil {
+		return data, noop, nil
+	}
+
+	// bodies can be many gigabytes, spool to disk instead of memory
+	spool, err := ioutil.TempFile("", "qri_validate")
+	if err != nil {
+		return nil, noop, fmt.Errorf("error creating body spool: %s", err.Error())
+	}
+	cleanup := func() {
+		spool.Close()
+		os.Remove(spool.Name())
+	}
+
+	r, err := dsio.NewEntryReader(ds.Structure, io.TeeReader(data, spool))
+	if err != nil {
+		cleanup()
+		return nil, noop, fmt.Errorf("error reading body: %s", err.Error())
+	}
+
+	report, err := validation.EntryReader(r, ds.Structure.Schema, validation.DefaultMaxErrors)
+	if err != nil {
+		cleanup()
+		return nil, noop, err
+	}
+	if report.ErrCount > 0 {
+		cleanup()
+		return nil, noop, validation.PolicyError{Policy: validation.PolicyStrict, Report: report}
+	}
+
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		cleanup()
+		return nil, noop, fmt.Errorf("error reading body spool: %s", err.Error())
+	}
+	// glue whatever we just read back onto the reader
+	return cafs.NewMemfileReader(data.FileName(), io.MultiReader(spool, data)), cleanup, nil
+}
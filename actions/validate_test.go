@@ -0,0 +1,47 @@
+package actions
+
+import (
+	"testing"
+
+	"github.com/qri-io/cafs"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dstest"
+	"github.com/qri-io/qri/validation"
+)
+
+func testValidationPolicy(t *testing.T, rmf RepoMakerFunc) {
+	r := rmf(t)
+	r.SetProfile(testPeerProfile)
+	act := Dataset{r}
+
+	tc, err := dstest.NewTestCaseFromDir(testdataPath("cities"))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// valid bodies pass a strict policy
+	if _, err := act.CreateDataset(tc.Name, tc.Input, tc.BodyFile(), CreateDatasetOptions{Pin: true, Policy: validation.PolicyStrict}); err != nil {
+		t.Fatalf("expected valid body to pass strict policy. got: %s", err)
+	}
+
+	tc.Input.Structure.Format = dataset.JSONDataFormat
+	tc.Input.Structure.FormatConfig = nil
+	body := []byte(`[["chatham", "lots", 41.3, true]]`)
+
+	_, err = act.CreateDataset(tc.Name, tc.Input, cafs.NewMemfileBytes("body.json", body), CreateDatasetOptions{Pin: true, Policy: validation.PolicyStrict})
+	perr, ok := err.(validation.PolicyError)
+	if !ok {
+		t.Fatalf("expected strict policy to return a PolicyError, got: %v", err)
+	}
+	if perr.Report.ErrCount != 1 || perr.Report.Errors[0].Column != "pop" {
+		t.Errorf("unexpected validation report: %v", perr.Report.Errors)
+	}
+
+	// use a fresh repo, the valid body above already holds this name
+	r = rmf(t)
+	r.SetProfile(testPeerProfile)
+	act = Dataset{r}
+	if _, err := act.CreateDataset(tc.Name, tc.Input, cafs.NewMemfileBytes("body.json", body), CreateDatasetOptions{Pin: true, Policy: validation.PolicyWarn}); err != nil {
+		t.Errorf("expected invalid body to pass warn policy. got: %s", err)
+	}
+}
@@ -0,0 +1,234 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	util "github.com/datatogether/api/apiutil"
+	"github.com/ipfs/go-datastore"
+	"github.com/qri-io/dataset/dsfs"
+	"github.com/qri-io/qri/repo"
+	"github.com/qri-io/qri/repo/profile"
+)
+
+// datasetPathPrefixes are routes that address a single dataset with the rest
+// of their path, eg: /body/peer/cities
+var datasetPathPrefixes = []string{"/save/", "/remove/", "/add/", "/export/", "/body/", "/render/", "/history/", "/registry/"}
+
+// authCheck verifies the api token or request signature of a request when
+// tokens are required, writing a 401 response for a missing or invalid token
+// or signature & a 403 for a token that doesn't grant access to the request.
+// It returns false if a response was written, & the request to continue
+// handling otherwise
+func (s *Server) authCheck(w http.ResponseWriter, r *http.Request) (*http.Request, bool) {
+	if !s.cfg.API.RequireTokens {
+		return r, true
+	}
+	required, ok := requiredScope(r)
+	if !ok {
+		return r, true
+	}
+
+	ts, ok := s.qriNode.Repo.(repo.TokenStore)
+	if !ok {
+		util.WriteErrResponse(w, http.StatusInternalServerError, repo.ErrTokensNotSupported)
+		return r, false
+	}
+
+	var tok *repo.Token
+	if strings.HasPrefix(r.Header.Get("Authorization"), SignatureScheme+" ") {
+		id, err := s.verifySignedRequest(w, r, time.Now())
+		if err == errSignedBodyTooLarge {
+			util.WriteErrResponse(w, http.StatusRequestEntityTooLarge, err)
+			return r, false
+		} else if err != nil {
+			w.Header().Set("WWW-Authenticate", SignatureScheme+` realm="qri"`)
+			util.WriteErrResponse(w, http.StatusUnauthorized, err)
+			return r, false
+		}
+		if tok, err = s.peerGrant(ts, id); err != nil {
+			util.WriteErrResponse(w, http.StatusForbidden, err)
+			return r, false
+		}
+	} else {
+		secret := bearerToken(r)
+		if secret == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="qri"`)
+			util.WriteErrResponse(w, http.StatusUnauthorized, fmt.Errorf("an api token is required"))
+			return r, false
+		}
+		var err error
+		if tok, err = repo.VerifyToken(ts, secret); err != nil {
+			if err != repo.ErrInvalidToken {
+				log.Infof("error verifying api token: %s", err.Error())
+			}
+			w.Header().Set("WWW-Authenticate", `Bearer realm="qri", error="invalid_token"`)
+			util.WriteErrResponse(w, http.StatusUnauthorized, repo.ErrInvalidToken)
+			return r, false
+		}
+	}
+
+	if !tok.Scope.Allows(required) {
+		util.WriteErrResponse(w, http.StatusForbidden, fmt.Errorf("this request requires a token with %s scope", required))
+		return r, false
+	}
+	if len(tok.Datasets) > 0 {
+		if err := s.datasetAccess(tok, r); err != nil {
+			util.WriteErrResponse(w, http.StatusForbidden, err)
+			return r, false
+		}
+		// events can be about any dataset, event handlers filter them to the
+		// datasets the token is limited to
+		r = r.WithContext(context.WithValue(r.Context(), TokenDatasetsCtxKey, tok.Datasets))
+	}
+
+	return r, true
+}
+
+// datasetAccess checks a token limited to a set of datasets grants access
+// to the dataset a request addresses. Requests that don't address a dataset
+// the token can be checked against are refused, apart from routes that
+// don't expose dataset data
+func (s *Server) datasetAccess(tok *repo.Token, r *http.Request) error {
+	switch {
+	case r.URL.Path == "/events" || r.URL.Path == "/events/stream":
+		return nil
+	case r.URL.Path == "/peers" || r.URL.Path == "/connections" || strings.HasPrefix(r.URL.Path, "/peers/"):
+		return nil
+	}
+
+	ref := s.requestDatasetRef(r)
+	if ref.Peername != "" && ref.Name != "" && !tok.MatchesDataset(ref.Peername, ref.Name) {
+		return fmt.Errorf("token doesn't grant access to %s", ref.AliasString())
+	}
+	if !s.tokenAllowsRef(tok, ref) {
+		return fmt.Errorf("token is limited to datasets: %s", strings.Join(tok.Datasets, ", "))
+	}
+	return nil
+}
+
+// tokenAllowsRef checks ref against the datasets a token is limited to.
+// Refs that address a version by path are resolved to the dataset the
+// version belongs to. Refs that can't be resolved aren't allowed
+func (s *Server) tokenAllowsRef(tok *repo.Token, ref repo.DatasetRef) bool {
+	named := ref.Peername != "" && ref.Name != ""
+	if ref.Path == "" {
+		return named && tok.MatchesDataset(ref.Peername, ref.Name)
+	}
+
+	head := repo.DatasetRef{Path: ref.Path}
+	if named {
+		head = repo.DatasetRef{Peername: ref.Peername, Name: ref.Name}
+	}
+	if err := repo.CanonicalizeDatasetRef(s.qriNode.Repo, &head); err != nil {
+		return false
+	}
+	if !tok.MatchesDataset(head.Peername, head.Name) {
+		return false
+	}
+	return s.inHistory(head.Path, ref.Path)
+}
+
+// inHistory checks if path is the dataset version at head or one of the
+// versions before it
+func (s *Server) inHistory(head, path string) bool {
+	trim := func(p string) string {
+		return strings.TrimSuffix(p, "/"+dsfs.PackageFileDataset.String())
+	}
+	path = trim(path)
+	for head != "" && head != "/" {
+		if trim(head) == path {
+			return true
+		}
+		ds, err := dsfs.LoadDataset(s.qriNode.Repo.Store(), datastore.NewKey(head))
+		if err != nil {
+			return false
+		}
+		head = ds.PreviousPath
+	}
+	return false
+}
+
+// peerGrant finds the access granted to the peer that signed a request.
+// this node's own profile has full access, other peers must be known to the
+// repo & have been granted access with `qri token create --peer`
+func (s *Server) peerGrant(ts repo.TokenStore, id string) (*repo.Token, error) {
+	if pro, err := s.qriNode.Repo.Profile(); err == nil && pro.ID.String() == id {
+		return &repo.Token{Scope: repo.TSAdmin, ProfileID: id}, nil
+	}
+
+	pid, err := profile.IDB58Decode(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid profile id: %s", id)
+	}
+	if _, err := s.qriNode.Repo.Profiles().GetProfile(pid); err != nil {
+		return nil, fmt.Errorf("unknown profile: %s", id)
+	}
+	tok, err := repo.PeerGrant(ts, id)
+	if err != nil {
+		if err == repo.ErrNotFound {
+			return nil, fmt.Errorf("profile %s hasn't been granted access", id)
+		}
+		return nil, err
+	}
+	return tok, nil
+}
+
+// requiredScope gives the token scope needed to make a request, returning
+// false if the request doesn't need a token
+func requiredScope(r *http.Request) (repo.TokenScope, bool) {
+	path := r.URL.Path
+	switch {
+	case r.Method == "OPTIONS" || path == "/status":
+		return "", false
+	case r.Method == "DELETE" || path == "/config" || path == "/rpc" || strings.HasPrefix(path, "/remove/") || strings.HasPrefix(path, "/connect/"):
+		return repo.TSAdmin, true
+	case r.Method == "GET" || path == "/graphql":
+		// graphql only supports queries
+		return repo.TSRead, true
+	case path == "/me" || strings.HasPrefix(path, "/profile"):
+		return repo.TSAdmin, true
+	default:
+		return repo.TSWrite, true
+	}
+}
+
+// bearerToken reads the token from a request's Authorization header
+func bearerToken(r *http.Request) string {
+	auth := r.Header.Get("Authorization")
+	if len(auth) > len("bearer ") && strings.EqualFold(auth[:len("bearer ")], "bearer ") {
+		return strings.TrimSpace(auth[len("bearer "):])
+	}
+	return ""
+}
+
+// requestDatasetRef finds the dataset a request addresses, if any, resolving
+// the "me" peername to this node's profile
+func (s *Server) requestDatasetRef(r *http.Request) repo.DatasetRef {
+	ref := DatasetRefFromCtx(r.Context())
+	if strings.HasPrefix(r.URL.Path, "/me/") {
+		ref, _ = DatasetRefFromPath(r.URL.Path)
+	}
+	for _, prefix := range datasetPathPrefixes {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			ref, _ = DatasetRefFromPath(strings.TrimPrefix(r.URL.Path, prefix))
+			break
+		}
+	}
+	if r.URL.Path == "/validate" {
+		ref, _ = repo.ParseDatasetRef(r.URL.Query().Get("ref"))
+	}
+	if strings.HasPrefix(r.URL.Path, "/ipfs/") {
+		ref = repo.DatasetRef{Path: r.URL.Path}
+	}
+
+	if ref.Peername == "me" {
+		if pro, err := s.qriNode.Repo.Profile(); err == nil {
+			ref.Peername = pro.Peername
+		}
+	}
+	return ref
+}
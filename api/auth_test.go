@@ -0,0 +1,166 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	golog "github.com/ipfs/go-log"
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/repo"
+	"github.com/qri-io/qri/repo/test"
+)
+
+func TestAuthCheck(t *testing.T) {
+	// bump up log level to keep test output clean
+	golog.SetLogLevel("qriapi", "error")
+	defer golog.SetLogLevel("qriapi", "info")
+
+	r, err := test.NewTestRepo(nil)
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err.Error())
+	}
+
+	lib.Config = config.DefaultConfig()
+	lib.Config.Profile = test.ProfileConfig()
+
+	s, err := New(r, func(c *config.Config) {
+		c.P2P.Enabled = false
+		c.API.RequireTokens = true
+		c.RPC.HTTP = true
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	token := func(scope repo.TokenScope, datasets ...string) string {
+		tok, secret, err := repo.NewToken(scope, datasets)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if err := r.PutToken(tok); err != nil {
+			t.Fatal(err.Error())
+		}
+		return secret
+	}
+
+	read := token(repo.TSRead)
+	cities := token(repo.TSWrite, "peer/cities")
+	admin := token(repo.TSAdmin, "peer/cities")
+	root := token(repo.TSAdmin)
+
+	path := func(name string) string {
+		ref := repo.DatasetRef{Peername: "peer", Name: name}
+		if err := repo.CanonicalizeDatasetRef(r, &ref); err != nil {
+			t.Fatal(err.Error())
+		}
+		return ref.Path
+	}
+	citiesPath, moviesPath := path("cities"), path("movies")
+
+	cases := []struct {
+		method, endpoint, token string
+		status                  int
+	}{
+		{"GET", "/status", "", 200},
+		{"OPTIONS", "/list", "", 200},
+		{"GET", "/list", "", 401},
+		{"GET", "/list", "nope.nope", 401},
+		{"GET", "/list", read, 200},
+		{"POST", "/save/me/cities", read, 403},
+		{"GET", "/list", cities, 403},
+		{"GET", "/search?q=cities", cities, 403},
+		{"GET", "/diff?left=me/cities&right=me/movies", cities, 403},
+		{"GET", "/me/cities", cities, 200},
+		{"GET", "/me/movies", cities, 403},
+		{"GET", "/body/@" + citiesPath, cities, 200},
+		{"GET", "/body/@" + moviesPath, cities, 403},
+		{"GET", "/body/me/cities/at" + moviesPath, cities, 403},
+		{"GET", "/history/@" + moviesPath, cities, 403},
+		{"GET", "/events", cities, 200},
+		{"POST", "/rename", cities, 403},
+		{"POST", "/remove/me/cities", cities, 403},
+		{"POST", "/remove/me/movies", admin, 403},
+		{"POST", "/profile", cities, 403},
+		{"GET", "/config", read, 403},
+		{"GET", "/validate?ref=me/cities", cities, 200},
+		{"GET", "/validate?ref=me/movies", cities, 403},
+		{"POST", "/graphql", read, 400},
+		{"POST", "/graphql", cities, 403},
+		{"POST", "/rpc", "", 401},
+		{"POST", "/rpc", read, 403},
+		{"POST", "/rpc", admin, 403},
+		// admin tokens get through auth, this request is refused for not
+		// being JSON
+		{"POST", "/rpc", root, 415},
+	}
+
+	routes := NewServerRoutes(s)
+	for i, c := range cases {
+		req := httptest.NewRequest(c.method, c.endpoint, nil)
+		if c.token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+		w := httptest.NewRecorder()
+		routes.ServeHTTP(w, req)
+		if w.Code != c.status {
+			t.Errorf("case %d: %s %s status mismatch. expected: %d, got: %d. body: %s", i, c.method, c.endpoint, c.status, w.Code, w.Body.String())
+		}
+		if w.Code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("case %d: expected 401 response to set a WWW-Authenticate header", i)
+		}
+	}
+}
+
+func TestAuthCheckEvents(t *testing.T) {
+	golog.SetLogLevel("qriapi", "error")
+	defer golog.SetLogLevel("qriapi", "info")
+
+	r, err := test.NewTestRepo(nil)
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err.Error())
+	}
+
+	lib.Config = config.DefaultConfig()
+	lib.Config.Profile = test.ProfileConfig()
+
+	s, err := New(r, func(c *config.Config) {
+		c.P2P.Enabled = false
+		c.API.RequireTokens = true
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	tok, secret, err := repo.NewToken(repo.TSRead, []string{"peer/cities"})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := r.PutToken(tok); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// events about other datasets are left out, even when asked for
+	for _, endpoint := range []string{"/events", "/events?dataset=peer/movies"} {
+		req := httptest.NewRequest("GET", endpoint, nil)
+		req.Header.Set("Authorization", "Bearer "+secret)
+		w := httptest.NewRecorder()
+		NewServerRoutes(s).ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected status 200, got: %d. body: %s", endpoint, w.Code, w.Body.String())
+		}
+		res := struct {
+			Data []eventResponse `json:"data"`
+		}{}
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatal(err.Error())
+		}
+		for _, e := range res.Data {
+			if e.Ref == nil || e.Ref.Name != "cities" {
+				t.Errorf("%s: expected only events about peer/cities, got: %v", endpoint, e.Ref)
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// NameCacheMaxAge is how long responses to requests that address a dataset
+// by name can be cached. names move to new versions when datasets are saved,
+// so this is kept short
+var NameCacheMaxAge = time.Minute
+
+// immutableCacheControl is sent with responses to requests that address a
+// dataset by path. content-addressed data never changes
+const immutableCacheControl = "max-age=31536000, immutable"
+
+// datasetETag creates a strong ETag for a response describing the dataset at
+// path. variant distinguishes different responses about the same dataset,
+// like pages of a body
+func datasetETag(path string, variant ...string) string {
+	h := sha256.New()
+	h.Write([]byte(path))
+	for _, v := range variant {
+		h.Write([]byte{0})
+		h.Write([]byte(v))
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// setDatasetCacheHeaders sets ETag & Cache-Control headers. byPath should be
+// true when the request addressed the dataset by path, which makes the
+// response immutable. responses to authenticated requests are only
+// cacheable by the client that made them
+func setDatasetCacheHeaders(w http.ResponseWriter, r *http.Request, etag string, byPath bool) {
+	scope := "public"
+	if r.Header.Get("Authorization") != "" {
+		scope = "private"
+	}
+
+	w.Header().Set("ETag", etag)
+	if byPath {
+		w.Header().Set("Cache-Control", fmt.Sprintf("%s, %s", scope, immutableCacheControl))
+	} else {
+		w.Header().Set("Cache-Control", fmt.Sprintf("%s, max-age=%d", scope, int(NameCacheMaxAge.Seconds())))
+	}
+}
+
+// writeNotModified responds with 304 Not Modified if the request's
+// If-None-Match header matches etag, returning true if it did
+func writeNotModified(w http.ResponseWriter, r *http.Request, etag string, byPath bool) bool {
+	if !etagMatches(r.Header.Get("If-None-Match"), etag) {
+		return false
+	}
+	setDatasetCacheHeaders(w, r, etag, byPath)
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// etagMatches checks an If-None-Match header value against etag, using the
+// weak comparison RFC 7232 specifies for If-None-Match
+func etagMatches(header, etag string) bool {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return false
+	}
+	if header == "*" {
+		return true
+	}
+	for _, tag := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(tag), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	golog "github.com/ipfs/go-log"
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/repo"
+	"github.com/qri-io/qri/repo/test"
+)
+
+func TestETagMatches(t *testing.T) {
+	etag := datasetETag("/map/QmVU86zb7A6NvipimEJ7mQFu1jy2nk96o6f3uwHe92D8US")
+	cases := []struct {
+		header string
+		expect bool
+	}{
+		{"", false},
+		{"*", true},
+		{etag, true},
+		{"W/" + etag, true},
+		{`"foo", ` + etag, true},
+		{`"foo"`, false},
+		{strings.Trim(etag, `"`), false},
+	}
+	for i, c := range cases {
+		if got := etagMatches(c.header, etag); got != c.expect {
+			t.Errorf("case %d: '%s' match mismatch. expected: %t, got: %t", i, c.header, c.expect, got)
+		}
+	}
+
+	if datasetETag("/map/Qm", "body", "limit=1") == datasetETag("/map/Qm", "body", "limit=2") {
+		t.Errorf("expected etag variants to differ")
+	}
+}
+
+func TestDatasetCacheHeaders(t *testing.T) {
+	// bump up log level to keep test output clean
+	golog.SetLogLevel("qriapi", "error")
+	defer golog.SetLogLevel("qriapi", "info")
+
+	r, err := test.NewTestRepo(nil)
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err.Error())
+	}
+	ref, err := r.GetRef(repo.DatasetRef{Peername: "peer", Name: "movies"})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	dsh := NewDatasetHandlers(r, false)
+
+	do := func(handler http.HandlerFunc, endpoint string, headers ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", endpoint, nil)
+		for i := 0; i+1 < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+
+	w := do(dsh.GetHandler, "/me/movies")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status mismatch. expected: %d, got: %d", http.StatusOK, w.Code)
+	}
+	etag := w.Header().Get("ETag")
+	if etag != datasetETag(ref.Path) {
+		t.Errorf("etag mismatch. expected: %s, got: %s", datasetETag(ref.Path), etag)
+	}
+	if cc := w.Header().Get("Cache-Control"); cc != "public, max-age=60" {
+		t.Errorf("name-based cache control mismatch. got: %s", cc)
+	}
+
+	w = do(dsh.GetHandler, "/me/movies", "If-None-Match", etag)
+	if w.Code != http.StatusNotModified {
+		t.Errorf("status mismatch. expected: %d, got: %d", http.StatusNotModified, w.Code)
+	}
+	if w.Body.Len() != 0 || w.Header().Get("ETag") != etag {
+		t.Errorf("expected an empty not modified response carrying the etag")
+	}
+
+	w = do(dsh.GetHandler, "/me/movies/at"+ref.Path)
+	if cc := w.Header().Get("Cache-Control"); cc != "public, "+immutableCacheControl {
+		t.Errorf("path-based cache control mismatch. got: %s", cc)
+	}
+	if w.Header().Get("ETag") != etag {
+		t.Errorf("expected path-based etag to match name-based etag")
+	}
+
+	w = do(dsh.GetHandler, "/me/movies", "Authorization", "Bearer token")
+	if cc := w.Header().Get("Cache-Control"); !strings.HasPrefix(cc, "private") {
+		t.Errorf("expected authenticated responses to be private. got: %s", cc)
+	}
+
+	a := do(dsh.BodyHandler, "/body/me/movies?limit=1")
+	b := do(dsh.BodyHandler, "/body/me/movies?limit=2")
+	if a.Code != http.StatusOK || b.Code != http.StatusOK {
+		t.Fatalf("body status mismatch. expected: %d, got: %d, %d", http.StatusOK, a.Code, b.Code)
+	}
+	if a.Header().Get("ETag") == "" || a.Header().Get("ETag") == b.Header().Get("ETag") {
+		t.Errorf("expected pages of a body to have different etags. got: %s, %s", a.Header().Get("ETag"), b.Header().Get("ETag"))
+	}
+	w = do(dsh.BodyHandler, "/body/me/movies?limit=1", "If-None-Match", a.Header().Get("ETag"))
+	if w.Code != http.StatusNotModified {
+		t.Errorf("body status mismatch. expected: %d, got: %d", http.StatusNotModified, w.Code)
+	}
+
+	// errors aren't cached
+	w = do(dsh.GetHandler, "/me/not_a_dataset")
+	if w.Header().Get("ETag") != "" || w.Header().Get("Cache-Control") != "" {
+		t.Errorf("expected error responses to have no cache headers")
+	}
+}
+
+func TestRenderCacheHeaders(t *testing.T) {
+	golog.SetLogLevel("qriapi", "error")
+	defer golog.SetLogLevel("qriapi", "info")
+
+	r, err := test.NewTestRepo(nil)
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err.Error())
+	}
+	ref, err := r.GetRef(repo.DatasetRef{Peername: "peer", Name: "movies"})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	// the render config is modified below, restore the global config after
+	defer func(cfg *config.Config) { lib.Config = cfg }(lib.Config)
+	lib.Config = config.DefaultConfig()
+
+	etag := renderETag(ref.Path, "")
+	req := httptest.NewRequest("GET", "/render/me/movies/at"+ref.Path, nil)
+	req.Header.Set("If-None-Match", etag)
+	w := httptest.NewRecorder()
+	NewRenderHandlers(r).RenderHandler(w, req)
+	if w.Code != http.StatusNotModified {
+		t.Fatalf("status mismatch. expected: %d, got: %d", http.StatusNotModified, w.Code)
+	}
+	// the default template can change, so renders aren't immutable
+	if cc := w.Header().Get("Cache-Control"); strings.Contains(cc, "immutable") {
+		t.Errorf("expected rendered pages not to be immutable. got: %s", cc)
+	}
+
+	lib.Config.Render.DefaultTemplateHash = "/ipfs/QmUpdatedTemplate"
+	if renderETag(ref.Path, "") == etag {
+		t.Errorf("expected updating the default template to change render etags")
+	}
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	util "github.com/datatogether/api/apiutil"
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/lib"
+)
+
+// ConfigHandlers wraps the lib configuration to interface with http.HandlerFunc
+type ConfigHandlers struct {
+	ReadOnly bool
+}
+
+// NewConfigHandlers allocates a ConfigHandlers pointer
+func NewConfigHandlers(readOnly bool) *ConfigHandlers {
+	return &ConfigHandlers{ReadOnly: readOnly}
+}
+
+// ConfigHandler reads & updates qri configuration. Private values like keys
+// are never included in responses
+func (h *ConfigHandlers) ConfigHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "OPTIONS":
+		util.EmptyOkHandler(w, r)
+	case "GET":
+		if h.ReadOnly {
+			readOnlyResponse(w, "/config")
+			return
+		}
+		h.getConfigHandler(w, r)
+	case "POST", "PUT":
+		if h.ReadOnly {
+			readOnlyResponse(w, "/config")
+			return
+		}
+		h.setConfigHandler(w, r)
+	default:
+		util.NotFoundHandler(w, r)
+	}
+}
+
+func (h *ConfigHandlers) getConfigHandler(w http.ResponseWriter, r *http.Request) {
+	if lib.Config == nil {
+		util.WriteErrResponse(w, http.StatusInternalServerError, fmt.Errorf("no configuration loaded"))
+		return
+	}
+
+	p := &lib.GetConfigParams{
+		Format:  "json",
+		Concise: true,
+		Field:   r.FormValue("field"),
+	}
+	var data []byte
+	if err := lib.GetConfig(p, &data); err != nil {
+		if p.Field != "" {
+			util.WriteErrResponse(w, http.StatusBadRequest, err)
+			return
+		}
+		log.Infof("error getting config: %s", err.Error())
+		util.WriteErrResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	util.WriteResponse(w, json.RawMessage(data))
+}
+
+// setConfigHandler updates configuration from a JSON object of
+// case.insensitive.dot.separated.paths to values, eg: {"api.port": 2504},
+// responding with the values that were set
+func (h *ConfigHandlers) setConfigHandler(w http.ResponseWriter, r *http.Request) {
+	if lib.Config == nil {
+		util.WriteErrResponse(w, http.StatusInternalServerError, fmt.Errorf("no configuration loaded"))
+		return
+	}
+
+	changes := map[string]interface{}{}
+	if err := json.NewDecoder(r.Body).Decode(&changes); err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("error decoding request body: %s", err.Error()))
+		return
+	}
+	if len(changes) == 0 {
+		util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("no configuration values provided"))
+		return
+	}
+
+	cfg := lib.Config.Copy()
+	ip := config.ImmutablePaths()
+	for path, value := range changes {
+		path = strings.ToLower(path)
+		if ip[path] {
+			util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("cannot set path %s", path))
+			return
+		}
+		if strings.HasPrefix(path, "profile.") {
+			util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("cannot set path %s, use the /profile endpoint to edit profile details", path))
+			return
+		}
+		if err := cfg.Set(path, value); err != nil {
+			util.WriteErrResponse(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("error validating config: %s", err))
+		return
+	}
+	if err := lib.SetConfig(cfg); err != nil {
+		log.Infof("error setting config: %s", err.Error())
+		util.WriteErrResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	res := map[string]interface{}{}
+	for path := range changes {
+		if v, err := lib.Config.Get(path); err == nil {
+			res[path] = v
+		}
+	}
+	util.WriteResponse(w, res)
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/beme/abide"
+	golog "github.com/ipfs/go-log"
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/repo/test"
+)
+
+func TestConfigHandler(t *testing.T) {
+	// bump up log level to keep test output clean
+	golog.SetLogLevel("qriapi", "error")
+	defer golog.SetLogLevel("qriapi", "info")
+
+	lib.Config = config.DefaultConfig()
+	lib.Config.Profile = test.ProfileConfig()
+	prevSaveConfig := lib.SaveConfig
+	lib.SaveConfig = func() error { return nil }
+	defer func() { lib.SaveConfig = prevSaveConfig }()
+
+	cases := []struct {
+		name, method, endpoint string
+		body                   []byte
+		readOnly               bool
+	}{
+		{"OPTIONS", "OPTIONS", "/config", nil, false},
+		{"GET field", "GET", "/config?field=api.port", nil, false},
+		{"GET invalid field", "GET", "/config?field=api.nope", nil, false},
+		{"GET read-only", "GET", "/config", nil, true},
+		{"POST", "POST", "/config", []byte(`{"api.port": 2504}`), false},
+		{"POST bad data", "POST", "/config", []byte(``), false},
+		{"POST invalid type", "POST", "/config", []byte(`{"api.port": "2504"}`), false},
+		{"POST profile", "POST", "/config", []byte(`{"profile.peername": "steve"}`), false},
+		{"POST read-only", "POST", "/config", []byte(`{"api.port": 2504}`), true},
+		{"bad method", "DELETE", "/config", nil, false},
+	}
+
+	ch := NewConfigHandlers(false)
+	for _, c := range cases {
+		name := fmt.Sprintf("Config Test: %s", c.name)
+		req := httptest.NewRequest(c.method, c.endpoint, bytes.NewBuffer(c.body))
+		w := httptest.NewRecorder()
+
+		ch.ReadOnly = c.readOnly
+		ch.ConfigHandler(w, req)
+		abide.AssertHTTPResponse(t, name, w.Result())
+	}
+
+	if lib.Config.API.Port != 2504 {
+		t.Errorf("expected api port to be set to 2504, got: %d", lib.Config.API.Port)
+	}
+	if lib.Config.Profile.PrivKey == "" {
+		t.Errorf("expected setting config to keep private values")
+	}
+}
@@ -18,6 +18,10 @@ type QriCtxKey string
 // to a context.Context
 const DatasetRefCtxKey QriCtxKey = "datasetRef"
 
+// TokenDatasetsCtxKey is the key for adding the datasets an api token is
+// limited to to a context.Context
+const TokenDatasetsCtxKey QriCtxKey = "tokenDatasets"
+
 // DatasetRefFromReq examines the path element of a request URL
 // to
 func DatasetRefFromReq(r *http.Request) (repo.DatasetRef, error) {
@@ -54,3 +58,10 @@ func HTTPPathToQriPath(path string) string {
 	path = strings.TrimPrefix(path, "/")
 	return path
 }
+
+// TokenDatasetsFromCtx extracts the datasets the request's api token is
+// limited to from a context, returning nil if the token isn't limited
+func TokenDatasetsFromCtx(ctx context.Context) []string {
+	datasets, _ := ctx.Value(TokenDatasetsCtxKey).([]string)
+	return datasets
+}
@@ -9,15 +9,19 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	util "github.com/datatogether/api/apiutil"
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/dataset/dsutil"
 	"github.com/qri-io/dsdiff"
+	"github.com/qri-io/qri/chunk"
+	"github.com/qri-io/qri/compress"
 	"github.com/qri-io/qri/lib"
 	"github.com/qri-io/qri/repo"
 	"github.com/qri-io/qri/repo/profile"
+	"github.com/qri-io/qri/validation"
 )
 
 // DatasetHandlers wraps a requests struct to interface with http.HandlerFunc
@@ -186,12 +190,36 @@ func (h *DatasetHandlers) ZipDatasetHandler(w http.ResponseWriter, r *http.Reque
 	}
 }
 
+// ValidateHandler is the endpoint for validating a dataset body against its
+// schema, or data & schema files uploaded as mime/multipart form data
+func (h *DatasetHandlers) ValidateHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "OPTIONS":
+		util.EmptyOkHandler(w, r)
+	case "GET", "POST":
+		if h.ReadOnly {
+			readOnlyResponse(w, "/validate")
+			return
+		}
+		h.validateHandler(w, r)
+	default:
+		util.NotFoundHandler(w, r)
+	}
+}
+
 func (h *DatasetHandlers) zipDatasetHandler(w http.ResponseWriter, r *http.Request) {
 	args, err := DatasetRefFromPath(r.URL.Path[len("/export"):])
 	if err != nil {
 		util.WriteErrResponse(w, http.StatusBadRequest, err)
 		return
 	}
+	byPath := args.Path != ""
+	// zips exported before bodies were decompressed on export may be cached,
+	// the etag version makes clients fetch them again
+	if byPath && writeNotModified(w, r, datasetETag(args.Path, "zip", "v2"), byPath) {
+		return
+	}
+
 	res := &repo.DatasetRef{}
 	err = h.Get(&args, res)
 	if err != nil {
@@ -207,9 +235,13 @@ func (h *DatasetHandlers) zipDatasetHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	if res.Path != "" {
+		setDatasetCacheHeaders(w, r, datasetETag(res.Path, "zip", "v2"), byPath)
+	}
 	w.Header().Set("Content-Type", "application/zip")
 	w.Header().Set("Content-Disposition", fmt.Sprintf("filename=\"%s.zip\"", "dataset"))
-	dsutil.WriteZipArchive(h.repo.Store(), ds, w)
+	// bodies may be stored compressed or chunked, export them whole
+	dsutil.WriteZipArchive(chunk.NewBodyStore(h.repo.Store(), ds), compress.Decompressed(ds), w)
 }
 
 func (h *DatasetHandlers) listHandler(w http.ResponseWriter, r *http.Request) {
@@ -234,17 +266,24 @@ func (h *DatasetHandlers) getHandler(w http.ResponseWriter, r *http.Request) {
 		util.WriteErrResponse(w, http.StatusBadRequest, err)
 		return
 	}
+	byPath := args.Path != ""
 
 	if err = repo.CanonicalizeDatasetRef(h.repo, &args); err != nil {
 		util.WriteErrResponse(w, http.StatusInternalServerError, err)
 		return
 	}
+	if args.Path != "" && writeNotModified(w, r, datasetETag(args.Path), byPath) {
+		return
+	}
 
 	err = h.Get(&args, res)
 	if err != nil {
 		util.WriteErrResponse(w, http.StatusInternalServerError, err)
 		return
 	}
+	if res.Path != "" {
+		setDatasetCacheHeaders(w, r, datasetETag(res.Path), byPath)
+	}
 	util.WriteResponse(w, res)
 }
 
@@ -401,8 +440,12 @@ func (h *DatasetHandlers) initHandler(w http.ResponseWriter, r *http.Request) {
 
 	res := &repo.DatasetRef{}
 	p := &lib.SaveParams{
-		Dataset: dsp,
-		Private: r.FormValue("private") == "true",
+		Dataset:     dsp,
+		Private:     r.FormValue("private") == "true",
+		Sheet:       r.FormValue("sheet"),
+		Compression: r.FormValue("compression"),
+
+		NoTransformCache: r.FormValue("no_cache") == "true",
 	}
 	if err := h.New(p, res); err != nil {
 		log.Infof("error initializing dataset: %s", err.Error())
@@ -538,10 +581,26 @@ func (h *DatasetHandlers) saveHandler(w http.ResponseWriter, r *http.Request) {
 
 	res := &repo.DatasetRef{}
 	p := &lib.SaveParams{
-		Dataset: dsp,
-		Private: r.FormValue("private") == "true",
+		Dataset:     dsp,
+		Private:     r.FormValue("private") == "true",
+		Sheet:       r.FormValue("sheet"),
+		Compression: r.FormValue("compression"),
+		Refresh:     r.FormValue("refresh") == "true",
+
+		NoTransformCache: r.FormValue("no_cache") == "true",
 	}
 	if err := h.Save(p, res); err != nil {
+		if err == repo.ErrBodyNotModified {
+			// like the CLI, finding nothing to save isn't an error. respond
+			// with the current version
+			cur := &repo.DatasetRef{Peername: dsp.Peername, Name: dsp.Name}
+			if err := h.Get(cur, res); err != nil {
+				util.WriteErrResponse(w, http.StatusInternalServerError, err)
+				return
+			}
+			util.WriteResponse(w, SaveResponse{DatasetRef: *res, Unchanged: true})
+			return
+		}
 		util.WriteErrResponse(w, http.StatusInternalServerError, err)
 		return
 	}
@@ -631,6 +690,13 @@ func loadFileIfPath(path string) (file *os.File, err error) {
 // TODO - should move this into lib
 const defaultDataLimit = 100
 
+// SaveResponse is the response to a save that found the dataset body
+// unchanged, carrying the current version of the dataset
+type SaveResponse struct {
+	repo.DatasetRef
+	Unchanged bool `json:"unchanged"`
+}
+
 // DataResponse is the struct used to respond to api requests made to the /data endpoint
 // It is necessary because we need to include the 'path' field in the response
 type DataResponse struct {
@@ -644,9 +710,17 @@ func (h DatasetHandlers) bodyHandler(w http.ResponseWriter, r *http.Request) {
 		util.WriteErrResponse(w, http.StatusBadRequest, err)
 		return
 	}
+	byPath := d.Path != ""
 
 	if err := repo.CanonicalizeDatasetRef(h.repo, &d); err != nil {
 		util.WriteErrResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// pagination params change the response, so they're part of the etag
+	etag := datasetETag(d.Path, "body", r.URL.Query().Encode())
+	if d.Path != "" && writeNotModified(w, r, etag, byPath) {
+		return
 	}
 
 	limit, err := util.ReqParamInt("limit", r)
@@ -679,7 +753,61 @@ func (h DatasetHandlers) bodyHandler(w http.ResponseWriter, r *http.Request) {
 		Path: result.Path,
 		Data: json.RawMessage(result.Data),
 	}
+	if d.Path != "" {
+		setDatasetCacheHeaders(w, r, etag, byPath)
+	}
 	if err := util.WritePageResponse(w, dataResponse, r, page); err != nil {
 		log.Infof("error writing repsonse: %s", err.Error())
 	}
 }
+
+func (h *DatasetHandlers) validateHandler(w http.ResponseWriter, r *http.Request) {
+	ref, err := repo.ParseDatasetRef(r.FormValue("ref"))
+	if err != nil && err != repo.ErrEmptyRef {
+		util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("error parsing ref param: %s", err.Error()))
+		return
+	}
+
+	p := &lib.ValidateDatasetParams{Ref: ref}
+	if v := r.FormValue("max_errors"); v != "" {
+		if p.MaxErrors, err = strconv.Atoi(v); err != nil {
+			util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("invalid max_errors param: %s", v))
+			return
+		}
+	}
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		bodyfile, bodyHeader, err := r.FormFile("body")
+		if err != nil && err != http.ErrMissingFile {
+			util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("error opening body file: %s", err))
+			return
+		}
+		if bodyfile != nil {
+			defer bodyfile.Close()
+			p.Data = bodyfile
+			p.DataFilename = bodyHeader.Filename
+		}
+
+		schemafile, _, err := r.FormFile("schema")
+		if err != nil && err != http.ErrMissingFile {
+			util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("error opening schema file: %s", err))
+			return
+		}
+		if schemafile != nil {
+			defer schemafile.Close()
+			p.Schema = schemafile
+		}
+	}
+
+	res := &validation.Report{}
+	if err := h.Validate(p, res); err != nil {
+		if e, ok := err.(lib.Error); ok && e.Message() != "" {
+			util.WriteErrResponse(w, http.StatusBadRequest, errors.New(e.Message()))
+			return
+		}
+		log.Infof("error validating dataset: %s", err.Error())
+		util.WriteErrResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
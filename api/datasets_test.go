@@ -0,0 +1,112 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/beme/abide"
+	golog "github.com/ipfs/go-log"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/repo"
+	"github.com/qri-io/qri/repo/test"
+	"github.com/qri-io/qri/validation"
+)
+
+func TestValidateHandler(t *testing.T) {
+	// bump up log level to keep test output clean
+	golog.SetLogLevel("qriapi", "error")
+	defer golog.SetLogLevel("qriapi", "info")
+
+	r, err := test.NewTestRepo(nil)
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err.Error())
+	}
+
+	cases := []struct {
+		name, method, endpoint string
+		readOnly               bool
+	}{
+		{"OPTIONS", "OPTIONS", "/validate", false},
+		{"GET no ref", "GET", "/validate", false},
+		{"GET bad max_errors", "GET", "/validate?ref=peer/movies&max_errors=many", false},
+		{"GET read-only", "GET", "/validate?ref=peer/movies", true},
+		{"bad method", "DELETE", "/validate", false},
+	}
+
+	dsh := NewDatasetHandlers(r, false)
+	for _, c := range cases {
+		name := fmt.Sprintf("Validate Test: %s", c.name)
+		req := httptest.NewRequest(c.method, c.endpoint, nil)
+		w := httptest.NewRecorder()
+
+		dsh.ReadOnly = c.readOnly
+		dsh.ValidateHandler(w, req)
+		abide.AssertHTTPResponse(t, name, w.Result())
+	}
+
+	dsh.ReadOnly = false
+	w := httptest.NewRecorder()
+	dsh.ValidateHandler(w, httptest.NewRequest("GET", "/validate?ref=peer/movies", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected validating a dataset to respond with 200, got: %d. body: %s", w.Code, w.Body.String())
+	}
+	res := struct {
+		Data *validation.Report `json:"data"`
+	}{}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err.Error())
+	}
+	if res.Data == nil || res.Data.Entries == 0 {
+		t.Errorf("expected report to check dataset entries, got: %s", w.Body.String())
+	}
+}
+
+func TestSaveHandlerUnchanged(t *testing.T) {
+	r, err := test.NewTestRepo(nil)
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err.Error())
+	}
+
+	etag := `"v1"`
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Write([]byte("city,pop,avg_age,in_usa\ntoronto,40000000,55.5,false\nnew york,8500000,44.4,true\n"))
+	}))
+	defer s.Close()
+
+	saved := &repo.DatasetRef{}
+	dsp := &dataset.DatasetPod{Peername: "me", Name: "cities", BodyPath: s.URL + "/cities.csv"}
+	if err := lib.NewDatasetRequests(r, nil).Save(&lib.SaveParams{Dataset: dsp}, saved); err != nil {
+		t.Fatal(err)
+	}
+
+	// refreshing an unchanged body responds with the current version
+	req := httptest.NewRequest("POST", "/save/me/cities?refresh=true", strings.NewReader("{}"))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	NewDatasetHandlers(r, false).SaveHandler(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected an unchanged save to respond with 200, got: %d. body: %s", w.Code, w.Body.String())
+	}
+	res := struct {
+		Data SaveResponse `json:"data"`
+	}{}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err.Error())
+	}
+	if !res.Data.Unchanged {
+		t.Errorf("expected response to be flagged unchanged")
+	}
+	if res.Data.Path != saved.Path {
+		t.Errorf("path mismatch. expected: %s, got: %s", saved.Path, res.Data.Path)
+	}
+}
@@ -0,0 +1,259 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	util "github.com/datatogether/api/apiutil"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/repo"
+)
+
+// DefaultStreamHeartbeat is how often an idle event stream sends a
+// keep-alive comment
+const DefaultStreamHeartbeat = 30 * time.Second
+
+// EventHandlers wraps a repo to interface with http.HandlerFunc
+type EventHandlers struct {
+	repo     repo.Repo
+	ReadOnly bool
+	// Heartbeat is how often idle event streams send a keep-alive comment
+	Heartbeat time.Duration
+}
+
+// NewEventHandlers allocates an EventHandlers pointer
+func NewEventHandlers(r repo.Repo, readOnly bool) *EventHandlers {
+	return &EventHandlers{repo: r, ReadOnly: readOnly, Heartbeat: DefaultStreamHeartbeat}
+}
+
+// EventsHandler lists logged events, most recent first
+func (h *EventHandlers) EventsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "OPTIONS":
+		util.EmptyOkHandler(w, r)
+	case "GET":
+		if h.ReadOnly {
+			readOnlyResponse(w, "/events")
+			return
+		}
+		h.listEventsHandler(w, r)
+	default:
+		util.NotFoundHandler(w, r)
+	}
+}
+
+// StreamHandler pushes events to clients as server-sent events as they
+// happen. Events can be filtered with comma-separated "type" & "dataset"
+// query params, eg: /events/stream?type=ds_created,ds_renamed&dataset=me/*
+func (h *EventHandlers) StreamHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "OPTIONS":
+		util.EmptyOkHandler(w, r)
+	case "GET":
+		if h.ReadOnly {
+			readOnlyResponse(w, "/events/stream")
+			return
+		}
+		h.streamEventsHandler(w, r)
+	default:
+		util.NotFoundHandler(w, r)
+	}
+}
+
+func (h *EventHandlers) listEventsHandler(w http.ResponseWriter, r *http.Request) {
+	p := lib.ListParamsFromRequest(r)
+	events, err := h.repo.Events(p.Limit, p.Offset)
+	if err != nil {
+		log.Infof("error listing events: %s", err.Error())
+		util.WriteErrResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	allowed := TokenDatasetsFromCtx(r.Context())
+	res := make([]eventResponse, 0, len(events))
+	for _, e := range events {
+		if matchesAny(allowed, e.Ref) {
+			res = append(res, newEventResponse(e))
+		}
+	}
+	if err := util.WritePageResponse(w, res, r, p.Page()); err != nil {
+		log.Infof("error list events response: %s", err.Error())
+	}
+}
+
+func (h *EventHandlers) streamEventsHandler(w http.ResponseWriter, r *http.Request) {
+	pub, ok := h.repo.(repo.EventPublisher)
+	if !ok {
+		util.WriteErrResponse(w, http.StatusInternalServerError, fmt.Errorf("this repo doesn't publish events"))
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		util.WriteErrResponse(w, http.StatusInternalServerError, fmt.Errorf("streaming isn't supported"))
+		return
+	}
+	filter, err := newEventFilter(h.repo, r)
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	events := make(chan *repo.Event, 64)
+	unsubscribe := pub.Subscribe(events)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	// clients reconnecting with the id of the last event they saw get any
+	// logged events they missed
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		if nsec, err := strconv.ParseInt(id, 10, 64); err == nil {
+			if missed, err := h.repo.EventsSince(time.Unix(0, nsec)); err == nil {
+				for _, e := range missed {
+					if filter.match(e) {
+						writeStreamEvent(w, e)
+					}
+				}
+			}
+		}
+	}
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(h.Heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		case e := <-events:
+			if !filter.match(e) {
+				continue
+			}
+			if err := writeStreamEvent(w, e); err != nil {
+				log.Infof("error writing event stream: %s", err.Error())
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// eventResponse is the encoding of an event in api responses & event streams
+type eventResponse struct {
+	Type   repo.EventType   `json:"type"`
+	Time   time.Time        `json:"time"`
+	Ref    *repo.DatasetRef `json:"ref,omitempty"`
+	PeerID string           `json:"peerID,omitempty"`
+	Params interface{}      `json:"params,omitempty"`
+}
+
+func newEventResponse(e *repo.Event) eventResponse {
+	res := eventResponse{
+		Type:   e.Type,
+		Time:   e.Time,
+		Params: e.Params,
+	}
+	if e.PeerID != "" {
+		res.PeerID = e.PeerID.Pretty()
+	}
+	if !e.Ref.IsEmpty() {
+		ref := e.Ref
+		res.Ref = &ref
+	}
+	return res
+}
+
+// writeStreamEvent writes a single server-sent event. Event ids are the
+// event timestamp in unix nanoseconds
+func writeStreamEvent(w http.ResponseWriter, e *repo.Event) error {
+	data, err := json.Marshal(newEventResponse(e))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.Time.UnixNano(), e.Type, data)
+	return err
+}
+
+// eventFilter selects events by type & dataset
+type eventFilter struct {
+	types    map[repo.EventType]bool
+	datasets []string
+	// allowed limits events to the datasets a request's api token is
+	// limited to
+	allowed []string
+}
+
+func newEventFilter(r repo.Repo, req *http.Request) (*eventFilter, error) {
+	f := &eventFilter{types: map[repo.EventType]bool{}, allowed: TokenDatasetsFromCtx(req.Context())}
+	for _, t := range queryList(req, "type") {
+		f.types[repo.EventType(t)] = true
+	}
+	for _, pattern := range queryList(req, "dataset") {
+		if strings.Count(pattern, "/") != 1 {
+			return nil, fmt.Errorf("invalid dataset filter '%s', must be in the form peername/dataset_name", pattern)
+		}
+		if strings.HasPrefix(pattern, "me/") {
+			pro, err := r.Profile()
+			if err != nil {
+				return nil, err
+			}
+			pattern = pro.Peername + strings.TrimPrefix(pattern, "me")
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid dataset filter '%s': %s", pattern, err.Error())
+		}
+		f.datasets = append(f.datasets, pattern)
+	}
+	return f, nil
+}
+
+func (f *eventFilter) match(e *repo.Event) bool {
+	if len(f.types) > 0 && !f.types[e.Type] {
+		return false
+	}
+	return matchesAny(f.datasets, e.Ref) && matchesAny(f.allowed, e.Ref)
+}
+
+// matchesAny checks a ref against a list of peername/dataset_name patterns.
+// an empty list matches everything
+func matchesAny(patterns []string, ref repo.DatasetRef) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	if ref.Peername == "" || ref.Name == "" {
+		return false
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, ref.Peername+"/"+ref.Name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// queryList reads a query or form param that can be repeated or
+// comma-separated
+func queryList(r *http.Request, key string) (list []string) {
+	r.ParseForm()
+	for _, v := range r.Form[key] {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				list = append(list, s)
+			}
+		}
+	}
+	return list
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qri-io/qri/repo"
+	"github.com/qri-io/qri/repo/test"
+)
+
+func TestEventsHandler(t *testing.T) {
+	r, err := test.NewTestRepo(nil)
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err.Error())
+	}
+	r.LogEvent(repo.ETDsCreated, repo.DatasetRef{Peername: "peer", Name: "movies"})
+	r.LogEvent(repo.ETDsRenamed, repo.DatasetRef{Peername: "peer", Name: "cities"})
+
+	h := NewEventHandlers(r, false)
+	w := httptest.NewRecorder()
+	h.EventsHandler(w, httptest.NewRequest("GET", "/events?page=1&pageSize=1", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status mismatch. expected: 200, got: %d. body: %s", w.Code, w.Body.String())
+	}
+	res := struct {
+		Data []eventResponse `json:"data"`
+	}{}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(res.Data) != 1 {
+		t.Fatalf("expected a page of 1 event, got: %d", len(res.Data))
+	}
+	if res.Data[0].Type != repo.ETDsRenamed || res.Data[0].Ref == nil || res.Data[0].Ref.AliasString() != "peer/cities" {
+		t.Errorf("expected most recent event first, got: %s", w.Body.String())
+	}
+
+	h.ReadOnly = true
+	w = httptest.NewRecorder()
+	h.EventsHandler(w, httptest.NewRequest("GET", "/events", nil))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected read-only events request to respond with 403, got: %d", w.Code)
+	}
+}
+
+func TestEventsStreamHandler(t *testing.T) {
+	r, err := test.NewTestRepo(nil)
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err.Error())
+	}
+
+	h := NewEventHandlers(r, false)
+	s := httptest.NewServer(http.HandlerFunc(h.StreamHandler))
+	defer s.Close()
+
+	res, err := http.Get(s.URL + "/events/stream?type=ds_created,ds_renamed&dataset=me/cities")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer res.Body.Close()
+	if ct := res.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("content type mismatch. expected: text/event-stream, got: %s", ct)
+	}
+
+	lines := make(chan string)
+	go func() {
+		sc := bufio.NewScanner(res.Body)
+		for sc.Scan() {
+			lines <- sc.Text()
+		}
+		close(lines)
+	}()
+	next := func(prefix string) string {
+		for {
+			select {
+			case line, ok := <-lines:
+				if !ok {
+					t.Fatalf("stream closed waiting for '%s'", prefix)
+				}
+				if strings.HasPrefix(line, prefix) {
+					return strings.TrimPrefix(line, prefix)
+				}
+			case <-time.After(time.Second * 2):
+				t.Fatalf("timed out waiting for '%s'", prefix)
+			}
+		}
+	}
+	next(": connected")
+
+	// events that don't match the filter are skipped
+	r.LogEvent(repo.ETDsCreated, repo.DatasetRef{Peername: "peer", Name: "movies"})
+	r.LogEvent(repo.ETDsPinned, repo.DatasetRef{Peername: "peer", Name: "cities"})
+	r.Publish(&repo.Event{Type: repo.ETPeerConnected, Time: time.Now()})
+	r.LogEvent(repo.ETDsRenamed, repo.DatasetRef{Peername: "peer", Name: "cities"})
+
+	if got := next("event: "); got != "ds_renamed" {
+		t.Errorf("event type mismatch. expected: ds_renamed, got: %s", got)
+	}
+	se := eventResponse{}
+	if err := json.Unmarshal([]byte(next("data: ")), &se); err != nil {
+		t.Fatal(err.Error())
+	}
+	if se.Ref == nil || se.Ref.AliasString() != "peer/cities" {
+		t.Errorf("expected event to reference peer/cities, got: %v", se.Ref)
+	}
+
+	res, err = http.Get(s.URL + "/events/stream?dataset=cities")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected invalid dataset filter to respond with 400, got: %d", res.StatusCode)
+	}
+}
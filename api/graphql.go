@@ -0,0 +1,806 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	util "github.com/datatogether/api/apiutil"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/p2p"
+	"github.com/qri-io/qri/repo"
+	"github.com/qri-io/qri/repo/profile"
+)
+
+const (
+	// defaultGraphQLLimit is the number of items list fields return when no
+	// limit is given
+	defaultGraphQLLimit = 25
+	// maxGraphQLLimit is the most items a list field returns, larger limits
+	// are clamped
+	maxGraphQLLimit = 100
+	// maxGraphQLDepth is how deeply a query's fields can nest
+	maxGraphQLDepth = 10
+	// maxGraphQLComplexity is the most fields a query can resolve. fields are
+	// counted once for each item of the lists they're nested in
+	maxGraphQLComplexity = 5000
+)
+
+// GraphQLHandlers serves a GraphQL endpoint for fetching datasets, their
+// components, history & body, profiles and peers in a single request
+type GraphQLHandlers struct {
+	ReadOnly bool
+
+	datasets *lib.DatasetRequests
+	history  *lib.HistoryRequests
+	profiles *lib.ProfileRequests
+	peers    *lib.PeerRequests
+	node     *p2p.QriNode
+	schema   graphql.Schema
+}
+
+// NewGraphQLHandlers allocates a GraphQLHandlers pointer
+func NewGraphQLHandlers(r repo.Repo, node *p2p.QriNode, readOnly bool) *GraphQLHandlers {
+	h := &GraphQLHandlers{
+		ReadOnly: readOnly,
+		datasets: lib.NewDatasetRequestsWithNode(r, nil, node),
+		history:  lib.NewHistoryRequests(r, nil),
+		profiles: lib.NewProfileRequests(r, nil),
+		peers:    lib.NewPeerRequests(node, nil),
+		node:     node,
+	}
+	h.history.Node = node
+
+	schema, err := h.newSchema()
+	if err != nil {
+		// the schema is static, an error here is a bug
+		panic(fmt.Errorf("error creating graphql schema: %s", err.Error()))
+	}
+	h.schema = schema
+	return h
+}
+
+// GraphQLHandler is the endpoint for graphql queries. Queries can be sent as
+// a "query" param, a JSON body with query, variables & operationName fields,
+// or an application/graphql body
+func (h *GraphQLHandlers) GraphQLHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "OPTIONS":
+		util.EmptyOkHandler(w, r)
+	case "GET", "POST":
+		h.graphQLHandler(w, r)
+	default:
+		util.NotFoundHandler(w, r)
+	}
+}
+
+// graphQLParams is the encoding of a graphql request
+type graphQLParams struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+func (h *GraphQLHandlers) graphQLHandler(w http.ResponseWriter, r *http.Request) {
+	p := graphQLParams{}
+	switch {
+	case r.Method == "GET":
+		p.Query = r.FormValue("query")
+		p.OperationName = r.FormValue("operationName")
+		if v := r.FormValue("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &p.Variables); err != nil {
+				util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("error decoding variables: %s", err.Error()))
+				return
+			}
+		}
+	case strings.HasPrefix(r.Header.Get("Content-Type"), "application/graphql"):
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("error reading request body: %s", err.Error()))
+			return
+		}
+		p.Query = string(data)
+	default:
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("error decoding request body: %s", err.Error()))
+			return
+		}
+	}
+
+	if p.Query == "" {
+		util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("query is required"))
+		return
+	}
+	// queries are checked before they're run, a deep or wide query can fan
+	// out to a huge number of dataset loads
+	if err := h.checkQueryCost(p); err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	res := graphql.Do(graphql.Params{
+		Schema:         h.schema,
+		RequestString:  p.Query,
+		VariableValues: p.Variables,
+		OperationName:  p.OperationName,
+		Context:        r.Context(),
+	})
+
+	// graphql responses aren't wrapped in a meta envelope, errors are
+	// reported in the response "errors" field
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		log.Infof("error writing graphql response: %s", err.Error())
+	}
+}
+
+// checkQueryCost errors for queries that nest deeper than maxGraphQLDepth or
+// resolve more than maxGraphQLComplexity fields. Queries that don't parse are
+// left for graphql.Do to report
+func (h *GraphQLHandlers) checkQueryCost(p graphQLParams) error {
+	doc, err := parser.Parse(parser.ParseParams{Source: p.Query})
+	if err != nil {
+		return nil
+	}
+
+	c := &queryCost{
+		fragments: map[string]*ast.FragmentDefinition{},
+		spreading: map[string]bool{},
+		variables: p.Variables,
+	}
+	for _, def := range doc.Definitions {
+		if frag, ok := def.(*ast.FragmentDefinition); ok && frag.Name != nil {
+			c.fragments[frag.Name.Value] = frag
+		}
+	}
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if p.OperationName != "" && (op.Name == nil || op.Name.Value != p.OperationName) {
+			continue
+		}
+		if err := c.selections(h.schema.QueryType(), op.SelectionSet, 1, 1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// queryCost measures the depth & complexity of a query
+type queryCost struct {
+	fragments map[string]*ast.FragmentDefinition
+	// spreading holds the fragments being walked, to skip cyclic spreads.
+	// cycles fail query validation
+	spreading  map[string]bool
+	variables  map[string]interface{}
+	complexity int
+}
+
+// selections adds the cost of a selection set on typ, where each field is
+// resolved count times
+func (c *queryCost) selections(typ *graphql.Object, set *ast.SelectionSet, depth, count int) error {
+	if set == nil {
+		return nil
+	}
+	if depth > maxGraphQLDepth {
+		return fmt.Errorf("query is too deep, fields can be nested at most %d levels", maxGraphQLDepth)
+	}
+
+	for _, sel := range set.Selections {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			c.complexity += count
+			if c.complexity > maxGraphQLComplexity {
+				return fmt.Errorf("query is too complex, queries can resolve at most %d fields", maxGraphQLComplexity)
+			}
+			if typ == nil || sel.Name == nil || sel.SelectionSet == nil {
+				continue
+			}
+			// unknown fields fail query validation
+			def, ok := typ.Fields()[sel.Name.Value]
+			if !ok {
+				continue
+			}
+			fieldCount := count
+			if _, ok := def.Type.(*graphql.List); ok {
+				fieldCount *= c.limit(def, sel)
+			}
+			if err := c.selections(objectType(def.Type), sel.SelectionSet, depth+1, fieldCount); err != nil {
+				return err
+			}
+		case *ast.InlineFragment:
+			if err := c.selections(typ, sel.SelectionSet, depth, count); err != nil {
+				return err
+			}
+		case *ast.FragmentSpread:
+			if sel.Name == nil {
+				continue
+			}
+			name := sel.Name.Value
+			frag, ok := c.fragments[name]
+			if !ok || c.spreading[name] {
+				continue
+			}
+			c.spreading[name] = true
+			err := c.selections(typ, frag.SelectionSet, depth, count)
+			delete(c.spreading, name)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// limit gives the number of items a list field can return, reading the
+// field's limit argument the way resolvers do
+func (c *queryCost) limit(def *graphql.FieldDefinition, field *ast.Field) int {
+	limit := maxGraphQLLimit
+	for _, arg := range def.Args {
+		if arg.Name() == "limit" {
+			if v, ok := arg.DefaultValue.(int); ok {
+				limit = v
+			}
+		}
+	}
+	for _, arg := range field.Arguments {
+		if arg.Name == nil || arg.Name.Value != "limit" {
+			continue
+		}
+		switch v := arg.Value.(type) {
+		case *ast.IntValue:
+			if i, err := strconv.Atoi(v.Value); err == nil {
+				limit = i
+			}
+		case *ast.Variable:
+			if v.Name == nil {
+				continue
+			}
+			// variables decoded from JSON are float64s
+			switch n := c.variables[v.Name.Value].(type) {
+			case float64:
+				limit = int(n)
+			case int:
+				limit = n
+			}
+		}
+	}
+	return clampLimit(limit)
+}
+
+// objectType gives the object type of a field, unwrapping lists & non-null
+// types. fields of scalar types give nil
+func objectType(typ graphql.Output) *graphql.Object {
+	for {
+		switch t := typ.(type) {
+		case *graphql.Object:
+			return t
+		case *graphql.List:
+			typ = t.OfType
+		case *graphql.NonNull:
+			typ = t.OfType
+		default:
+			return nil
+		}
+	}
+}
+
+// readOnlyField errors for fields that expose data read-only servers don't
+// serve over other endpoints
+func (h *GraphQLHandlers) readOnlyField(field string) error {
+	if h.ReadOnly {
+		return fmt.Errorf("qri server is in read-only mode, access to '%s' is forbidden", field)
+	}
+	return nil
+}
+
+// jsonScalar passes arbitrary JSON values through as-is, used for values
+// like schemas & bodies that have no fixed shape
+var jsonScalar = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "JSON",
+	Description: "Any JSON value",
+	Serialize:   func(v interface{}) interface{} { return v },
+	ParseValue:  func(v interface{}) interface{} { return v },
+	ParseLiteral: func(v ast.Value) interface{} {
+		return parseJSONLiteral(v)
+	},
+})
+
+func parseJSONLiteral(v ast.Value) interface{} {
+	switch v := v.(type) {
+	case *ast.ObjectValue:
+		obj := map[string]interface{}{}
+		for _, f := range v.Fields {
+			obj[f.Name.Value] = parseJSONLiteral(f.Value)
+		}
+		return obj
+	case *ast.ListValue:
+		list := make([]interface{}, len(v.Values))
+		for i, item := range v.Values {
+			list[i] = parseJSONLiteral(item)
+		}
+		return list
+	case *ast.IntValue:
+		return graphql.Int.ParseLiteral(v)
+	case *ast.FloatValue:
+		return graphql.Float.ParseLiteral(v)
+	case *ast.BooleanValue:
+		return v.Value
+	case *ast.StringValue:
+		return v.Value
+	case *ast.EnumValue:
+		return v.Value
+	}
+	return nil
+}
+
+// componentMap converts a dataset component to a map of its JSON encoding,
+// so component fields resolve by their JSON names
+func componentMap(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, nil
+	}
+	return m, nil
+}
+
+// componentField resolves a dataset component from a repo.DatasetRef source
+func componentField(typ graphql.Output, component func(ds *dataset.DatasetPod) interface{}) *graphql.Field {
+	return &graphql.Field{
+		Type: typ,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			ref, ok := p.Source.(repo.DatasetRef)
+			if !ok || ref.Dataset == nil {
+				return nil, nil
+			}
+			return componentMap(component(ref.Dataset))
+		},
+	}
+}
+
+// refField resolves a string from a repo.DatasetRef source
+func refField(value func(ref repo.DatasetRef) string) *graphql.Field {
+	return &graphql.Field{
+		Type: graphql.String,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			ref, ok := p.Source.(repo.DatasetRef)
+			if !ok {
+				return nil, nil
+			}
+			return value(ref), nil
+		},
+	}
+}
+
+// stringFields creates a fields map of string fields
+func stringFields(names ...string) graphql.Fields {
+	fields := graphql.Fields{}
+	for _, name := range names {
+		fields[name] = &graphql.Field{Type: graphql.String}
+	}
+	return fields
+}
+
+// intArg reads an integer argument, falling back to a default
+func intArg(p graphql.ResolveParams, name string, def int) int {
+	if v, ok := p.Args[name].(int); ok {
+		return v
+	}
+	return def
+}
+
+// limitArg reads the limit argument of a list field, clamped to
+// maxGraphQLLimit
+func limitArg(p graphql.ResolveParams) int {
+	return clampLimit(intArg(p, "limit", defaultGraphQLLimit))
+}
+
+// clampLimit keeps a list limit between 1 & maxGraphQLLimit. limits below 1
+// fall back to defaultGraphQLLimit
+func clampLimit(limit int) int {
+	if limit < 1 {
+		return defaultGraphQLLimit
+	}
+	if limit > maxGraphQLLimit {
+		return maxGraphQLLimit
+	}
+	return limit
+}
+
+// parseRefArg parses a dataset reference argument
+func parseRefArg(p graphql.ResolveParams, name string) (repo.DatasetRef, error) {
+	refstr, _ := p.Args[name].(string)
+	ref, err := repo.ParseDatasetRef(refstr)
+	if err != nil {
+		return ref, fmt.Errorf("error parsing %s: %s", name, err.Error())
+	}
+	return ref, nil
+}
+
+func (h *GraphQLHandlers) newSchema() (graphql.Schema, error) {
+	commitType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Commit",
+		Description: "Commit encapsulates information about changes to a dataset",
+		Fields: func() graphql.Fields {
+			fields := stringFields("path", "title", "message", "signature", "timestamp", "qri")
+			fields["author"] = &graphql.Field{Type: jsonScalar}
+			return fields
+		}(),
+	})
+
+	metaType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Meta",
+		Description: "Meta contains human-readable descriptive metadata",
+		Fields: func() graphql.Fields {
+			fields := stringFields("path", "title", "description", "accessPath", "downloadPath", "homePath", "readmePath", "accrualPeriodicity", "identifier", "version", "qri")
+			fields["keywords"] = &graphql.Field{Type: graphql.NewList(graphql.String)}
+			fields["language"] = &graphql.Field{Type: graphql.NewList(graphql.String)}
+			fields["theme"] = &graphql.Field{Type: graphql.NewList(graphql.String)}
+			fields["license"] = &graphql.Field{Type: jsonScalar}
+			fields["citations"] = &graphql.Field{Type: jsonScalar}
+			fields["contributors"] = &graphql.Field{Type: jsonScalar}
+			return fields
+		}(),
+	})
+
+	structureType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Structure",
+		Description: "Structure defines the characteristics of a dataset body",
+		Fields: func() graphql.Fields {
+			fields := stringFields("path", "checksum", "compression", "encoding", "format", "qri")
+			fields["entries"] = &graphql.Field{Type: graphql.Int}
+			fields["errCount"] = &graphql.Field{Type: graphql.Int}
+			fields["length"] = &graphql.Field{Type: graphql.Int}
+			fields["formatConfig"] = &graphql.Field{Type: jsonScalar}
+			fields["schema"] = &graphql.Field{Type: jsonScalar}
+			return fields
+		}(),
+	})
+
+	// transform secrets are deliberately left out of the schema
+	transformType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Transform",
+		Description: "Transform is a record of executing a transformation on data",
+		Fields: func() graphql.Fields {
+			fields := stringFields("path", "scriptPath", "syntax", "syntaxVersion", "qri")
+			fields["config"] = &graphql.Field{Type: jsonScalar}
+			fields["resources"] = &graphql.Field{Type: jsonScalar}
+			return fields
+		}(),
+	})
+
+	vizType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Viz",
+		Description: "Viz stores configuration data related to representing a dataset as a visualization",
+		Fields:      stringFields("path", "format", "scriptPath", "qri"),
+	})
+
+	var datasetType *graphql.Object
+	datasetType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Dataset",
+		Description: "A version of a dataset",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"peername":     refField(func(ref repo.DatasetRef) string { return ref.Peername }),
+				"name":         refField(func(ref repo.DatasetRef) string { return ref.Name }),
+				"path":         refField(func(ref repo.DatasetRef) string { return ref.Path }),
+				"profileID":    refField(func(ref repo.DatasetRef) string { return ref.ProfileID.String() }),
+				"alias":        refField(func(ref repo.DatasetRef) string { return ref.AliasString() }),
+				"bodyPath":     refField(datasetString(func(ds *dataset.DatasetPod) string { return ds.BodyPath })),
+				"previousPath": refField(datasetString(func(ds *dataset.DatasetPod) string { return ds.PreviousPath })),
+				"commit":       componentField(commitType, func(ds *dataset.DatasetPod) interface{} { return ds.Commit }),
+				"meta":         componentField(metaType, func(ds *dataset.DatasetPod) interface{} { return ds.Meta }),
+				"structure":    componentField(structureType, func(ds *dataset.DatasetPod) interface{} { return ds.Structure }),
+				"transform":    componentField(transformType, func(ds *dataset.DatasetPod) interface{} { return ds.Transform }),
+				"viz":          componentField(vizType, func(ds *dataset.DatasetPod) interface{} { return ds.Viz }),
+				"body": &graphql.Field{
+					Type:        jsonScalar,
+					Description: "A page of the dataset body. limits are clamped like list field limits",
+					Args: graphql.FieldConfigArgument{
+						"limit":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultDataLimit},
+						"offset": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0},
+					},
+					Resolve: h.resolveBody,
+				},
+				"previous": &graphql.Field{
+					Type:        datasetType,
+					Description: "The version of the dataset this version was created from",
+					Resolve:     h.resolvePrevious,
+				},
+				"history": &graphql.Field{
+					Type:        graphql.NewList(datasetType),
+					Description: "Versions of the dataset, starting with this version & following previous paths",
+					Args: graphql.FieldConfigArgument{
+						"limit": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultGraphQLLimit},
+					},
+					Resolve: h.resolveHistory,
+				},
+			}
+		}),
+	})
+
+	profileType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Profile",
+		Description: "A peer or organization on the qri network",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			fields := stringFields("id", "peername", "type", "email", "name", "description", "homeurl", "color", "thumb", "photo", "poster", "twitter")
+			fields["created"] = &graphql.Field{Type: graphql.DateTime}
+			fields["updated"] = &graphql.Field{Type: graphql.DateTime}
+			fields["online"] = &graphql.Field{Type: graphql.Boolean}
+			fields["peerIDs"] = &graphql.Field{Type: graphql.NewList(graphql.String)}
+			fields["networkAddrs"] = &graphql.Field{Type: graphql.NewList(graphql.String)}
+			fields["datasets"] = &graphql.Field{
+				Type:        graphql.NewList(datasetType),
+				Description: "Datasets this profile has published",
+				Args:        listArgs(),
+				Resolve:     h.resolveProfileDatasets,
+			}
+			return fields
+		}),
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"dataset": &graphql.Field{
+				Type:        datasetType,
+				Description: "Get a dataset by reference, eg: me/cities or peer/cities@/ipfs/Qm...",
+				Args: graphql.FieldConfigArgument{
+					"ref": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: h.resolveDataset,
+			},
+			"datasets": &graphql.Field{
+				Type:        graphql.NewList(datasetType),
+				Description: "List datasets in this repo",
+				Args:        listArgs(),
+				Resolve:     h.resolveDatasets,
+			},
+			"me": &graphql.Field{
+				Type:        profileType,
+				Description: "This node's profile",
+				Resolve:     h.resolveMe,
+			},
+			"peer": &graphql.Field{
+				Type:        profileType,
+				Description: "Get a peer by peername or profile ID",
+				Args: graphql.FieldConfigArgument{
+					"peername": &graphql.ArgumentConfig{Type: graphql.String},
+					"id":       &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: h.resolvePeer,
+			},
+			"peers": &graphql.Field{
+				Type:        graphql.NewList(profileType),
+				Description: "List peers, connected peers only unless cached is true",
+				Args: graphql.FieldConfigArgument{
+					"limit":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultGraphQLLimit},
+					"offset": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0},
+					"cached": &graphql.ArgumentConfig{Type: graphql.Boolean, DefaultValue: false},
+				},
+				Resolve: h.resolvePeers,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query})
+}
+
+// listArgs are the arguments for fields that list datasets
+func listArgs() graphql.FieldConfigArgument {
+	return graphql.FieldConfigArgument{
+		"limit":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultGraphQLLimit},
+		"offset": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0},
+	}
+}
+
+// datasetString reads a string from a reference's dataset, if it has one
+func datasetString(value func(ds *dataset.DatasetPod) string) func(ref repo.DatasetRef) string {
+	return func(ref repo.DatasetRef) string {
+		if ref.Dataset == nil {
+			return ""
+		}
+		return value(ref.Dataset)
+	}
+}
+
+func (h *GraphQLHandlers) resolveDataset(p graphql.ResolveParams) (interface{}, error) {
+	ref, err := parseRefArg(p, "ref")
+	if err != nil {
+		return nil, err
+	}
+	res := repo.DatasetRef{}
+	if err := h.datasets.Get(&ref, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (h *GraphQLHandlers) resolveDatasets(p graphql.ResolveParams) (interface{}, error) {
+	if err := h.readOnlyField("datasets"); err != nil {
+		return nil, err
+	}
+	res := []repo.DatasetRef{}
+	lp := &lib.ListParams{
+		Limit:  limitArg(p),
+		Offset: intArg(p, "offset", 0),
+	}
+	if err := h.datasets.List(lp, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (h *GraphQLHandlers) resolveBody(p graphql.ResolveParams) (interface{}, error) {
+	if err := h.readOnlyField("body"); err != nil {
+		return nil, err
+	}
+	ref, ok := p.Source.(repo.DatasetRef)
+	if !ok || ref.Path == "" {
+		return nil, nil
+	}
+
+	// bodies are only read a page at a time, clamped like any other list
+	lp := &lib.LookupParams{
+		Path:   ref.Path,
+		Format: dataset.JSONDataFormat,
+		Limit:  clampLimit(intArg(p, "limit", defaultDataLimit)),
+		Offset: intArg(p, "offset", 0),
+	}
+	res := &lib.LookupResult{}
+	if err := h.datasets.LookupBody(lp, res); err != nil {
+		return nil, err
+	}
+
+	var body interface{}
+	if err := json.Unmarshal(res.Data, &body); err != nil {
+		return nil, fmt.Errorf("error decoding body: %s", err.Error())
+	}
+	return body, nil
+}
+
+func (h *GraphQLHandlers) resolvePrevious(p graphql.ResolveParams) (interface{}, error) {
+	ref, ok := p.Source.(repo.DatasetRef)
+	if !ok || ref.Dataset == nil || ref.Dataset.PreviousPath == "" || ref.Dataset.PreviousPath == "/" {
+		return nil, nil
+	}
+
+	prev := repo.DatasetRef{
+		Peername:  ref.Peername,
+		ProfileID: ref.ProfileID,
+		Name:      ref.Name,
+		Path:      ref.Dataset.PreviousPath,
+	}
+	res := repo.DatasetRef{}
+	if err := h.datasets.Get(&prev, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (h *GraphQLHandlers) resolveHistory(p graphql.ResolveParams) (interface{}, error) {
+	ref, ok := p.Source.(repo.DatasetRef)
+	if !ok {
+		return nil, nil
+	}
+
+	params := &lib.LogParams{
+		ListParams: lib.ListParams{Limit: limitArg(p)},
+		Ref: repo.DatasetRef{
+			Peername:  ref.Peername,
+			ProfileID: ref.ProfileID,
+			Name:      ref.Name,
+			Path:      ref.Path,
+		},
+	}
+	res := []repo.DatasetRef{}
+	if err := h.history.Log(params, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (h *GraphQLHandlers) resolveMe(p graphql.ResolveParams) (interface{}, error) {
+	if err := h.readOnlyField("me"); err != nil {
+		return nil, err
+	}
+	in := true
+	res := &config.ProfilePod{}
+	if err := h.profiles.GetProfile(&in, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (h *GraphQLHandlers) resolvePeer(p graphql.ResolveParams) (interface{}, error) {
+	if err := h.readOnlyField("peer"); err != nil {
+		return nil, err
+	}
+	if h.node == nil {
+		return nil, fmt.Errorf("no p2p connection")
+	}
+
+	params := &lib.PeerInfoParams{}
+	params.Peername, _ = p.Args["peername"].(string)
+	if id, ok := p.Args["id"].(string); ok && id != "" {
+		pid, err := profile.IDB58Decode(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid profile id: %s", id)
+		}
+		params.ProfileID = pid
+	}
+	if params.Peername == "" && params.ProfileID == "" {
+		return nil, fmt.Errorf("peername or id is required")
+	}
+
+	res := &config.ProfilePod{}
+	if err := h.peers.Info(params, res); err != nil {
+		if err == repo.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return res, nil
+}
+
+func (h *GraphQLHandlers) resolvePeers(p graphql.ResolveParams) (interface{}, error) {
+	if err := h.readOnlyField("peers"); err != nil {
+		return nil, err
+	}
+	cached, _ := p.Args["cached"].(bool)
+	params := &lib.PeerListParams{
+		Limit:  limitArg(p),
+		Offset: intArg(p, "offset", 0),
+		Cached: cached,
+	}
+	res := []*config.ProfilePod{}
+	if err := h.peers.List(params, &res); err != nil {
+		return nil, err
+	}
+
+	// cached lists are padded to the limit with nil profiles
+	peers := make([]*config.ProfilePod, 0, len(res))
+	for _, pro := range res {
+		if pro != nil {
+			peers = append(peers, pro)
+		}
+	}
+	return peers, nil
+}
+
+func (h *GraphQLHandlers) resolveProfileDatasets(p graphql.ResolveParams) (interface{}, error) {
+	if err := h.readOnlyField("datasets"); err != nil {
+		return nil, err
+	}
+	pro, ok := p.Source.(*config.ProfilePod)
+	if !ok {
+		return nil, nil
+	}
+	res := []repo.DatasetRef{}
+	lp := &lib.ListParams{
+		Peername: pro.Peername,
+		Limit:    limitArg(p),
+		Offset:   intArg(p, "offset", 0),
+	}
+	if err := h.datasets.List(lp, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
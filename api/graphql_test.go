@@ -0,0 +1,163 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	golog "github.com/ipfs/go-log"
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/p2p"
+	"github.com/qri-io/qri/repo/test"
+)
+
+func TestGraphQLHandler(t *testing.T) {
+	// bump up log level to keep test output clean
+	golog.SetLogLevel("qriapi", "error")
+	defer golog.SetLogLevel("qriapi", "info")
+
+	r, err := test.NewTestRepo(nil)
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err.Error())
+	}
+	node, err := p2p.NewQriNode(r, func(c *config.P2P) { c.Enabled = false })
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	lib.Config = config.DefaultConfig()
+	lib.Config.Profile = test.ProfileConfig()
+
+	h := NewGraphQLHandlers(r, node, false)
+
+	type response struct {
+		Data   map[string]interface{} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	do := func(req *http.Request) response {
+		w := httptest.NewRecorder()
+		h.GraphQLHandler(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status mismatch. expected: 200, got: %d. body: %s", w.Code, w.Body.String())
+		}
+		res := response{}
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatal(err.Error())
+		}
+		return res
+	}
+
+	query := `{"query": "query Movies($ref: String!) { dataset(ref: $ref) { name peername structure { format entries } body(limit: 2) history { path } } me { peername datasets(limit: 10) { name } } }", "variables": {"ref": "me/movies"}}`
+	res := do(httptest.NewRequest("POST", "/graphql", strings.NewReader(query)))
+	if len(res.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", res.Errors)
+	}
+
+	ds, ok := res.Data["dataset"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected a dataset, got: %v", res.Data["dataset"])
+	}
+	if ds["name"] != "movies" || ds["peername"] != "peer" {
+		t.Errorf("expected dataset peer/movies, got: %s/%s", ds["peername"], ds["name"])
+	}
+	if st, ok := ds["structure"].(map[string]interface{}); !ok || st["format"] != "csv" {
+		t.Errorf("expected csv structure, got: %v", ds["structure"])
+	}
+	if body, ok := ds["body"].([]interface{}); !ok || len(body) != 2 {
+		t.Errorf("expected a body page of 2 entries, got: %v", ds["body"])
+	}
+	if history, ok := ds["history"].([]interface{}); !ok || len(history) == 0 {
+		t.Errorf("expected dataset history, got: %v", ds["history"])
+	}
+
+	me, ok := res.Data["me"].(map[string]interface{})
+	if !ok || me["peername"] != "peer" {
+		t.Fatalf("expected profile for peer, got: %v", res.Data["me"])
+	}
+	if datasets, ok := me["datasets"].([]interface{}); !ok || len(datasets) != 5 {
+		t.Errorf("expected 5 profile datasets, got: %v", me["datasets"])
+	}
+
+	// queries can be sent as query params
+	res = do(httptest.NewRequest("GET", "/graphql?query="+url.QueryEscape(`{ dataset(ref: "me/cities") { name } }`), nil))
+	if ds, ok := res.Data["dataset"].(map[string]interface{}); !ok || ds["name"] != "cities" {
+		t.Errorf("expected dataset cities, got: %v", res.Data["dataset"])
+	}
+
+	// read-only servers only serve dataset & history fields
+	h.ReadOnly = true
+	res = do(httptest.NewRequest("GET", "/graphql?query="+url.QueryEscape(`{ dataset(ref: "me/cities") { name } me { peername } }`), nil))
+	if ds, ok := res.Data["dataset"].(map[string]interface{}); !ok || ds["name"] != "cities" {
+		t.Errorf("expected read-only dataset query to succeed, got: %v", res.Data["dataset"])
+	}
+	if len(res.Errors) != 1 || !strings.Contains(res.Errors[0].Message, "read-only") {
+		t.Errorf("expected read-only error for profile, got: %v", res.Errors)
+	}
+
+	w := httptest.NewRecorder()
+	h.GraphQLHandler(w, httptest.NewRequest("POST", "/graphql", strings.NewReader(`{}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected missing query to respond with 400, got: %d", w.Code)
+	}
+}
+
+func TestGraphQLQueryCost(t *testing.T) {
+	golog.SetLogLevel("qriapi", "error")
+	defer golog.SetLogLevel("qriapi", "info")
+
+	r, err := test.NewTestRepo(nil)
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err.Error())
+	}
+	node, err := p2p.NewQriNode(r, func(c *config.P2P) { c.Enabled = false })
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	h := NewGraphQLHandlers(r, node, false)
+
+	deep := `{ dataset(ref: "me/movies") { ` + strings.Repeat("previous { ", maxGraphQLDepth) + "path" + strings.Repeat(" }", maxGraphQLDepth) + " } }"
+	cases := []struct {
+		query  string
+		status int
+	}{
+		{`{ dataset(ref: "me/movies") { previous { previous { path } } } }`, http.StatusOK},
+		{deep, http.StatusBadRequest},
+		{`{ datasets(limit: 100) { history(limit: 100) { path } } }`, http.StatusBadRequest},
+		// limits above the max are clamped when measuring queries
+		{`{ datasets(limit: 100000) { history(limit: 1) { path } } }`, http.StatusOK},
+		{`{ dataset(ref: "me/movies") { ...F } } fragment F on Dataset { name history(limit: 100) { history(limit: 100) { path } } }`, http.StatusBadRequest},
+	}
+	for i, c := range cases {
+		w := httptest.NewRecorder()
+		h.GraphQLHandler(w, httptest.NewRequest("GET", "/graphql?query="+url.QueryEscape(c.query), nil))
+		if w.Code != c.status {
+			t.Errorf("case %d status mismatch. expected: %d, got: %d. body: %s", i, c.status, w.Code, w.Body.String())
+		}
+	}
+
+	// body pages are clamped, & can't be skipped by reading the whole body
+	bodyQuery := func(args string) map[string]interface{} {
+		w := httptest.NewRecorder()
+		h.GraphQLHandler(w, httptest.NewRequest("GET", "/graphql?query="+url.QueryEscape(`{ dataset(ref: "me/movies") { body(`+args+`) } }`), nil))
+		res := map[string]interface{}{}
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatal(err.Error())
+		}
+		return res
+	}
+	res := bodyQuery("limit: 100000")
+	data, _ := res["data"].(map[string]interface{})
+	ds, _ := data["dataset"].(map[string]interface{})
+	if body, ok := ds["body"].([]interface{}); !ok || len(body) != maxGraphQLLimit {
+		t.Errorf("expected body limit to be clamped to %d entries, got: %v", maxGraphQLLimit, res)
+	}
+	if res = bodyQuery("all: true"); res["errors"] == nil {
+		t.Errorf("expected the all argument to be refused")
+	}
+}
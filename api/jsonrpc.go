@@ -0,0 +1,356 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/rpc"
+	"strings"
+	"sync"
+
+	util "github.com/datatogether/api/apiutil"
+)
+
+// JSON-RPC 2.0 error codes, see https://www.jsonrpc.org/specification#error_object
+const (
+	jsonrpcParseError     = -32700
+	jsonrpcInvalidRequest = -32600
+	jsonrpcMethodNotFound = -32601
+	jsonrpcInvalidParams  = -32602
+	jsonrpcServerError    = -32000
+)
+
+// maxJSONRPCRequestSize caps the size of JSON-RPC http request bodies
+const maxJSONRPCRequestSize = 10 << 20
+
+// jsonrpcRequest is the encoding of a JSON-RPC 2.0 request
+type jsonrpcRequest struct {
+	Version string           `json:"jsonrpc"`
+	Method  string           `json:"method"`
+	Params  *json.RawMessage `json:"params"`
+	ID      *json.RawMessage `json:"id"`
+}
+
+// jsonrpcResponse is the encoding of a JSON-RPC 2.0 response
+type jsonrpcResponse struct {
+	Version string           `json:"jsonrpc"`
+	Result  interface{}      `json:"result,omitempty"`
+	Error   *jsonrpcError    `json:"error,omitempty"`
+	ID      *json.RawMessage `json:"id"`
+}
+
+// jsonrpcError is the encoding of a JSON-RPC 2.0 error
+type jsonrpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// jsonrpcCall tracks a request between reading it & writing its response
+type jsonrpcCall struct {
+	// id is nil for notifications, which get no response
+	id *json.RawMessage
+	// code overrides the error code of the response, for requests that
+	// failed before reaching a receiver
+	code  int
+	batch *jsonrpcBatch
+}
+
+// jsonrpcBatch collects responses to a batch of requests, which are written
+// together once every request in the batch is served
+type jsonrpcBatch struct {
+	remaining int
+	responses []*jsonrpcResponse
+}
+
+// nullID is the id of responses to requests that didn't have a readable id
+var nullID = json.RawMessage("null")
+
+// jsonrpcCodec implements rpc.ServerCodec for JSON-RPC 2.0, so lib
+// receivers can be served to clients that don't speak gob. Requests are read
+// as a stream of JSON values, which can be single requests or batches.
+// net/rpc methods take a single argument, which can be given as the params
+// object or a one-element params array
+type jsonrpcCodec struct {
+	dec *json.Decoder
+	w   io.Writer
+	c   io.Closer
+
+	// queue holds requests of a batch that haven't been read yet
+	queue   []json.RawMessage
+	batch   *jsonrpcBatch
+	params  *json.RawMessage
+	current uint64
+
+	mu      sync.Mutex
+	seq     uint64
+	pending map[uint64]*jsonrpcCall
+	wrote   bool
+}
+
+// NewJSONRPCCodec creates a JSON-RPC 2.0 rpc.ServerCodec that reads requests
+// from & writes responses to conn
+func NewJSONRPCCodec(conn io.ReadWriteCloser) rpc.ServerCodec {
+	return newJSONRPCCodec(conn, conn, conn)
+}
+
+func newJSONRPCCodec(r io.Reader, w io.Writer, c io.Closer) *jsonrpcCodec {
+	return &jsonrpcCodec{
+		dec:     json.NewDecoder(r),
+		w:       w,
+		c:       c,
+		pending: map[uint64]*jsonrpcCall{},
+	}
+}
+
+// ReadRequestHeader implements the rpc.ServerCodec interface
+func (c *jsonrpcCodec) ReadRequestHeader(r *rpc.Request) error {
+	for len(c.queue) == 0 {
+		var raw json.RawMessage
+		if err := c.dec.Decode(&raw); err != nil {
+			if err != io.EOF {
+				c.writeError(jsonrpcParseError, fmt.Sprintf("parse error: %s", err.Error()))
+			}
+			return err
+		}
+
+		raw = bytes.TrimSpace(raw)
+		if len(raw) > 0 && raw[0] == '[' {
+			reqs := []json.RawMessage{}
+			if err := json.Unmarshal(raw, &reqs); err != nil {
+				c.writeError(jsonrpcParseError, fmt.Sprintf("parse error: %s", err.Error()))
+				return err
+			}
+			if len(reqs) == 0 {
+				c.writeError(jsonrpcInvalidRequest, "invalid request: empty batch")
+				continue
+			}
+			c.queue = reqs
+			c.batch = &jsonrpcBatch{remaining: len(reqs)}
+		} else {
+			c.queue = []json.RawMessage{raw}
+			c.batch = nil
+		}
+	}
+
+	raw := c.queue[0]
+	c.queue = c.queue[1:]
+
+	req := jsonrpcRequest{}
+	call := &jsonrpcCall{batch: c.batch}
+	if err := json.Unmarshal(raw, &req); err != nil || req.Version != "2.0" || req.Method == "" {
+		// net/rpc responds to an empty method with an error, which is
+		// written with the invalid request code
+		req.Method = ""
+		call.code = jsonrpcInvalidRequest
+		if req.ID == nil {
+			req.ID = &nullID
+		}
+	}
+	call.id = req.ID
+
+	c.mu.Lock()
+	c.seq++
+	c.pending[c.seq] = call
+	c.current = c.seq
+	c.mu.Unlock()
+
+	r.ServiceMethod = req.Method
+	r.Seq = c.current
+	c.params = req.Params
+	return nil
+}
+
+// ReadRequestBody implements the rpc.ServerCodec interface
+func (c *jsonrpcCodec) ReadRequestBody(x interface{}) error {
+	if x == nil || c.params == nil {
+		return nil
+	}
+
+	params := bytes.TrimSpace(*c.params)
+	if len(params) > 0 && params[0] == '[' {
+		list := []json.RawMessage{}
+		if err := json.Unmarshal(params, &list); err != nil {
+			return c.invalidParams(err.Error())
+		}
+		if len(list) != 1 {
+			return c.invalidParams(fmt.Sprintf("expected 1 param, got %d", len(list)))
+		}
+		params = list[0]
+	}
+
+	if err := json.Unmarshal(params, x); err != nil {
+		return c.invalidParams(err.Error())
+	}
+	return nil
+}
+
+// invalidParams marks the current request as having invalid params
+func (c *jsonrpcCodec) invalidParams(msg string) error {
+	c.mu.Lock()
+	if call, ok := c.pending[c.current]; ok {
+		call.code = jsonrpcInvalidParams
+	}
+	c.mu.Unlock()
+	return fmt.Errorf("invalid params: %s", msg)
+}
+
+// WriteResponse implements the rpc.ServerCodec interface
+func (c *jsonrpcCodec) WriteResponse(r *rpc.Response, x interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	call, ok := c.pending[r.Seq]
+	if !ok {
+		return fmt.Errorf("invalid sequence number in response")
+	}
+	delete(c.pending, r.Seq)
+
+	res := &jsonrpcResponse{Version: "2.0", ID: call.id}
+	if r.Error == "" {
+		res.Result = x
+	} else {
+		res.Error = &jsonrpcError{Code: jsonrpcErrorCode(call.code, r.Error), Message: r.Error}
+	}
+
+	if call.batch != nil {
+		call.batch.remaining--
+		if call.id != nil {
+			call.batch.responses = append(call.batch.responses, res)
+		}
+		if call.batch.remaining > 0 || len(call.batch.responses) == 0 {
+			return nil
+		}
+		return c.write(call.batch.responses)
+	}
+
+	if call.id == nil {
+		// notifications don't get a response
+		return nil
+	}
+	return c.write(res)
+}
+
+// Close implements the rpc.ServerCodec interface
+func (c *jsonrpcCodec) Close() error {
+	if c.c == nil {
+		return nil
+	}
+	return c.c.Close()
+}
+
+// more reports if requests from a batch are still waiting to be read
+func (c *jsonrpcCodec) more() bool {
+	return len(c.queue) > 0
+}
+
+// writeError writes an error response with a null id
+func (c *jsonrpcCodec) writeError(code int, msg string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.write(&jsonrpcResponse{
+		Version: "2.0",
+		Error:   &jsonrpcError{Code: code, Message: msg},
+		ID:      &nullID,
+	})
+}
+
+// write encodes a value as a line of JSON. callers must hold the lock
+func (c *jsonrpcCodec) write(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	c.wrote = true
+	_, err = c.w.Write(append(data, '\n'))
+	return err
+}
+
+// jsonrpcErrorCode picks the error code of a failed request
+func jsonrpcErrorCode(code int, msg string) int {
+	if code != 0 {
+		return code
+	}
+	if strings.HasPrefix(msg, "rpc: can't find") || strings.HasPrefix(msg, "rpc: service/method request ill-formed") {
+		return jsonrpcMethodNotFound
+	}
+	return jsonrpcServerError
+}
+
+// JSONRPCHandlers serves lib receivers as JSON-RPC 2.0 over http, using the
+// same method names as net/rpc, eg: "DatasetRequests.List"
+type JSONRPCHandlers struct {
+	ReadOnly bool
+	// RequireTokens must be set for requests to be served. lib receivers
+	// give full control of the node, so calls need an admin token, which the
+	// api only checks when it requires tokens
+	RequireTokens bool
+	server        *rpc.Server
+}
+
+// NewJSONRPCHandlers allocates a JSONRPCHandlers pointer
+func NewJSONRPCHandlers(server *rpc.Server, readOnly, requireTokens bool) *JSONRPCHandlers {
+	return &JSONRPCHandlers{ReadOnly: readOnly, RequireTokens: requireTokens, server: server}
+}
+
+// RPCHandler is the endpoint for JSON-RPC 2.0 requests. The request body can
+// be a single request or a batch
+func (h *JSONRPCHandlers) RPCHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "OPTIONS":
+		util.EmptyOkHandler(w, r)
+	case "POST":
+		if h.ReadOnly {
+			readOnlyResponse(w, "/rpc")
+			return
+		}
+		if !h.RequireTokens {
+			util.WriteErrResponse(w, http.StatusForbidden, fmt.Errorf("/rpc is only served when api tokens are required"))
+			return
+		}
+		// browsers can send cross-origin form posts without a preflight
+		// request, requiring JSON rules them out
+		if mt, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mt != "application/json" {
+			util.WriteErrResponse(w, http.StatusUnsupportedMediaType, fmt.Errorf("/rpc requests must have Content-Type: application/json"))
+			return
+		}
+		h.rpcHandler(w, r)
+	default:
+		util.NotFoundHandler(w, r)
+	}
+}
+
+func (h *JSONRPCHandlers) rpcHandler(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxJSONRPCRequestSize))
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("error reading request body: %s", err.Error()))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	buf := &bytes.Buffer{}
+	codec := newJSONRPCCodec(bytes.NewReader(body), buf, nil)
+	if len(bytes.TrimSpace(body)) == 0 {
+		codec.writeError(jsonrpcParseError, "parse error: empty request body")
+	} else {
+		// serve the first request, then any that follow it in a batch
+		for {
+			if err := h.server.ServeRequest(codec); err != nil && err != io.EOF {
+				log.Debugf("serving json-rpc request: %s", err.Error())
+			}
+			if !codec.more() {
+				break
+			}
+		}
+	}
+
+	if !codec.wrote {
+		// requests that are all notifications get no response
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,204 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/rpc"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/repo"
+	"github.com/qri-io/qri/repo/test"
+)
+
+// ArithRequests is a receiver for testing JSON-RPC transports
+type ArithRequests struct{}
+
+// ArithParams are the arguments to ArithRequests methods
+type ArithParams struct {
+	A, B int
+}
+
+func (ArithRequests) Add(p *ArithParams, res *int) error {
+	*res = p.A + p.B
+	return nil
+}
+
+func (ArithRequests) Div(p *ArithParams, res *int) error {
+	if p.B == 0 {
+		return fmt.Errorf("divide by zero")
+	}
+	*res = p.A / p.B
+	return nil
+}
+
+func newArithRPCServer(t *testing.T) *rpc.Server {
+	srv := rpc.NewServer()
+	if err := srv.Register(ArithRequests{}); err != nil {
+		t.Fatal(err.Error())
+	}
+	return srv
+}
+
+func TestJSONRPCHandler(t *testing.T) {
+	h := NewJSONRPCHandlers(newArithRPCServer(t), false, true)
+
+	cases := []struct {
+		description, body string
+		status            int
+		expect            string
+	}{
+		{"params object", `{"jsonrpc":"2.0","id":1,"method":"ArithRequests.Add","params":{"A":1,"B":2}}`, 200,
+			`{"jsonrpc":"2.0","result":3,"id":1}`},
+		{"params array", `{"jsonrpc":"2.0","id":"a","method":"ArithRequests.Add","params":[{"a":2,"b":2}]}`, 200,
+			`{"jsonrpc":"2.0","result":4,"id":"a"}`},
+		{"receiver error", `{"jsonrpc":"2.0","id":2,"method":"ArithRequests.Div","params":{"A":1}}`, 200,
+			`{"jsonrpc":"2.0","error":{"code":-32000,"message":"divide by zero"},"id":2}`},
+		{"method not found", `{"jsonrpc":"2.0","id":3,"method":"ArithRequests.Mul","params":{}}`, 200,
+			`{"jsonrpc":"2.0","error":{"code":-32601,"message":"rpc: can't find method ArithRequests.Mul"},"id":3}`},
+		{"invalid params", `{"jsonrpc":"2.0","id":4,"method":"ArithRequests.Add","params":[1,2]}`, 200,
+			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"invalid params: expected 1 param, got 2"},"id":4}`},
+		{"invalid request", `{"id":5,"method":"ArithRequests.Add"}`, 200,
+			`{"jsonrpc":"2.0","error":{"code":-32600,"message":"rpc: service/method request ill-formed: "},"id":5}`},
+		{"parse error", `{"jsonrpc":`, 200,
+			`{"jsonrpc":"2.0","error":{"code":-32700,"message":"parse error: unexpected EOF"},"id":null}`},
+		{"empty body", ``, 200,
+			`{"jsonrpc":"2.0","error":{"code":-32700,"message":"parse error: empty request body"},"id":null}`},
+		{"notification", `{"jsonrpc":"2.0","method":"ArithRequests.Add","params":{"A":1,"B":2}}`, 204, ``},
+		{"batch", `[{"jsonrpc":"2.0","id":1,"method":"ArithRequests.Add","params":{"A":1,"B":2}},{"jsonrpc":"2.0","method":"ArithRequests.Add","params":{}},{"jsonrpc":"2.0","id":2,"method":"ArithRequests.Div","params":{"A":6,"B":3}}]`, 200,
+			`[{"jsonrpc":"2.0","result":3,"id":1},{"jsonrpc":"2.0","result":2,"id":2}]`},
+		{"empty batch", `[]`, 200,
+			`{"jsonrpc":"2.0","error":{"code":-32600,"message":"invalid request: empty batch"},"id":null}`},
+	}
+
+	rpcRequest := func(body string) *http.Request {
+		req := httptest.NewRequest("POST", "/rpc", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		return req
+	}
+
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		h.RPCHandler(w, rpcRequest(c.body))
+		if w.Code != c.status {
+			t.Errorf("case '%s': status mismatch. expected: %d, got: %d", c.description, c.status, w.Code)
+			continue
+		}
+		if got := strings.TrimSpace(w.Body.String()); got != c.expect {
+			t.Errorf("case '%s': response mismatch.\nexpected: %s\ngot:      %s", c.description, c.expect, got)
+		}
+	}
+
+	// form posts can be sent cross-origin without a preflight request
+	w := httptest.NewRecorder()
+	req := rpcRequest(cases[0].body)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	h.RPCHandler(w, req)
+	if w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("expected non-JSON request to respond with 415, got: %d", w.Code)
+	}
+
+	h.RequireTokens = false
+	w = httptest.NewRecorder()
+	h.RPCHandler(w, rpcRequest(cases[0].body))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected request to a server that doesn't require tokens to respond with 403, got: %d", w.Code)
+	}
+
+	h.RequireTokens = true
+	h.ReadOnly = true
+	w = httptest.NewRecorder()
+	h.RPCHandler(w, rpcRequest(cases[0].body))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected read-only request to respond with 403, got: %d", w.Code)
+	}
+}
+
+func TestJSONRPCCodec(t *testing.T) {
+	srv := newArithRPCServer(t)
+	client, server := net.Pipe()
+	defer client.Close()
+	go srv.ServeCodec(NewJSONRPCCodec(server))
+
+	go func() {
+		client.Write([]byte(`{"jsonrpc":"2.0","id":1,"method":"ArithRequests.Add","params":{"A":1,"B":2}}
+{"jsonrpc":"2.0","id":2,"method":"ArithRequests.Add","params":{"A":3,"B":4}}`))
+	}()
+
+	results := map[string]int{}
+	sc := bufio.NewScanner(client)
+	for i := 0; i < 2 && sc.Scan(); i++ {
+		res := struct {
+			Result int             `json:"result"`
+			ID     json.RawMessage `json:"id"`
+		}{}
+		if err := json.Unmarshal(sc.Bytes(), &res); err != nil {
+			t.Fatal(err.Error())
+		}
+		results[string(res.ID)] = res.Result
+	}
+	if results["1"] != 3 || results["2"] != 7 {
+		t.Errorf("expected results for both requests, got: %v", results)
+	}
+}
+
+func TestJSONRPCRoute(t *testing.T) {
+	r, err := test.NewTestRepo(nil)
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err.Error())
+	}
+	body := `{"jsonrpc":"2.0","id":1,"method":"DatasetRequests.List","params":{"Limit":10}}`
+
+	// /rpc isn't served by default
+	s, err := New(r, func(c *config.Config) {
+		c.P2P.Enabled = false
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	w := httptest.NewRecorder()
+	NewServerRoutes(s).ServeHTTP(w, httptest.NewRequest("POST", "/rpc", strings.NewReader(body)))
+	if strings.Contains(w.Body.String(), `"jsonrpc"`) {
+		t.Errorf("expected /rpc not to serve JSON-RPC by default, got: %s", w.Body.String())
+	}
+
+	s, err = New(r, func(c *config.Config) {
+		c.P2P.Enabled = false
+		c.API.RequireTokens = true
+		c.RPC.HTTP = true
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	tok, secret, err := repo.NewToken(repo.TSAdmin, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := r.PutToken(tok); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	req := httptest.NewRequest("POST", "/rpc", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+secret)
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	NewServerRoutes(s).ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status mismatch. expected: 200, got: %d. body: %s", w.Code, w.Body.String())
+	}
+
+	res := struct {
+		Result []repo.DatasetRef `json:"result"`
+	}{}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(res.Result) != 5 {
+		t.Errorf("expected 5 datasets, got: %d. body: %s", len(res.Result), w.Body.String())
+	}
+}
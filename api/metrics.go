@@ -0,0 +1,78 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	util "github.com/datatogether/api/apiutil"
+	"github.com/qri-io/qri/metrics"
+)
+
+var (
+	// requestCount tracks api requests by the route that served them
+	requestCount = metrics.Default.NewCounter("qri_api_requests_total", "api requests by route, method & response status", "route", "method", "status")
+	// requestDuration tracks how long api requests take to serve
+	requestDuration = metrics.Default.NewHistogram("qri_api_request_duration_seconds", "api request latencies by route & method", metrics.DefaultBuckets, "route", "method")
+)
+
+// statusRecorder captures the status code a handler responds with
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader implements the http.ResponseWriter interface
+func (r *statusRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// Flush implements the http.Flusher interface, for streaming responses like
+// server-sent events
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// recordRequest records metrics for a served request. requests are labeled
+// with the route pattern that matched them, not the full path, so datasets
+// don't each get their own series
+func (s *Server) recordRequest(r *http.Request, status int, start time.Time) {
+	route := ""
+	if s.routes != nil {
+		_, route = s.routes.Handler(r)
+	}
+	requestCount.Inc(route, r.Method, strconv.Itoa(status))
+	requestDuration.Observe(time.Since(start).Seconds(), route, r.Method)
+}
+
+// MetricsHandlers exposes metrics in the prometheus text format
+type MetricsHandlers struct {
+	registry *metrics.Registry
+}
+
+// NewMetricsHandlers allocates a MetricsHandlers pointer
+func NewMetricsHandlers(registry *metrics.Registry) *MetricsHandlers {
+	return &MetricsHandlers{registry: registry}
+}
+
+// MetricsHandler is the endpoint for scraping metrics
+func (h *MetricsHandlers) MetricsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "OPTIONS":
+		util.EmptyOkHandler(w, r)
+	case "GET":
+		h.metricsHandler(w, r)
+	default:
+		util.NotFoundHandler(w, r)
+	}
+}
+
+func (h *MetricsHandlers) metricsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if err := h.registry.WriteText(w); err != nil {
+		log.Infof("error writing metrics: %s", err.Error())
+	}
+}
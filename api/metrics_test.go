@@ -0,0 +1,59 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/repo/test"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	r, err := test.NewTestRepo(nil)
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err.Error())
+	}
+	s, err := New(r, func(c *config.Config) {
+		c.P2P.Enabled = false
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	routes := NewServerRoutes(s)
+
+	served := requestCount.Value("/status", "GET", "200")
+	missing := requestCount.Value("/metrics", "PUT", "404")
+	for i := 0; i < 2; i++ {
+		routes.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/status", nil))
+	}
+	routes.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("PUT", "/metrics", nil))
+
+	if got := requestCount.Value("/status", "GET", "200") - served; got != 2 {
+		t.Errorf("expected 2 /status requests to be counted, got: %f", got)
+	}
+	if got := requestCount.Value("/metrics", "PUT", "404") - missing; got != 1 {
+		t.Errorf("expected response status to be recorded, got: %f", got)
+	}
+
+	w := httptest.NewRecorder()
+	routes.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status mismatch. expected: %d, got: %d", http.StatusOK, w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("content type mismatch. expected text/plain, got: %s", ct)
+	}
+
+	body := w.Body.String()
+	for _, expect := range []string{
+		"# TYPE qri_api_requests_total counter\n",
+		`qri_api_request_duration_seconds_count{route="/status",method="GET"}`,
+		"# TYPE qri_repo_refs gauge\n",
+	} {
+		if !strings.Contains(body, expect) {
+			t.Errorf("expected metrics to contain '%s'. got:\n%s", expect, body)
+		}
+	}
+}
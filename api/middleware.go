@@ -14,6 +14,11 @@ func (s *Server) middleware(handler http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		log.Infof("%s %s %s", r.Method, r.URL.Path, time.Now())
 
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		w = rec
+		defer func() { s.recordRequest(r, rec.status, start) }()
+
 		// If this server is operating behind a proxy, but we still want to force
 		// users to use https, cfg.ProxyForceHttps == true will listen for the common
 		// X-Forward-Proto & redirect to https
@@ -33,6 +38,11 @@ func (s *Server) middleware(handler http.HandlerFunc) http.HandlerFunc {
 		// }
 		s.addCORSHeaders(w, r)
 
+		r, ok := s.authCheck(w, r)
+		if !ok {
+			return
+		}
+
 		if ok := s.readOnlyCheck(r); ok {
 			handler(w, r)
 		} else {
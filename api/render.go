@@ -5,9 +5,18 @@ import (
 
 	"github.com/datatogether/api/apiutil"
 	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/render"
 	"github.com/qri-io/qri/repo"
 )
 
+const (
+	// defaultRenderLimit & maxRenderLimit bound the number of body entries
+	// a rendered page includes. larger bodies are paged on the server with
+	// the limit & offset params
+	defaultRenderLimit = 500
+	maxRenderLimit     = 5000
+)
+
 // RenderHandlers wraps a requests struct to interface with http.HandlerFunc
 type RenderHandlers struct {
 	lib.RenderRequests
@@ -34,13 +43,29 @@ func (h *RenderHandlers) RenderHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// rendered pages are revalidated instead of cached as immutable, see
+	// renderETag
+	etag := renderETag(args.Path, r.URL.Query().Encode())
+	if args.Path != "" && writeNotModified(w, r, etag, false) {
+		return
+	}
+
+	// output is always rendered in the format of the dataset's viz template.
+	// letting requests choose would run html templates through engines that
+	// don't escape
+	ref := &repo.DatasetRef{}
+	if err := lib.NewDatasetRequests(h.repo, nil).Get(&args, ref); err != nil {
+		apiutil.WriteErrResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	limit, offset := renderPage(r)
 	p := &lib.RenderParams{
-		Ref:            args,
-		TemplateFormat: "html",
-		// TODO - parameterize
-		All:    true,
-		Limit:  0,
-		Offset: 0,
+		Ref:    args,
+		Limit:  limit,
+		Offset: offset,
+		// served pages describe their dataset for search engines & catalogs
+		JSONLD: true,
 	}
 
 	data := []byte{}
@@ -49,5 +74,37 @@ func (h *RenderHandlers) RenderHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if args.Path != "" {
+		setDatasetCacheHeaders(w, r, etag, false)
+	}
+	w.Header().Set("Content-Type", render.ContentType(lib.VizFormat(ref.Dataset)))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
 	w.Write(data)
 }
+
+// renderPage reads the page of body entries a render request asks for,
+// keeping the limit between 1 & maxRenderLimit
+func renderPage(r *http.Request) (limit, offset int) {
+	limit, err := apiutil.ReqParamInt("limit", r)
+	if err != nil || limit < 1 {
+		limit = defaultRenderLimit
+	}
+	if limit > maxRenderLimit {
+		limit = maxRenderLimit
+	}
+	if offset, err = apiutil.ReqParamInt("offset", r); err != nil || offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
+
+// renderETag gives the etag of a rendered dataset version. The default
+// template ships with qri & can be updated while qri runs, so output can
+// change for the same dataset version
+func renderETag(path, query string) string {
+	templateHash := ""
+	if lib.Config != nil && lib.Config.Render != nil {
+		templateHash = lib.Config.Render.DefaultTemplateHash
+	}
+	return datasetETag(path, "render", query, lib.VersionNumber, templateHash)
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	golog "github.com/ipfs/go-log"
+	"github.com/qri-io/qri/repo/test"
+)
+
+func TestRenderPage(t *testing.T) {
+	cases := []struct {
+		query         string
+		limit, offset int
+	}{
+		{"", defaultRenderLimit, 0},
+		{"?limit=10&offset=20", 10, 20},
+		{"?limit=0&offset=-1", defaultRenderLimit, 0},
+		{"?limit=1000000", maxRenderLimit, 0},
+		{"?limit=many&offset=few", defaultRenderLimit, 0},
+	}
+	for i, c := range cases {
+		limit, offset := renderPage(httptest.NewRequest("GET", "/render/me/movies"+c.query, nil))
+		if limit != c.limit || offset != c.offset {
+			t.Errorf("case %d: expected limit %d & offset %d, got: %d, %d", i, c.limit, c.offset, limit, offset)
+		}
+	}
+}
+
+func TestRenderHandlerLimit(t *testing.T) {
+	golog.SetLogLevel("qriapi", "error")
+	defer golog.SetLogLevel("qriapi", "info")
+
+	r, err := test.NewTestRepo(nil)
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err.Error())
+	}
+	h := NewRenderHandlers(r)
+
+	rows := func(query string) int {
+		w := httptest.NewRecorder()
+		h.RenderHandler(w, httptest.NewRequest("GET", "/render/me/movies"+query, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("status mismatch. expected: %d, got: %d. body: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		return strings.Count(w.Body.String(), "<td>")
+	}
+
+	one, three := rows("?limit=1"), rows("?limit=3")
+	if one == 0 || three != 3*one {
+		t.Errorf("expected rendered rows to follow the limit. limit=1: %d cells, limit=3: %d cells", one, three)
+	}
+}
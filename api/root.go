@@ -49,6 +49,11 @@ func (mh *RootHandler) Handler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	byPath := ref.Path != ""
+	if byPath && writeNotModified(w, r, datasetETag(ref.Path), byPath) {
+		return
+	}
+
 	res := repo.DatasetRef{}
 
 	err := mh.dsh.Get(&ref, &res)
@@ -61,6 +66,9 @@ func (mh *RootHandler) Handler(w http.ResponseWriter, r *http.Request) {
 		util.WriteErrResponse(w, http.StatusNotFound, errors.New("cannot find peer dataset"))
 		return
 	}
+	if res.Path != "" {
+		setDatasetCacheHeaders(w, r, datasetETag(res.Path), byPath)
+	}
 	util.WriteResponse(w, res)
 	return
 }
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	util "github.com/datatogether/api/apiutil"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/repo"
+)
+
+// ScheduleHandlers wraps a requests struct to interface with http.HandlerFunc
+type ScheduleHandlers struct {
+	lib.ScheduleRequests
+	ReadOnly bool
+}
+
+// NewScheduleHandlers allocates a ScheduleHandlers pointer
+func NewScheduleHandlers(r repo.Repo, readOnly bool) *ScheduleHandlers {
+	req := lib.NewScheduleRequests(r, nil)
+	return &ScheduleHandlers{*req, readOnly}
+}
+
+// SchedulesHandler lists, adds & removes scheduled dataset updates
+func (h *ScheduleHandlers) SchedulesHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "OPTIONS":
+		util.EmptyOkHandler(w, r)
+	case "GET":
+		if h.ReadOnly {
+			readOnlyResponse(w, "/schedules")
+			return
+		}
+		h.listSchedulesHandler(w, r)
+	case "POST", "PUT":
+		h.addScheduleHandler(w, r)
+	case "DELETE":
+		h.removeScheduleHandler(w, r)
+	default:
+		util.NotFoundHandler(w, r)
+	}
+}
+
+func (h *ScheduleHandlers) listSchedulesHandler(w http.ResponseWriter, r *http.Request) {
+	p := lib.ListParamsFromRequest(r)
+	res := []*repo.Schedule{}
+	if err := h.List(&p, &res); err != nil {
+		log.Infof("error listing schedules: %s", err.Error())
+		util.WriteErrResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err := util.WritePageResponse(w, res, r, p.Page()); err != nil {
+		log.Infof("error list schedules response: %s", err.Error())
+	}
+}
+
+// scheduleReqParams is an encoding struct that maps to lib.ScheduleParams
+type scheduleReqParams struct {
+	Ref      string `json:"ref"`
+	Spec     string `json:"spec"`
+	BodyPath string `json:"bodyPath"`
+}
+
+func (h *ScheduleHandlers) addScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	reqParams := &scheduleReqParams{}
+	if r.Header.Get("Content-Type") == "application/json" {
+		if err := json.NewDecoder(r.Body).Decode(reqParams); err != nil {
+			util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("error decoding body into params: %s", err.Error()))
+			return
+		}
+	} else {
+		reqParams.Ref = r.FormValue("ref")
+		reqParams.Spec = r.FormValue("spec")
+		reqParams.BodyPath = r.FormValue("body_path")
+	}
+
+	ref, err := repo.ParseDatasetRef(reqParams.Ref)
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("error parsing ref param: %s", err.Error()))
+		return
+	}
+
+	p := &lib.ScheduleParams{
+		Ref:      ref,
+		Spec:     reqParams.Spec,
+		BodyPath: reqParams.BodyPath,
+	}
+	res := &repo.Schedule{}
+	if err := h.Add(p, res); err != nil {
+		log.Infof("error adding schedule: %s", err.Error())
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	util.WriteResponse(w, res)
+}
+
+func (h *ScheduleHandlers) removeScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	ref, err := repo.ParseDatasetRef(r.FormValue("ref"))
+	if err != nil {
+		util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("error parsing ref param: %s", err.Error()))
+		return
+	}
+
+	done := false
+	if err := h.Remove(&ref, &done); err != nil {
+		log.Infof("error removing schedule: %s", err.Error())
+		util.WriteErrResponse(w, http.StatusBadRequest, err)
+		return
+	}
+	util.WriteResponse(w, ref)
+}
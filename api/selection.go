@@ -0,0 +1,110 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	util "github.com/datatogether/api/apiutil"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/repo"
+)
+
+// SelectionHandlers wraps a requests struct to interface with http.HandlerFunc
+type SelectionHandlers struct {
+	lib.SelectionRequests
+	ReadOnly bool
+}
+
+// NewSelectionHandlers allocates a SelectionHandlers pointer
+func NewSelectionHandlers(r repo.Repo, readOnly bool) *SelectionHandlers {
+	req := lib.NewSelectionRequests(r, nil)
+	return &SelectionHandlers{*req, readOnly}
+}
+
+// UseHandler lists, sets & clears the selected datasets that requests default
+// to when no dataset is specified
+func (h *SelectionHandlers) UseHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "OPTIONS":
+		util.EmptyOkHandler(w, r)
+	case "GET":
+		if h.ReadOnly {
+			readOnlyResponse(w, "/use")
+			return
+		}
+		h.selectedRefsHandler(w, r)
+	case "POST", "PUT":
+		if h.ReadOnly {
+			readOnlyResponse(w, "/use")
+			return
+		}
+		h.selectRefsHandler(w, r)
+	case "DELETE":
+		if h.ReadOnly {
+			readOnlyResponse(w, "/use")
+			return
+		}
+		h.setSelectedRefs(w, []repo.DatasetRef{})
+	default:
+		util.NotFoundHandler(w, r)
+	}
+}
+
+func (h *SelectionHandlers) selectedRefsHandler(w http.ResponseWriter, r *http.Request) {
+	done := false
+	res := []repo.DatasetRef{}
+	if err := h.SelectedRefs(&done, &res); err != nil {
+		log.Infof("error getting selected datasets: %s", err.Error())
+		util.WriteErrResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	if res == nil {
+		res = []repo.DatasetRef{}
+	}
+	util.WriteResponse(w, res)
+}
+
+// useReqParams is an encoding struct for selecting datasets with JSON
+type useReqParams struct {
+	Refs []string `json:"refs"`
+}
+
+func (h *SelectionHandlers) selectRefsHandler(w http.ResponseWriter, r *http.Request) {
+	reqParams := &useReqParams{}
+	if r.Header.Get("Content-Type") == "application/json" {
+		if err := json.NewDecoder(r.Body).Decode(reqParams); err != nil {
+			util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("error decoding body into params: %s", err.Error()))
+			return
+		}
+	} else {
+		reqParams.Refs = queryList(r, "ref")
+	}
+
+	if len(reqParams.Refs) == 0 {
+		util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("at least one dataset reference is required. use DELETE to clear the selection"))
+		return
+	}
+
+	refs := make([]repo.DatasetRef, len(reqParams.Refs))
+	for i, refstr := range reqParams.Refs {
+		ref, err := repo.ParseDatasetRef(strings.TrimSpace(refstr))
+		if err != nil {
+			util.WriteErrResponse(w, http.StatusBadRequest, fmt.Errorf("error parsing ref '%s': %s", refstr, err.Error()))
+			return
+		}
+		refs[i] = ref
+	}
+	h.setSelectedRefs(w, refs)
+}
+
+func (h *SelectionHandlers) setSelectedRefs(w http.ResponseWriter, refs []repo.DatasetRef) {
+	done := false
+	if err := h.SetSelectedRefs(&refs, &done); err != nil {
+		log.Infof("error selecting datasets: %s", err.Error())
+		util.WriteErrResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+	util.WriteResponse(w, refs)
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/beme/abide"
+	golog "github.com/ipfs/go-log"
+	"github.com/qri-io/qri/repo/test"
+)
+
+func TestUseHandler(t *testing.T) {
+	// bump up log level to keep test output clean
+	golog.SetLogLevel("qriapi", "error")
+	defer golog.SetLogLevel("qriapi", "info")
+
+	r, err := test.NewTestRepo(nil)
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err.Error())
+	}
+
+	cases := []struct {
+		name, method, endpoint, contentType string
+		body                                []byte
+		readOnly                            bool
+	}{
+		{"OPTIONS", "OPTIONS", "/use", "", nil, false},
+		{"GET empty", "GET", "/use", "", nil, false},
+		{"POST", "POST", "/use", "application/json", []byte(`{"refs":["peer/movies","peer/cities"]}`), false},
+		{"GET", "GET", "/use", "", nil, false},
+		{"PUT query", "PUT", "/use?ref=peer/counter", "", nil, false},
+		{"POST no refs", "POST", "/use", "application/json", []byte(`{"refs":[]}`), false},
+		{"POST bad data", "POST", "/use", "application/json", []byte(``), false},
+		{"DELETE", "DELETE", "/use", "", nil, false},
+		{"GET read-only", "GET", "/use", "", nil, true},
+		{"POST read-only", "POST", "/use?ref=peer/counter", "", nil, true},
+		{"bad method", "PATCH", "/use", "", nil, false},
+	}
+
+	selh := NewSelectionHandlers(r, false)
+	for _, c := range cases {
+		name := fmt.Sprintf("Use Test: %s", c.name)
+		req := httptest.NewRequest(c.method, c.endpoint, bytes.NewBuffer(c.body))
+		if c.contentType != "" {
+			req.Header.Set("Content-Type", c.contentType)
+		}
+		w := httptest.NewRecorder()
+
+		selh.ReadOnly = c.readOnly
+		selh.UseHandler(w, req)
+		abide.AssertHTTPResponse(t, name, w.Result())
+	}
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net"
 	"net/http"
 	"net/rpc"
+	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/datatogether/api/apiutil"
@@ -15,6 +18,7 @@ import (
 	"github.com/qri-io/cafs"
 	"github.com/qri-io/qri/config"
 	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/metrics"
 	"github.com/qri-io/qri/p2p"
 	"github.com/qri-io/qri/repo"
 )
@@ -31,6 +35,13 @@ type Server struct {
 	// configuration options
 	cfg     *config.Config
 	qriNode *p2p.QriNode
+	// signatures tracks signed requests to reject replays
+	signatures *signatureCache
+	// rpc serves lib receivers over gob & JSON-RPC transports
+	rpc *rpc.Server
+	// routes is the mux created by NewServerRoutes, used to label request
+	// metrics by route
+	routes *http.ServeMux
 }
 
 // New creates a new qri server with optional configuration
@@ -43,7 +54,8 @@ func New(r repo.Repo, options ...func(*config.Config)) (s *Server, err error) {
 	}
 
 	s = &Server{
-		cfg: cfg,
+		cfg:        cfg,
+		signatures: newSignatureCache(),
 	}
 
 	// allocate a new node
@@ -54,6 +66,15 @@ func New(r repo.Repo, options ...func(*config.Config)) (s *Server, err error) {
 		return s, err
 	}
 
+	s.rpc = rpc.NewServer()
+	for _, rcvr := range lib.Receivers(s.qriNode) {
+		if err := s.rpc.Register(rcvr); err != nil {
+			return s, fmt.Errorf("error registering RPC receiver %s: %s", rcvr.CoreRequestsName(), err.Error())
+		}
+	}
+
+	lib.RegisterMetrics(s.qriNode)
+
 	return s, nil
 }
 
@@ -63,7 +84,10 @@ func (s *Server) Serve() (err error) {
 	server.Handler = NewServerRoutes(s)
 
 	go s.ServeRPC()
+	go s.ServeJSONRPCSocket()
 	go s.ServeWebapp()
+	go s.ServeScheduler()
+	go s.ServeWebhooks()
 
 	// func(p2pcfg *config.P2P) {
 	// p2pcfg.Online = s.cfg.Online
@@ -153,15 +177,89 @@ func (s *Server) ServeRPC() {
 		return
 	}
 
-	for _, rcvr := range lib.Receivers(s.qriNode) {
-		if err := rpc.Register(rcvr); err != nil {
-			log.Infof("error registering RPC receiver %s: %s", rcvr.CoreRequestsName(), err.Error())
+	s.rpc.Accept(listener)
+	return
+}
+
+// ServeJSONRPCSocket serves JSON-RPC 2.0 requests on a unix domain socket if
+// one is configured. The socket is only accessible to the current user
+func (s *Server) ServeJSONRPCSocket() {
+	if !s.cfg.RPC.Enabled || s.cfg.RPC.Socket == "" {
+		return
+	}
+
+	path := s.cfg.RPC.Socket
+	// remove a socket left behind by a previous run
+	if fi, err := os.Stat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		os.Remove(path)
+	}
+
+	listener, err := listenPrivateSocket(path)
+	if err != nil {
+		log.Infof("JSON-RPC listen on socket %s error: %s", path, err)
+		return
+	}
+	defer os.Remove(path)
+	defer listener.Close()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			log.Infof("JSON-RPC socket accept error: %s", err)
 			return
 		}
+		go s.rpc.ServeCodec(NewJSONRPCCodec(conn))
 	}
+}
 
-	rpc.Accept(listener)
-	return
+// listenPrivateSocket listens on a unix socket at path that only the current
+// user can connect to. The socket is created in a private directory & only
+// moved to path once its permissions are set, so it's never reachable with
+// the permissions the process umask gives new files
+func listenPrivateSocket(path string) (net.Listener, error) {
+	// names are kept short, socket paths have a length limit
+	dir, err := ioutil.TempDir(filepath.Dir(path), ".qri")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	tmp := filepath.Join(dir, "s")
+	listener, err := net.Listen("unix", tmp)
+	if err != nil {
+		return nil, err
+	}
+	// the socket is moved, closing the listener can't remove it
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	if err := os.Chmod(tmp, 0600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("setting socket permissions: %s", err.Error())
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+// ServeScheduler runs scheduled dataset updates for as long as the server is up
+func (s *Server) ServeScheduler() {
+	sched := lib.NewScheduler(s.qriNode.Repo, s.qriNode)
+	if err := sched.Start(context.Background()); err != nil {
+		log.Infof("scheduler stopped: %s", err.Error())
+	}
+}
+
+// ServeWebhooks sends repo events to configured webhooks for as long as the
+// server is up
+func (s *Server) ServeWebhooks() {
+	if s.cfg.Webhooks == nil || len(s.cfg.Webhooks.Hooks) == 0 {
+		return
+	}
+	wh := lib.NewWebhooks(s.qriNode.Repo, s.cfg.Webhooks)
+	if err := wh.Start(context.Background()); err != nil {
+		log.Infof("webhooks stopped: %s", err.Error())
+	}
 }
 
 // HandleIPFSPath responds to IPFS Hash requests with raw data
@@ -261,6 +359,7 @@ func NewServerRoutes(s *Server) *http.ServeMux {
 	m.Handle("/export/", s.middleware(dsh.ZipDatasetHandler))
 	m.Handle("/diff", s.middleware(dsh.DiffHandler))
 	m.Handle("/body/", s.middleware(dsh.BodyHandler))
+	m.Handle("/validate", s.middleware(dsh.ValidateHandler))
 
 	renderh := NewRenderHandlers(s.qriNode.Repo)
 	m.Handle("/render/", s.middleware(renderh.RenderHandler))
@@ -276,8 +375,33 @@ func NewServerRoutes(s *Server) *http.ServeMux {
 	sh := NewSearchHandlers(s.qriNode.Repo)
 	m.Handle("/search", s.middleware(sh.SearchHandler))
 
+	sch := NewScheduleHandlers(s.qriNode.Repo, s.cfg.API.ReadOnly)
+	m.Handle("/schedules", s.middleware(sch.SchedulesHandler))
+
+	eh := NewEventHandlers(s.qriNode.Repo, s.cfg.API.ReadOnly)
+	m.Handle("/events", s.middleware(eh.EventsHandler))
+	m.Handle("/events/stream", s.middleware(eh.StreamHandler))
+
+	ch := NewConfigHandlers(s.cfg.API.ReadOnly)
+	m.Handle("/config", s.middleware(ch.ConfigHandler))
+
+	selh := NewSelectionHandlers(s.qriNode.Repo, s.cfg.API.ReadOnly)
+	m.Handle("/use", s.middleware(selh.UseHandler))
+
+	gh := NewGraphQLHandlers(s.qriNode.Repo, s.qriNode, s.cfg.API.ReadOnly)
+	m.Handle("/graphql", s.middleware(gh.GraphQLHandler))
+
+	if s.cfg.RPC.Enabled && s.cfg.RPC.HTTP {
+		rpch := NewJSONRPCHandlers(s.rpc, s.cfg.API.ReadOnly, s.cfg.API.RequireTokens)
+		m.Handle("/rpc", s.middleware(rpch.RPCHandler))
+	}
+
+	mh := NewMetricsHandlers(metrics.Default)
+	m.Handle("/metrics", s.middleware(mh.MetricsHandler))
+
 	rh := NewRootHandler(dsh, ph)
 	m.Handle("/", s.datasetRefMiddleware(s.middleware(rh.Handler)))
 
+	s.routes = m
 	return m
 }
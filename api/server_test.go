@@ -332,6 +332,14 @@ func TestServerReadOnlyRoutes(t *testing.T) {
 		{"GET", "/diff", 403},
 		{"GET", "/body/", 403},
 		{"POST", "/registry/", 403},
+		{"GET", "/validate", 403},
+		{"POST", "/validate", 403},
+		{"GET", "/config", 403},
+		{"POST", "/config", 403},
+		{"GET", "/use", 403},
+		{"POST", "/use", 403},
+		{"DELETE", "/use", 403},
+		{"GET", "/events", 403},
 
 		// active endpoints:
 		{"GET", "/status", 200},
@@ -356,6 +364,11 @@ func TestServerReadOnlyRoutes(t *testing.T) {
 		{"OPTIONS", "/me/", 200},
 		{"OPTIONS", "/list/", 200},
 		{"OPTIONS", "/history/", 200},
+		{"OPTIONS", "/validate", 200},
+		{"OPTIONS", "/config", 200},
+		{"OPTIONS", "/use", 200},
+		{"OPTIONS", "/events", 200},
+		{"OPTIONS", "/graphql", 200},
 	}
 
 	for i, c := range cases {
@@ -546,3 +559,41 @@ func NewFilesRequest(method, endpoint, url string, filePaths, params map[string]
 
 	return req, nil
 }
+
+func TestListenPrivateSocket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "qri_rpc_socket")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "rpc.sock")
+
+	l, err := listenPrivateSocket(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode()&os.ModeSocket == 0 || fi.Mode().Perm() != 0600 {
+		t.Errorf("expected a socket only the current user can use, got mode: %s", fi.Mode())
+	}
+	// the private directory the socket was created in is removed
+	if fis, _ := ioutil.ReadDir(dir); len(fis) != 1 {
+		t.Errorf("expected only the socket to be left in %s, got %d files", dir, len(fis))
+	}
+
+	go func() {
+		if conn, err := l.Accept(); err == nil {
+			conn.Close()
+		}
+	}()
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatalf("connecting to socket: %s", err.Error())
+	}
+	conn.Close()
+}
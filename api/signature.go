@@ -0,0 +1,203 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p-crypto"
+	"github.com/libp2p/go-libp2p-peer"
+	"github.com/qri-io/qri/repo/profile"
+)
+
+// SignatureScheme is the Authorization header scheme for signed requests.
+// Signed requests carry a header of the form "QriSignature id=<profileID>,
+// ts=<unix seconds>,key=<base64 public key>,sig=<base64 signature>"
+const SignatureScheme = "QriSignature"
+
+// SignatureMaxAge is how far a signed request's timestamp can be from the
+// server's clock. signatures are only accepted once within this window
+var SignatureMaxAge = 5 * time.Minute
+
+// SignedBodyMaxSize is the largest request body accepted with a signed
+// request. bodies are read into memory to check their signature
+var SignedBodyMaxSize int64 = 32 << 20
+
+// errSignedBodyTooLarge is returned for signed requests with a body larger
+// than SignedBodyMaxSize
+var errSignedBodyTooLarge = fmt.Errorf("signed request body is too large")
+
+// SignRequest signs an http request with a profile private key, setting its
+// Authorization header. The signature covers the request method, path &
+// query, a timestamp, and a hash of the request body
+func SignRequest(r *http.Request, pk crypto.PrivKey, now time.Time) error {
+	pub, err := crypto.MarshalPublicKey(pk.GetPublic())
+	if err != nil {
+		return err
+	}
+	pid, err := peer.IDFromPublicKey(pk.GetPublic())
+	if err != nil {
+		return err
+	}
+	body, err := readRequestBody(r)
+	if err != nil {
+		return err
+	}
+
+	ts := strconv.FormatInt(now.Unix(), 10)
+	sig, err := pk.Sign(signedMessage(r, ts, body))
+	if err != nil {
+		return err
+	}
+
+	r.Header.Set("Authorization", fmt.Sprintf("%s id=%s,ts=%s,key=%s,sig=%s", SignatureScheme,
+		pid.Pretty(), ts, base64.StdEncoding.EncodeToString(pub), base64.StdEncoding.EncodeToString(sig)))
+	return nil
+}
+
+// verifySignedRequest checks the signature of a request, returning the
+// profile ID of the signer. Only this node's own profile & profiles the
+// repo knows about can sign requests
+func (s *Server) verifySignedRequest(w http.ResponseWriter, r *http.Request, now time.Time) (string, error) {
+	params := signatureParams(r)
+	if params["id"] == "" || params["ts"] == "" || params["key"] == "" || params["sig"] == "" {
+		return "", fmt.Errorf("signature must include id, ts, key & sig")
+	}
+
+	sec, err := strconv.ParseInt(params["ts"], 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid signature timestamp")
+	}
+	ts := time.Unix(sec, 0)
+	if ts.Before(now.Add(-SignatureMaxAge)) || ts.After(now.Add(SignatureMaxAge)) {
+		return "", fmt.Errorf("signature has expired")
+	}
+
+	data, err := base64.StdEncoding.Strict().DecodeString(params["key"])
+	if err != nil {
+		return "", fmt.Errorf("invalid signature public key")
+	}
+	pub, err := crypto.UnmarshalPublicKey(data)
+	if err != nil {
+		return "", fmt.Errorf("invalid signature public key")
+	}
+	// profile IDs are derived from public keys, so a key can only sign for
+	// the profile it belongs to
+	pid, err := peer.IDFromPublicKey(pub)
+	if err != nil || pid.Pretty() != params["id"] {
+		return "", fmt.Errorf("signature public key doesn't match profile %s", params["id"])
+	}
+
+	sig, err := base64.StdEncoding.Strict().DecodeString(params["sig"])
+	if err != nil {
+		return "", fmt.Errorf("invalid signature")
+	}
+
+	// check the signer is known before reading the body
+	if pro, err := s.qriNode.Repo.Profile(); err != nil || pro.ID.String() != params["id"] {
+		if _, err := s.qriNode.Repo.Profiles().GetProfile(profile.ID(pid)); err != nil {
+			return "", fmt.Errorf("unknown profile: %s", params["id"])
+		}
+	}
+
+	if r.Body != nil {
+		r.Body = http.MaxBytesReader(w, r.Body, SignedBodyMaxSize)
+	}
+	body, err := readRequestBody(r)
+	if err != nil {
+		if int64(len(body)) >= SignedBodyMaxSize {
+			return "", errSignedBodyTooLarge
+		}
+		return "", err
+	}
+	msg := signedMessage(r, params["ts"], body)
+	if ok, err := pub.Verify(msg, sig); err != nil || !ok {
+		return "", fmt.Errorf("invalid signature")
+	}
+
+	// the same request can be signed with different encodings of a signature,
+	// so used signatures are remembered by what they sign
+	if !s.signatures.add(replayKey(params["id"], msg), ts, now) {
+		return "", fmt.Errorf("signature has already been used")
+	}
+	return params["id"], nil
+}
+
+// replayKey identifies a signed request for the replay cache
+func replayKey(id string, msg []byte) string {
+	sum := sha256.Sum256(append([]byte(id+"\n"), msg...))
+	return hex.EncodeToString(sum[:])
+}
+
+// signedMessage gives the bytes that are signed for a request
+func signedMessage(r *http.Request, ts string, body []byte) []byte {
+	sum := sha256.Sum256(body)
+	return []byte(strings.Join([]string{r.Method, r.URL.RequestURI(), ts, hex.EncodeToString(sum[:])}, "\n"))
+}
+
+// signatureParams reads the key=value pairs of a signed request's
+// Authorization header
+func signatureParams(r *http.Request) map[string]string {
+	params := map[string]string{}
+	auth := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), SignatureScheme))
+	for _, pair := range strings.Split(auth, ",") {
+		kv := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(kv) == 2 {
+			params[kv[0]] = kv[1]
+		}
+	}
+	return params
+}
+
+// readRequestBody reads the full body of a request, replacing it so it can
+// be read again. On error it returns whatever was read before the error
+func readRequestBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil {
+		return nil, nil
+	}
+	data, err := ioutil.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		return data, err
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
+
+// signatureCache remembers recently used signatures to reject replayed
+// requests
+type signatureCache struct {
+	sync.Mutex
+	seen map[string]time.Time
+}
+
+func newSignatureCache() *signatureCache {
+	return &signatureCache{seen: map[string]time.Time{}}
+}
+
+// add records a signed request, returning false if it's already been
+// seen. requests older than SignatureMaxAge are dropped, as they'll fail the
+// timestamp check anyway
+func (c *signatureCache) add(key string, ts, now time.Time) bool {
+	c.Lock()
+	defer c.Unlock()
+
+	for k, t := range c.seen {
+		if t.Before(now.Add(-SignatureMaxAge)) {
+			delete(c.seen, k)
+		}
+	}
+	if _, ok := c.seen[key]; ok {
+		return false
+	}
+	c.seen[key] = ts
+	return true
+}
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"bytes"
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	golog "github.com/ipfs/go-log"
+	"github.com/libp2p/go-libp2p-crypto"
+	"github.com/libp2p/go-libp2p-peer"
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/repo"
+	"github.com/qri-io/qri/repo/profile"
+	"github.com/qri-io/qri/repo/test"
+)
+
+func TestSignedRequests(t *testing.T) {
+	// bump up log level to keep test output clean
+	golog.SetLogLevel("qriapi", "error")
+	defer golog.SetLogLevel("qriapi", "info")
+
+	r, err := test.NewTestRepo(nil)
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err.Error())
+	}
+
+	lib.Config = config.DefaultConfig()
+	lib.Config.Profile = test.ProfileConfig()
+
+	s, err := New(r, func(c *config.Config) {
+		c.P2P.Enabled = false
+		c.API.RequireTokens = true
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	pro, err := r.Profile()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	newPeer := func(peername string) crypto.PrivKey {
+		pk, _, err := crypto.GenerateKeyPairWithReader(crypto.RSA, 2048, rand.Reader)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		pid, err := peer.IDFromPublicKey(pk.GetPublic())
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if err := r.Profiles().PutProfile(&profile.Profile{ID: profile.ID(pid), Peername: peername}); err != nil {
+			t.Fatal(err.Error())
+		}
+		return pk
+	}
+
+	other := newPeer("other")
+	ungranted := newPeer("ungranted")
+	stranger, _, err := crypto.GenerateKeyPairWithReader(crypto.RSA, 2048, rand.Reader)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	otherID, _ := peer.IDFromPublicKey(other.GetPublic())
+	grant, err := repo.NewPeerGrant(otherID.Pretty(), repo.TSWrite, []string{"peer/cities"})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := r.PutToken(grant); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	routes := NewServerRoutes(s)
+	do := func(req *http.Request) int {
+		w := httptest.NewRecorder()
+		routes.ServeHTTP(w, req)
+		return w.Code
+	}
+	signed := func(method, endpoint string, pk crypto.PrivKey, ts time.Time) *http.Request {
+		req := httptest.NewRequest(method, endpoint, nil)
+		if err := SignRequest(req, pk, ts); err != nil {
+			t.Fatal(err.Error())
+		}
+		return req
+	}
+
+	now := time.Now()
+	cases := []struct {
+		description string
+		req         *http.Request
+		status      int
+	}{
+		{"own profile", signed("GET", "/list", pro.PrivKey, now), 200},
+		{"granted peer", signed("GET", "/me/cities", other, now), 200},
+		{"dataset outside grant", signed("GET", "/me/movies", other, now), 403},
+		{"scope outside grant", signed("POST", "/remove/me/cities", other, now), 403},
+		{"known peer without a grant", signed("GET", "/list", ungranted, now), 403},
+		{"unknown peer", signed("GET", "/list", stranger, now), 401},
+		{"expired signature", signed("GET", "/list", other, now.Add(-2*SignatureMaxAge)), 401},
+	}
+	for _, c := range cases {
+		if got := do(c.req); got != c.status {
+			t.Errorf("%s: status mismatch. expected: %d, got: %d", c.description, c.status, got)
+		}
+	}
+
+	// replayed requests are rejected
+	req := signed("GET", "/me/cities", other, now.Add(time.Second))
+	replay := httptest.NewRequest("GET", "/me/cities", nil)
+	replay.Header.Set("Authorization", req.Header.Get("Authorization"))
+	if got := do(req); got != 200 {
+		t.Errorf("expected first request to succeed, got: %d", got)
+	}
+	if got := do(replay); got != 401 {
+		t.Errorf("expected replayed request to be unauthorized, got: %d", got)
+	}
+
+	// the signature covers the request body
+	req = httptest.NewRequest("POST", "/rename", bytes.NewReader([]byte(`{"current":"me/cities","new":"me/towns"}`)))
+	if err := SignRequest(req, pro.PrivKey, now.Add(2*time.Second)); err != nil {
+		t.Fatal(err.Error())
+	}
+	tampered := httptest.NewRequest("POST", "/rename", bytes.NewReader([]byte(`{"current":"me/movies","new":"me/films"}`)))
+	tampered.Header.Set("Authorization", req.Header.Get("Authorization"))
+	if got := do(tampered); got != 401 {
+		t.Errorf("expected request with an altered body to be unauthorized, got: %d", got)
+	}
+
+	// bodies are only read up to a limit
+	defer func(max int64) { SignedBodyMaxSize = max }(SignedBodyMaxSize)
+	SignedBodyMaxSize = 16
+	req = httptest.NewRequest("POST", "/rename", bytes.NewReader([]byte(`{"current":"me/cities","new":"me/towns"}`)))
+	if err := SignRequest(req, pro.PrivKey, now.Add(3*time.Second)); err != nil {
+		t.Fatal(err.Error())
+	}
+	if got := do(req); got != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected request with a body over the limit to be refused with 413, got: %d", got)
+	}
+}
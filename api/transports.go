@@ -2,18 +2,22 @@ package api
 
 import (
 	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"io/ioutil"
 	"net"
 	"net/http"
 	"time"
 
 	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/lib"
 	"golang.org/x/crypto/acme/autocert"
 )
 
 // StartServer interprets info from config to start the server
-// if config.TLS == true it'll spin up an https server using LetsEncrypt
-// that should work just fine on the raw internet (ie not behind a proxy like nginx etc)
+// if config.TLS == true it'll spin up an https server, sourcing certificates
+// according to config.TLSMode. The default "autocert" mode uses LetsEncrypt,
+// which should work just fine on the raw internet (ie not behind a proxy like nginx etc)
 // it'll also redirect http traffic to it's https route counterpart if port 80 is open
 func StartServer(c *config.API, s *http.Server) error {
 	s.Addr = fmt.Sprintf(fmt.Sprintf(":%d", c.Port))
@@ -25,22 +29,26 @@ func StartServer(c *config.API, s *http.Server) error {
 		return s.ListenAndServe()
 	}
 
-	// log.Infoln("using https server for url root:", c.UrlRoot)
-	certCache := "/tmp/certs"
-	key, cert := "", ""
+	tlsCfg, err := TLSConfig(c)
+	if err != nil {
+		return err
+	}
+	s.TLSConfig = tlsCfg
 
-	// LetsEncrypt is good. Thanks LetsEncrypt.
-	certManager := autocert.Manager{
-		Prompt:     autocert.AcceptTOS,
-		HostPolicy: autocert.HostWhitelist(c.URLRoot),
-		Cache:      autocert.DirCache(certCache),
+	if c.TLSMode == "" || c.TLSMode == config.TLSModeAutocert {
+		// Attempt to boot a port 80 https redirect
+		go func() { HTTPSRedirect() }()
 	}
 
-	// Attempt to boot a port 80 https redirect
-	go func() { HTTPSRedirect() }()
+	// certificates are provided by TLSConfig
+	return s.ListenAndServeTLS("", "")
+}
 
-	s.TLSConfig = &tls.Config{
-		GetCertificate: certManager.GetCertificate,
+// TLSConfig builds https server configuration from api config. When
+// TLSClientCAFile is set, clients must present a certificate signed by one
+// of the CAs in the file
+func TLSConfig(c *config.API) (*tls.Config, error) {
+	cfg := &tls.Config{
 		// Causes servers to use Go's default ciphersuite preferences,
 		// which are tuned to avoid attacks. Does nothing on clients.
 		PreferServerCipherSuites: true,
@@ -51,7 +59,51 @@ func StartServer(c *config.API, s *http.Server) error {
 		},
 	}
 
-	return s.ListenAndServeTLS(cert, key)
+	switch c.TLSMode {
+	case "", config.TLSModeAutocert:
+		// log.Infoln("using https server for url root:", c.UrlRoot)
+		certCache := "/tmp/certs"
+
+		// LetsEncrypt is good. Thanks LetsEncrypt.
+		certManager := autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(c.URLRoot),
+			Cache:      autocert.DirCache(certCache),
+		}
+		cfg.GetCertificate = certManager.GetCertificate
+	case config.TLSModeCert, config.TLSModeSelfSigned:
+		if c.TLSCertFile == "" || c.TLSKeyFile == "" {
+			return nil, fmt.Errorf("tls mode %s requires both api.tlscertfile & api.tlskeyfile to be set", c.TLSMode)
+		}
+		if c.TLSMode == config.TLSModeSelfSigned && !lib.CertValid(c.TLSCertFile, c.TLSKeyFile) {
+			log.Infof("generating self-signed certificate: %s", c.TLSCertFile)
+			if err := lib.GenerateSelfSignedCert(c.TLSCertFile, c.TLSKeyFile, lib.SelfSignedCertHosts()); err != nil {
+				return nil, fmt.Errorf("error generating self-signed certificate: %s", err.Error())
+			}
+		}
+		cert, err := tls.LoadX509KeyPair(c.TLSCertFile, c.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading tls certificate: %s", err.Error())
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	default:
+		return nil, fmt.Errorf("unknown tls mode: %s", c.TLSMode)
+	}
+
+	if c.TLSClientCAFile != "" {
+		data, err := ioutil.ReadFile(c.TLSClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading client CA file: %s", err.Error())
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no PEM-encoded certificates found in client CA file: %s", c.TLSClientCAFile)
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return cfg, nil
 }
 
 // HTTPSRedirect listens on port 80, redirecting HTTP requests to https
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/qri-io/qri/config"
+)
+
+func TestTLSConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "qri_test_api_tls")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	bad := []*config.API{
+		{TLS: true, TLSMode: "sometimes"},
+		{TLS: true, TLSMode: config.TLSModeCert},
+		{TLS: true, TLSMode: config.TLSModeCert, TLSCertFile: filepath.Join(dir, "missing.pem"), TLSKeyFile: filepath.Join(dir, "missing.key")},
+	}
+	for i, c := range bad {
+		if _, err := TLSConfig(c); err == nil {
+			t.Errorf("case %d: expected error, got nil", i)
+		}
+	}
+
+	// self-signed certificates are generated if missing
+	cfg := &config.API{
+		TLS:         true,
+		TLSMode:     config.TLSModeSelfSigned,
+		TLSCertFile: filepath.Join(dir, "cert.pem"),
+		TLSKeyFile:  filepath.Join(dir, "key.pem"),
+	}
+	tlsCfg, err := TLSConfig(cfg)
+	if err != nil {
+		t.Fatalf("error creating self-signed tls config: %s", err.Error())
+	}
+	if len(tlsCfg.Certificates) != 1 {
+		t.Fatalf("expected 1 certificate, got: %d", len(tlsCfg.Certificates))
+	}
+
+	// the generated pair can be supplied as a user certificate
+	cfg.TLSMode = config.TLSModeCert
+	if _, err := TLSConfig(cfg); err != nil {
+		t.Errorf("error creating cert tls config: %s", err.Error())
+	}
+
+	caCert, caKey := newTestCA(t)
+	cfg.TLSClientCAFile = filepath.Join(dir, "ca.pem")
+	if err := ioutil.WriteFile(cfg.TLSClientCAFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caCert.Raw}), 0644); err != nil {
+		t.Fatal(err.Error())
+	}
+	if tlsCfg, err = TLSConfig(cfg); err != nil {
+		t.Fatalf("error creating mutual tls config: %s", err.Error())
+	}
+
+	s := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	s.TLS = tlsCfg
+	s.StartTLS()
+	defer s.Close()
+
+	serverCert, err := x509.ParseCertificate(tlsCfg.Certificates[0].Certificate[0])
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(serverCert)
+
+	cli := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+	if _, err := cli.Get(s.URL); err == nil {
+		t.Errorf("expected request without a client certificate to fail")
+	}
+
+	clientCert := newTestClientCert(t, caCert, caKey)
+	cli = &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: []tls.Certificate{clientCert}}}}
+	res, err := cli.Get(s.URL)
+	if err != nil {
+		t.Fatalf("error making request with a client certificate: %s", err.Error())
+	}
+	defer res.Body.Close()
+	body, _ := ioutil.ReadAll(res.Body)
+	if string(body) != "client" {
+		t.Errorf("expected server to see client certificate. got: %s", string(body))
+	}
+}
+
+func newTestCA(t *testing.T) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	return cert, key
+}
+
+func newTestClientCert(t *testing.T, ca *x509.Certificate, caKey *ecdsa.PrivateKey) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
@@ -0,0 +1,259 @@
+// Package catalog maps dataset metadata to & from the vocabularies data
+// catalogs use: DCAT, schema.org & Frictionless Data Packages. Documents
+// combine fields from a dataset's meta & structure, and are plain maps that
+// encode to JSON (or JSON-LD, for DCAT & schema.org)
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/render"
+)
+
+const (
+	// FormatDCAT is the W3C Data Catalog Vocabulary, as JSON-LD
+	FormatDCAT = "dcat"
+	// FormatSchemaOrg is the schema.org Dataset type, as JSON-LD
+	FormatSchemaOrg = "schemaorg"
+	// FormatFrictionless is a Frictionless Data Package descriptor
+	FormatFrictionless = "frictionless"
+)
+
+// Formats lists supported vocabularies
+var Formats = []string{FormatDCAT, FormatSchemaOrg, FormatFrictionless}
+
+// IsFormat checks if format is a supported vocabulary
+func IsFormat(format string) bool {
+	for _, f := range Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// Filename gives the conventional filename for a document in format
+func Filename(format string) string {
+	switch format {
+	case FormatFrictionless:
+		return "datapackage.json"
+	default:
+		return format + ".jsonld"
+	}
+}
+
+// Encode maps the metadata & structure of a dataset to a document in format
+func Encode(format string, ds *dataset.DatasetPod) (map[string]interface{}, error) {
+	switch format {
+	case FormatDCAT:
+		return encodeDCAT(ds), nil
+	case FormatSchemaOrg:
+		return encodeSchemaOrg(ds), nil
+	case FormatFrictionless:
+		return encodeFrictionless(ds), nil
+	}
+	return nil, fmt.Errorf("unsupported metadata format: '%s'. supported formats are: %s", format, strings.Join(Formats, ", "))
+}
+
+// Write writes a dataset to w as an indented document in format
+func Write(format string, w io.Writer, ds *dataset.DatasetPod) error {
+	doc, err := Encode(format, ds)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// Detect gives the format of a decoded document, returning "" for
+// documents in no supported format
+func Detect(doc map[string]interface{}) string {
+	switch doc["@type"] {
+	case "dcat:Dataset":
+		return FormatDCAT
+	case "Dataset":
+		return FormatSchemaOrg
+	}
+	if _, ok := doc["resources"].([]interface{}); ok {
+		return FormatFrictionless
+	}
+	return ""
+}
+
+// Decode maps a document in any supported format to a dataset. Relative
+// body paths are resolved against dir, the directory the document was read
+// from
+func Decode(data []byte, dir string) (*dataset.DatasetPod, error) {
+	doc := map[string]interface{}{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("reading metadata document: %s", err.Error())
+	}
+
+	var ds *dataset.DatasetPod
+	switch Detect(doc) {
+	case FormatDCAT:
+		ds = decodeDCAT(doc)
+	case FormatSchemaOrg:
+		ds = decodeSchemaOrg(doc)
+	case FormatFrictionless:
+		ds = decodeFrictionless(doc)
+	default:
+		return nil, fmt.Errorf("unrecognized metadata document. supported formats are: %s", strings.Join(Formats, ", "))
+	}
+
+	if ds.BodyPath != "" && !isURL(ds.BodyPath) && !filepath.IsAbs(ds.BodyPath) {
+		ds.BodyPath = filepath.Join(dir, ds.BodyPath)
+	}
+	return ds, nil
+}
+
+// mediaTypes maps qri data formats to media types
+var mediaTypes = map[string]string{
+	"csv":  "text/csv",
+	"json": "application/json",
+	"cbor": "application/cbor",
+	"xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// formatFromMediaType gives the data format for a media type or file format
+// name, returning "" for unknown types
+func formatFromMediaType(mt string) string {
+	mt = strings.ToLower(strings.TrimSpace(mt))
+	for format, t := range mediaTypes {
+		if mt == format || mt == t {
+			return format
+		}
+	}
+	return ""
+}
+
+// field is a column of a tabular dataset
+type field struct {
+	Name, Type string
+}
+
+// fields lists the columns described by a dataset schema
+func fields(ds *dataset.DatasetPod) (fs []field) {
+	if ds.Structure == nil || ds.Structure.Schema == nil {
+		return nil
+	}
+	for _, col := range render.SchemaColumns(ds.Structure.Schema, nil) {
+		fs = append(fs, field{Name: col.Title, Type: col.Type})
+	}
+	return
+}
+
+// schema builds a dataset schema for rows of columns
+func schema(fs []field) map[string]interface{} {
+	items := make([]interface{}, len(fs))
+	for i, f := range fs {
+		col := map[string]interface{}{"title": f.Name}
+		if f.Type != "" {
+			col["type"] = f.Type
+		}
+		items[i] = col
+	}
+	return map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"type":  "array",
+			"items": items,
+		},
+	}
+}
+
+// modified gives the time a dataset was committed, or "" if unknown
+func modified(ds *dataset.DatasetPod) string {
+	if ds.Commit == nil || ds.Commit.Timestamp.IsZero() {
+		return ""
+	}
+	return ds.Commit.Timestamp.UTC().Format(time.RFC3339)
+}
+
+// meta returns the meta of ds, adding one if it doesn't exist
+func meta(ds *dataset.DatasetPod) *dataset.Meta {
+	if ds.Meta == nil {
+		ds.Meta = &dataset.Meta{}
+	}
+	return ds.Meta
+}
+
+// set adds a value to a document, skipping empty values
+func set(doc map[string]interface{}, key string, val interface{}) {
+	switch v := val.(type) {
+	case string:
+		if v == "" {
+			return
+		}
+	case []string:
+		if len(v) == 0 {
+			return
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			return
+		}
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return
+		}
+	case int:
+		if v == 0 {
+			return
+		}
+	}
+	doc[key] = val
+}
+
+// str reads a string from a document
+func str(doc map[string]interface{}, key string) string {
+	s, _ := doc[key].(string)
+	return s
+}
+
+// strs reads a list of strings from a document, accepting a single string
+// or a comma-separated list
+func strs(doc map[string]interface{}, key string) (res []string) {
+	switch v := doc[key].(type) {
+	case string:
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				res = append(res, s)
+			}
+		}
+	case []interface{}:
+		for _, s := range v {
+			if s, ok := s.(string); ok {
+				res = append(res, s)
+			}
+		}
+	}
+	return
+}
+
+// objs reads a list of objects from a document, accepting a single object
+func objs(doc map[string]interface{}, key string) (res []map[string]interface{}) {
+	switch v := doc[key].(type) {
+	case map[string]interface{}:
+		res = append(res, v)
+	case []interface{}:
+		for _, o := range v {
+			if o, ok := o.(map[string]interface{}); ok {
+				res = append(res, o)
+			}
+		}
+	}
+	return
+}
+
+func isURL(path string) bool {
+	lowered := strings.ToLower(path)
+	return strings.HasPrefix(lowered, "http://") || strings.HasPrefix(lowered, "https://")
+}
@@ -0,0 +1,141 @@
+package catalog
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/qri-io/dataset"
+)
+
+func citiesPod() *dataset.DatasetPod {
+	return &dataset.DatasetPod{
+		Peername: "me",
+		Name:     "world_cities",
+		Commit:   &dataset.CommitPod{Timestamp: time.Date(2018, 7, 4, 12, 0, 0, 0, time.UTC)},
+		Meta: &dataset.Meta{
+			Title:        "World Cities",
+			Description:  "population & age of cities",
+			Keywords:     []string{"cities", "population"},
+			License:      &dataset.License{Type: "CC-BY-4.0", URL: "https://creativecommons.org/licenses/by/4.0/"},
+			Contributors: []*dataset.User{{Fullname: "Ada Lovelace", Email: "ada@example.com"}},
+			DownloadPath: "https://example.com/cities.csv",
+			Version:      "1.0",
+		},
+		Structure: &dataset.StructurePod{
+			Format:       "csv",
+			FormatConfig: map[string]interface{}{"headerRow": true},
+			Length:       155,
+			Schema: map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "array",
+					"items": []interface{}{
+						map[string]interface{}{"title": "city", "type": "string"},
+						map[string]interface{}{"title": "pop", "type": "integer"},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, format := range Formats {
+		buf := &bytes.Buffer{}
+		if err := Write(format, buf, citiesPod()); err != nil {
+			t.Fatalf("%s: writing: %s", format, err.Error())
+		}
+
+		doc := map[string]interface{}{}
+		if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatalf("%s: invalid json: %s", format, err.Error())
+		}
+		if got := Detect(doc); got != format {
+			t.Errorf("%s: detected format mismatch. got: '%s'", format, got)
+		}
+
+		ds, err := Decode(buf.Bytes(), "/data")
+		if err != nil {
+			t.Fatalf("%s: decoding: %s", format, err.Error())
+		}
+		md := ds.Meta
+		if md.Title != "World Cities" || md.Description != "population & age of cities" {
+			t.Errorf("%s: title & description mismatch. got: '%s', '%s'", format, md.Title, md.Description)
+		}
+		if len(md.Keywords) != 2 || md.Keywords[0] != "cities" {
+			t.Errorf("%s: keywords mismatch. got: %v", format, md.Keywords)
+		}
+		if md.License == nil || md.License.URL != "https://creativecommons.org/licenses/by/4.0/" {
+			t.Errorf("%s: expected license url to round trip", format)
+		}
+		if len(md.Contributors) != 1 || md.Contributors[0].Email != "ada@example.com" {
+			t.Errorf("%s: expected contributors to round trip", format)
+		}
+		if md.DownloadPath != "https://example.com/cities.csv" {
+			t.Errorf("%s: download path mismatch. got: '%s'", format, md.DownloadPath)
+		}
+		if ds.Structure == nil || ds.Structure.Format != "csv" {
+			t.Errorf("%s: expected csv format to round trip", format)
+		}
+	}
+}
+
+func TestEncodeFrictionless(t *testing.T) {
+	doc := encodeFrictionless(citiesPod())
+	if doc["name"] != "world-cities" {
+		t.Errorf("expected package name to be a valid frictionless name, got: %v", doc["name"])
+	}
+	res := doc["resources"].([]interface{})[0].(map[string]interface{})
+	fields := res["schema"].(map[string]interface{})["fields"].([]interface{})
+	if len(fields) != 2 {
+		t.Fatalf("expected 2 schema fields, got: %d", len(fields))
+	}
+	if f := fields[1].(map[string]interface{}); f["name"] != "pop" || f["type"] != "integer" {
+		t.Errorf("field mismatch. got: %v", f)
+	}
+}
+
+func TestDecodeFrictionless(t *testing.T) {
+	data := []byte(`{
+  "name": "gdp",
+  "title": "Country, Regional and World GDP",
+  "licenses": [{"name": "ODC-PDDL-1.0", "path": "http://opendatacommons.org/licenses/pddl/"}],
+  "resources": [{
+    "name": "gdp",
+    "path": "data/gdp.csv",
+    "schema": {
+      "fields": [
+        {"name": "Country Name", "type": "string"},
+        {"name": "Year", "type": "date"},
+        {"name": "Value", "type": "number"}
+      ]
+    }
+  }]
+}`)
+	ds, err := Decode(data, "/packages/gdp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ds.BodyPath != "/packages/gdp/data/gdp.csv" {
+		t.Errorf("expected relative body path to be resolved, got: %s", ds.BodyPath)
+	}
+	if ds.Structure == nil || ds.Structure.Format != "csv" || ds.Structure.FormatConfig["headerRow"] != true {
+		t.Fatalf("expected csv structure with a header row, got: %v", ds.Structure)
+	}
+	items := ds.Structure.Schema["items"].(map[string]interface{})["items"].([]interface{})
+	if len(items) != 3 {
+		t.Fatalf("expected 3 schema columns, got: %d", len(items))
+	}
+	if _, ok := items[1].(map[string]interface{})["type"]; ok {
+		t.Errorf("expected unsupported field types to be left untyped")
+	}
+	if ds.Meta.License == nil || ds.Meta.License.Type != "ODC-PDDL-1.0" {
+		t.Errorf("expected license to be read")
+	}
+
+	if _, err := Decode([]byte(`{"title":"not a package"}`), ""); err == nil {
+		t.Errorf("expected unrecognized documents to error")
+	}
+}
@@ -0,0 +1,101 @@
+package catalog
+
+import (
+	"github.com/qri-io/dataset"
+)
+
+// dcatContext maps the prefixes used in DCAT documents to their vocabularies
+var dcatContext = map[string]interface{}{
+	"dcat":  "http://www.w3.org/ns/dcat#",
+	"dct":   "http://purl.org/dc/terms/",
+	"foaf":  "http://xmlns.com/foaf/0.1/",
+	"owl":   "http://www.w3.org/2002/07/owl#",
+	"vcard": "http://www.w3.org/2006/vcard/ns#",
+}
+
+func encodeDCAT(ds *dataset.DatasetPod) map[string]interface{} {
+	doc := map[string]interface{}{
+		"@context": dcatContext,
+		"@type":    "dcat:Dataset",
+	}
+	set(doc, "dct:modified", modified(ds))
+
+	if md := ds.Meta; md != nil {
+		set(doc, "dct:title", md.Title)
+		set(doc, "dct:description", md.Description)
+		set(doc, "dct:identifier", md.Identifier)
+		set(doc, "dcat:keyword", md.Keywords)
+		set(doc, "dcat:theme", md.Theme)
+		set(doc, "dct:language", md.Language)
+		set(doc, "dct:accrualPeriodicity", md.AccrualPeriodicity)
+		set(doc, "owl:versionInfo", md.Version)
+		set(doc, "dcat:landingPage", md.HomePath)
+		if md.License != nil {
+			set(doc, "dct:license", md.License.URL)
+			set(doc, "dct:rights", md.License.Type)
+		}
+
+		var creators []interface{}
+		for _, c := range md.Contributors {
+			if c == nil {
+				continue
+			}
+			creator := map[string]interface{}{"@type": "foaf:Agent"}
+			set(creator, "foaf:name", c.Fullname)
+			set(creator, "foaf:mbox", c.Email)
+			creators = append(creators, creator)
+		}
+		set(doc, "dct:creator", creators)
+	}
+
+	dist := map[string]interface{}{"@type": "dcat:Distribution"}
+	if md := ds.Meta; md != nil {
+		set(dist, "dcat:downloadURL", md.DownloadPath)
+		set(dist, "dcat:accessURL", md.AccessPath)
+	}
+	if st := ds.Structure; st != nil {
+		set(dist, "dct:format", st.Format)
+		set(dist, "dcat:mediaType", mediaTypes[st.Format])
+		set(dist, "dcat:byteSize", st.Length)
+	}
+	if len(dist) > 1 {
+		doc["dcat:distribution"] = []interface{}{dist}
+	}
+	return doc
+}
+
+func decodeDCAT(doc map[string]interface{}) *dataset.DatasetPod {
+	ds := &dataset.DatasetPod{}
+	md := meta(ds)
+	md.Title = str(doc, "dct:title")
+	md.Description = str(doc, "dct:description")
+	md.Identifier = str(doc, "dct:identifier")
+	md.Keywords = strs(doc, "dcat:keyword")
+	md.Theme = strs(doc, "dcat:theme")
+	md.Language = strs(doc, "dct:language")
+	md.AccrualPeriodicity = str(doc, "dct:accrualPeriodicity")
+	md.Version = str(doc, "owl:versionInfo")
+	md.HomePath = str(doc, "dcat:landingPage")
+	if url, typ := str(doc, "dct:license"), str(doc, "dct:rights"); url != "" || typ != "" {
+		md.License = &dataset.License{Type: typ, URL: url}
+	}
+	for _, c := range objs(doc, "dct:creator") {
+		md.Contributors = append(md.Contributors, &dataset.User{Fullname: str(c, "foaf:name"), Email: str(c, "foaf:mbox")})
+	}
+
+	if dists := objs(doc, "dcat:distribution"); len(dists) > 0 {
+		dist := dists[0]
+		md.DownloadPath = str(dist, "dcat:downloadURL")
+		md.AccessPath = str(dist, "dcat:accessURL")
+		ds.BodyPath = md.DownloadPath
+
+		format := formatFromMediaType(str(dist, "dcat:mediaType"))
+		if format == "" {
+			format = formatFromMediaType(str(dist, "dct:format"))
+		}
+		if format != "" {
+			ds.Structure = &dataset.StructurePod{Format: format}
+		}
+	}
+	return ds
+}
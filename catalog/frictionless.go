@@ -0,0 +1,151 @@
+package catalog
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/qri-io/dataset"
+)
+
+// frictionlessTypes maps jsonschema types to Table Schema field types
+var frictionlessTypes = map[string]string{
+	"string":  "string",
+	"number":  "number",
+	"integer": "integer",
+	"boolean": "boolean",
+	"object":  "object",
+	"array":   "array",
+}
+
+func encodeFrictionless(ds *dataset.DatasetPod) map[string]interface{} {
+	// package & resource names are lowercase & can't contain underscores
+	name := strings.Replace(strings.ToLower(ds.Name), "_", "-", -1)
+	doc := map[string]interface{}{}
+	set(doc, "name", name)
+	set(doc, "created", modified(ds))
+
+	if md := ds.Meta; md != nil {
+		set(doc, "title", md.Title)
+		set(doc, "description", md.Description)
+		set(doc, "id", md.Identifier)
+		set(doc, "keywords", md.Keywords)
+		set(doc, "version", md.Version)
+		set(doc, "homepage", md.HomePath)
+		if md.License != nil {
+			lic := map[string]interface{}{}
+			set(lic, "name", md.License.Type)
+			set(lic, "path", md.License.URL)
+			doc["licenses"] = []interface{}{lic}
+		}
+
+		var contributors []interface{}
+		for _, c := range md.Contributors {
+			if c == nil {
+				continue
+			}
+			contributor := map[string]interface{}{"role": "author"}
+			set(contributor, "title", c.Fullname)
+			set(contributor, "email", c.Email)
+			contributors = append(contributors, contributor)
+		}
+		set(doc, "contributors", contributors)
+
+		if md.DownloadPath != "" {
+			doc["sources"] = []interface{}{map[string]interface{}{"title": "download", "path": md.DownloadPath}}
+		}
+	}
+
+	res := map[string]interface{}{"name": name}
+	if res["name"] == "" {
+		res["name"] = "body"
+	}
+	if st := ds.Structure; st != nil {
+		// qri export writes bodies alongside descriptors as data.[format]
+		set(res, "path", "data."+st.Format)
+		set(res, "format", st.Format)
+		set(res, "mediatype", mediaTypes[st.Format])
+		set(res, "bytes", st.Length)
+		if st.Format == "csv" {
+			res["profile"] = "tabular-data-resource"
+			if header, ok := st.FormatConfig["headerRow"].(bool); ok {
+				res["dialect"] = map[string]interface{}{"header": header}
+			}
+		}
+	}
+	if fs := fields(ds); len(fs) > 0 {
+		tsFields := make([]interface{}, len(fs))
+		for i, f := range fs {
+			tsf := map[string]interface{}{"name": f.Name}
+			set(tsf, "type", frictionlessTypes[f.Type])
+			tsFields[i] = tsf
+		}
+		res["schema"] = map[string]interface{}{"fields": tsFields}
+	}
+	doc["resources"] = []interface{}{res}
+	return doc
+}
+
+func decodeFrictionless(doc map[string]interface{}) *dataset.DatasetPod {
+	ds := &dataset.DatasetPod{}
+	md := meta(ds)
+	md.Title = str(doc, "title")
+	md.Description = str(doc, "description")
+	md.Identifier = str(doc, "id")
+	md.Keywords = strs(doc, "keywords")
+	md.Version = str(doc, "version")
+	md.HomePath = str(doc, "homepage")
+	if lics := objs(doc, "licenses"); len(lics) > 0 {
+		md.License = &dataset.License{Type: str(lics[0], "name"), URL: str(lics[0], "path")}
+	}
+	for _, c := range objs(doc, "contributors") {
+		md.Contributors = append(md.Contributors, &dataset.User{Fullname: str(c, "title"), Email: str(c, "email")})
+	}
+	if sources := objs(doc, "sources"); len(sources) > 0 {
+		md.DownloadPath = str(sources[0], "path")
+	}
+
+	// qri datasets have a single body, read from the first resource
+	resources := objs(doc, "resources")
+	if len(resources) == 0 {
+		return ds
+	}
+	res := resources[0]
+	ds.BodyPath = str(res, "path")
+	if paths := strs(res, "path"); ds.BodyPath == "" && len(paths) > 0 {
+		ds.BodyPath = paths[0]
+	}
+
+	format := formatFromMediaType(str(res, "mediatype"))
+	if format == "" {
+		format = formatFromMediaType(str(res, "format"))
+	}
+	if format == "" {
+		format = formatFromMediaType(strings.TrimPrefix(filepath.Ext(ds.BodyPath), "."))
+	}
+	if format == "" {
+		return ds
+	}
+	ds.Structure = &dataset.StructurePod{Format: format}
+
+	if format == "csv" {
+		// tabular resources have a header row unless their dialect says otherwise
+		header := true
+		if dialects := objs(res, "dialect"); len(dialects) > 0 {
+			if h, ok := dialects[0]["header"].(bool); ok {
+				header = h
+			}
+		}
+		ds.Structure.FormatConfig = map[string]interface{}{"headerRow": header}
+	}
+
+	if schemas := objs(res, "schema"); len(schemas) > 0 {
+		var fs []field
+		for _, f := range objs(schemas[0], "fields") {
+			fs = append(fs, field{Name: str(f, "name"), Type: frictionlessTypes[str(f, "type")]})
+		}
+		if len(fs) > 0 {
+			ds.Structure.Schema = schema(fs)
+		}
+	}
+	return ds
+}
@@ -0,0 +1,107 @@
+package catalog
+
+import (
+	"github.com/qri-io/dataset"
+)
+
+func encodeSchemaOrg(ds *dataset.DatasetPod) map[string]interface{} {
+	doc := map[string]interface{}{
+		"@context": "https://schema.org/",
+		"@type":    "Dataset",
+	}
+	set(doc, "dateModified", modified(ds))
+
+	if md := ds.Meta; md != nil {
+		set(doc, "name", md.Title)
+		set(doc, "description", md.Description)
+		set(doc, "identifier", md.Identifier)
+		set(doc, "keywords", md.Keywords)
+		set(doc, "about", md.Theme)
+		set(doc, "inLanguage", md.Language)
+		set(doc, "version", md.Version)
+		set(doc, "url", md.HomePath)
+		if md.License != nil {
+			set(doc, "license", md.License.URL)
+		}
+
+		var creators []interface{}
+		for _, c := range md.Contributors {
+			if c == nil {
+				continue
+			}
+			creator := map[string]interface{}{"@type": "Person"}
+			set(creator, "name", c.Fullname)
+			set(creator, "email", c.Email)
+			creators = append(creators, creator)
+		}
+		set(doc, "creator", creators)
+
+		var citations []interface{}
+		for _, c := range md.Citations {
+			if c == nil {
+				continue
+			}
+			citation := map[string]interface{}{"@type": "CreativeWork"}
+			set(citation, "name", c.Name)
+			set(citation, "url", c.URL)
+			citations = append(citations, citation)
+		}
+		set(doc, "citation", citations)
+	}
+	if _, ok := doc["name"]; !ok && ds.Name != "" {
+		doc["name"] = ds.Name
+	}
+
+	dist := map[string]interface{}{"@type": "DataDownload"}
+	if md := ds.Meta; md != nil {
+		set(dist, "contentUrl", md.DownloadPath)
+	}
+	if st := ds.Structure; st != nil {
+		set(dist, "encodingFormat", mediaTypes[st.Format])
+	}
+	if len(dist) > 1 {
+		doc["distribution"] = []interface{}{dist}
+	}
+
+	var vars []interface{}
+	for _, f := range fields(ds) {
+		vars = append(vars, map[string]interface{}{"@type": "PropertyValue", "name": f.Name})
+	}
+	set(doc, "variableMeasured", vars)
+	return doc
+}
+
+func decodeSchemaOrg(doc map[string]interface{}) *dataset.DatasetPod {
+	ds := &dataset.DatasetPod{}
+	md := meta(ds)
+	md.Title = str(doc, "name")
+	md.Description = str(doc, "description")
+	md.Identifier = str(doc, "identifier")
+	md.Keywords = strs(doc, "keywords")
+	md.Theme = strs(doc, "about")
+	md.Language = strs(doc, "inLanguage")
+	md.Version = str(doc, "version")
+	md.HomePath = str(doc, "url")
+
+	// licenses can be urls or CreativeWorks
+	if url := str(doc, "license"); url != "" {
+		md.License = &dataset.License{URL: url}
+	} else if lics := objs(doc, "license"); len(lics) > 0 {
+		md.License = &dataset.License{Type: str(lics[0], "name"), URL: str(lics[0], "url")}
+	}
+	for _, c := range objs(doc, "creator") {
+		md.Contributors = append(md.Contributors, &dataset.User{Fullname: str(c, "name"), Email: str(c, "email")})
+	}
+	for _, c := range objs(doc, "citation") {
+		md.Citations = append(md.Citations, &dataset.Citation{Name: str(c, "name"), URL: str(c, "url")})
+	}
+
+	if dists := objs(doc, "distribution"); len(dists) > 0 {
+		md.DownloadPath = str(dists[0], "contentUrl")
+		ds.BodyPath = md.DownloadPath
+		if format := formatFromMediaType(str(dists[0], "encodingFormat")); format != "" {
+			ds.Structure = &dataset.StructurePod{Format: format}
+		}
+	}
+	return ds
+}
@@ -0,0 +1,196 @@
+// Package chunk stores large dataset bodies as a sequence of
+// content-addressed chunks listed in a manifest. Chunk boundaries are
+// chosen by hashing entries, so an edit only changes the chunks around it:
+// saving a new version re-uses every other chunk, reading from an offset
+// skips straight to the chunk that holds it, and peers only fetch the
+// chunks they don't already have
+package chunk
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/qri-io/cafs"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+)
+
+// ManifestKind identifies chunk manifests
+const ManifestKind = "ch:0"
+
+// DefaultSize is the default target size of a chunk in bytes
+const DefaultSize = 1024 * 1024
+
+// manifestPrefix is the start of every encoded manifest, used to tell
+// manifests apart from bodies
+var manifestPrefix = []byte(`{"qri":"` + ManifestKind + `"`)
+
+// Manifest lists the chunks of a body in order. Each chunk is a complete
+// body in the dataset's format
+type Manifest struct {
+	Qri string `json:"qri"`
+	// Entries is the total number of entries in the body
+	Entries int `json:"entries"`
+	// Chunks lists chunks in body order
+	Chunks []Chunk `json:"chunks"`
+}
+
+// Chunk is a stored range of body entries
+type Chunk struct {
+	Path    string `json:"path"`
+	Entries int    `json:"entries"`
+	Length  int    `json:"length"`
+}
+
+// Seek finds the chunk holding the entry at offset, returning the chunk
+// index & the number of entries in the chunks before it
+func (m *Manifest) Seek(offset int) (index, skipped int) {
+	for i, c := range m.Chunks {
+		if skipped+c.Entries > offset {
+			return i, skipped
+		}
+		skipped += c.Entries
+	}
+	return len(m.Chunks), skipped
+}
+
+// IsManifest checks if r starts with an encoded manifest without consuming
+// any input
+func IsManifest(r *bufio.Reader) bool {
+	head, _ := r.Peek(len(manifestPrefix))
+	return bytes.Equal(head, manifestPrefix)
+}
+
+// ReadManifest decodes a manifest
+func ReadManifest(r io.Reader) (*Manifest, error) {
+	m := &Manifest{}
+	if err := json.NewDecoder(r).Decode(m); err != nil {
+		return nil, fmt.Errorf("reading chunk manifest: %s", err.Error())
+	}
+	if m.Qri != ManifestKind {
+		return nil, fmt.Errorf("invalid chunk manifest kind '%s'", m.Qri)
+	}
+	return m, nil
+}
+
+// Write splits a body into chunks of roughly size bytes, adding each chunk
+// to store as a file named name. Chunks that are already in the store are
+// added again, which is a no-op for content-addressed stores
+func Write(store cafs.Filestore, st *dataset.Structure, r io.Reader, name string, size int, pin bool) (*Manifest, error) {
+	if size <= 0 {
+		size = DefaultSize
+	}
+	rr, err := dsio.NewEntryReader(st, r)
+	if err != nil {
+		return nil, fmt.Errorf("error allocating data reader: %s", err.Error())
+	}
+
+	m := &Manifest{Qri: ManifestKind}
+	var (
+		buf     *dsio.EntryBuffer
+		entries int
+		length  int
+	)
+	flush := func() error {
+		if buf == nil {
+			return nil
+		}
+		if err := buf.Close(); err != nil {
+			return err
+		}
+		data := buf.Bytes()
+		key, err := store.Put(cafs.NewMemfileBytes(name, data), pin)
+		if err != nil {
+			return fmt.Errorf("adding chunk: %s", err.Error())
+		}
+		m.Chunks = append(m.Chunks, Chunk{Path: key.String(), Entries: entries, Length: len(data)})
+		buf, entries, length = nil, 0, 0
+		return nil
+	}
+
+	for {
+		ent, err := rr.ReadEntry()
+		if err != nil {
+			if err.Error() == "EOF" {
+				break
+			}
+			return nil, err
+		}
+
+		if buf == nil {
+			if buf, err = dsio.NewEntryBuffer(st); err != nil {
+				return nil, err
+			}
+		}
+		// entries are renumbered from the start of each chunk
+		ent.Index = entries
+		if err := buf.WriteEntry(ent); err != nil {
+			return nil, err
+		}
+
+		// boundaries depend only on entry content, not position
+		data, err := json.Marshal([]interface{}{ent.Key, ent.Value})
+		if err != nil {
+			return nil, err
+		}
+		entries++
+		length += len(data)
+		m.Entries++
+
+		if boundary(data, length, size) {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// boundary decides if a chunk ends after an entry. Chunks end after entries
+// whose hash falls below a threshold proportional to their size, which
+// places a boundary every size bytes on average no matter what came before,
+// so boundaries survive edits elsewhere in the body. Chunks are kept between
+// a quarter & four times the target size
+func boundary(entry []byte, length, size int) bool {
+	if length < size/4 {
+		return false
+	}
+	if length >= size*4 {
+		return true
+	}
+	h := fnv.New32a()
+	h.Write(entry)
+	return uint64(h.Sum32())*uint64(size) < uint64(len(entry))<<32
+}
+
+// Paths lists the paths of every chunk in a manifest
+func (m *Manifest) Paths() []string {
+	paths := make([]string, len(m.Chunks))
+	for i, c := range m.Chunks {
+		paths[i] = c.Path
+	}
+	return paths
+}
+
+// Missing lists the chunks of a manifest that aren't in store
+func Missing(store cafs.Filestore, m *Manifest) ([]string, error) {
+	var missing []string
+	for _, c := range m.Chunks {
+		has, err := store.Has(datastore.NewKey(c.Path))
+		if err != nil {
+			return nil, err
+		}
+		if !has {
+			missing = append(missing, c.Path)
+		}
+	}
+	return missing, nil
+}
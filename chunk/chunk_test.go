@@ -0,0 +1,194 @@
+package chunk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/qri-io/cafs"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/compress"
+)
+
+var jsonStructure = &dataset.Structure{Format: dataset.JSONDataFormat, Schema: dataset.BaseSchemaArray}
+
+func makeBody(t *testing.T, n int, edit int) []byte {
+	rows := make([]interface{}, n)
+	for i := range rows {
+		rows[i] = map[string]interface{}{"id": i, "city": fmt.Sprintf("city_%d", i)}
+	}
+	if edit >= 0 {
+		rows[edit] = map[string]interface{}{"id": edit, "city": "edited"}
+	}
+	data, err := json.Marshal(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestWrite(t *testing.T) {
+	store := cafs.NewMapstore()
+	m, err := Write(store, jsonStructure, bytes.NewReader(makeBody(t, 1000, -1)), "body.json", 1024, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Entries != 1000 {
+		t.Errorf("expected 1000 entries, got: %d", m.Entries)
+	}
+	if len(m.Chunks) < 4 {
+		t.Fatalf("expected body to be split into chunks, got: %d", len(m.Chunks))
+	}
+	total := 0
+	for _, c := range m.Chunks {
+		total += c.Entries
+	}
+	if total != m.Entries {
+		t.Errorf("chunk entries should sum to %d, got: %d", m.Entries, total)
+	}
+
+	missing, err := Missing(store, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(missing) != 0 {
+		t.Errorf("expected no missing chunks, got: %d", len(missing))
+	}
+
+	// editing one entry should only change the chunk that holds it
+	edited, err := Write(store, jsonStructure, bytes.NewReader(makeBody(t, 1000, 500)), "body.json", 1024, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := map[string]bool{}
+	for _, p := range m.Paths() {
+		prev[p] = true
+	}
+	changed := 0
+	for _, p := range edited.Paths() {
+		if !prev[p] {
+			changed++
+		}
+	}
+	if changed > 2 {
+		t.Errorf("expected an edit to change at most 2 chunks, changed %d of %d", changed, len(edited.Chunks))
+	}
+}
+
+func TestSeek(t *testing.T) {
+	m := &Manifest{Chunks: []Chunk{{Entries: 10}, {Entries: 5}, {Entries: 20}}}
+	cases := []struct {
+		offset, index, skipped int
+	}{
+		{0, 0, 0},
+		{9, 0, 0},
+		{10, 1, 10},
+		{16, 2, 15},
+		{40, 3, 35},
+	}
+	for i, c := range cases {
+		index, skipped := m.Seek(c.offset)
+		if index != c.index || skipped != c.skipped {
+			t.Errorf("case %d: expected (%d, %d), got (%d, %d)", i, c.index, c.skipped, index, skipped)
+		}
+	}
+}
+
+func TestStore(t *testing.T) {
+	ms := cafs.NewMapstore()
+	body := makeBody(t, 1000, -1)
+	ds := &dataset.Dataset{Structure: jsonStructure}
+
+	key, err := NewStore(ms, ds, "body.json", 1024).Put(cafs.NewMemfileBytes("body.json", body), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds.BodyPath = key.String()
+
+	if m, err := LoadManifest(ms, ds); err != nil || m == nil {
+		t.Fatalf("expected stored body to be a chunk manifest. error: %v", err)
+	}
+
+	f, err := LoadBody(ms, ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, expect := []interface{}{}, []interface{}{}
+	json.Unmarshal(data, &got)
+	json.Unmarshal(body, &expect)
+	if len(got) != len(expect) {
+		t.Fatalf("expected %d entries reading chunks back, got: %d", len(expect), len(got))
+	}
+
+	f, skipped, err := LoadBodyAt(ms, ds, 700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if skipped == 0 || skipped > 700 {
+		t.Errorf("expected to skip chunks before offset 700, skipped: %d", skipped)
+	}
+	if data, err = ioutil.ReadAll(f); err != nil {
+		t.Fatal(err)
+	}
+	got = []interface{}{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1000-skipped {
+		t.Errorf("expected %d entries from offset, got: %d", 1000-skipped, len(got))
+	}
+	if row, ok := got[0].(map[string]interface{}); !ok || row["id"] != float64(skipped) {
+		t.Errorf("expected first entry to be entry %d, got: %v", skipped, got[0])
+	}
+
+	// bodies that aren't chunked read as-is
+	key, err = ms.Put(cafs.NewMemfileBytes("body.json", body), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, skipped, err = LoadBodyAt(ms, &dataset.Dataset{BodyPath: key.String(), Structure: jsonStructure}, 700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ = ioutil.ReadAll(f); skipped != 0 || !bytes.Equal(data, body) {
+		t.Errorf("expected whole bodies to be read from the start")
+	}
+}
+
+func TestBodyStore(t *testing.T) {
+	ms := cafs.NewMapstore()
+	body := makeBody(t, 1000, -1)
+	st := *jsonStructure
+	ds := &dataset.Dataset{Structure: &st}
+
+	// a compressed, chunked body
+	compress.SetStructureFormat(ds, compress.Gzip)
+	store := NewStore(compress.NewStore(ms, compress.Gzip, "body.json"), ds, "body.json", 1024)
+	key, err := store.Put(cafs.NewMemfileBytes("body.json", body), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds.BodyPath = key.String()
+
+	f, err := NewBodyStore(ms, ds).Get(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := []interface{}{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("expected body store to read the whole body, got error: %s", err.Error())
+	}
+	if len(got) != 1000 {
+		t.Errorf("expected 1000 entries, got: %d", len(got))
+	}
+}
@@ -0,0 +1,174 @@
+package chunk
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/qri-io/cafs"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/qri/compress"
+)
+
+// LoadBody loads the body of a dataset from the store, reassembling chunked
+// bodies & decompressing compressed ones
+func LoadBody(store cafs.Filestore, ds *dataset.Dataset) (cafs.File, error) {
+	f, _, err := LoadBodyAt(store, ds, 0)
+	return f, err
+}
+
+// LoadBodyAt loads the body of a dataset starting from the chunk that holds
+// the entry at offset, returning the number of entries skipped. Bodies that
+// aren't chunked are read from the start
+func LoadBodyAt(store cafs.Filestore, ds *dataset.Dataset, offset int) (cafs.File, int, error) {
+	f, err := compress.LoadBody(store, ds)
+	if err != nil {
+		return nil, 0, err
+	}
+	br := bufio.NewReader(f)
+	if !IsManifest(br) {
+		return &readFile{File: f, r: br}, 0, nil
+	}
+
+	m, err := ReadManifest(br)
+	f.Close()
+	if err != nil {
+		return nil, 0, err
+	}
+	i, skipped := m.Seek(offset)
+	return NewReader(store, ds.Structure, f.FileName(), m.Chunks[i:], skipped), skipped, nil
+}
+
+// NewBodyStore wraps store so the body of ds reads back whole &
+// decompressed, for code that loads bodies with store.Get like
+// dsutil.WriteZipArchive. Other files pass through
+func NewBodyStore(store cafs.Filestore, ds *dataset.Dataset) cafs.Filestore {
+	return &bodyStore{Filestore: store, ds: ds}
+}
+
+type bodyStore struct {
+	cafs.Filestore
+	ds *dataset.Dataset
+}
+
+// Get implements the cafs.Filestore interface
+func (s *bodyStore) Get(key datastore.Key) (cafs.File, error) {
+	if s.ds.BodyPath != "" && key.Equal(datastore.NewKey(s.ds.BodyPath)) {
+		return LoadBody(s.Filestore, s.ds)
+	}
+	return s.Filestore.Get(key)
+}
+
+// LoadManifest loads the chunk manifest of a dataset body, returning nil if
+// the body isn't chunked
+func LoadManifest(store cafs.Filestore, ds *dataset.Dataset) (*Manifest, error) {
+	f, err := compress.LoadBody(store, ds)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	br := bufio.NewReader(f)
+	if !IsManifest(br) {
+		return nil, nil
+	}
+	return ReadManifest(br)
+}
+
+// NewReader returns a file that reads chunks back as a single body in the
+// format st describes, named name. Entries are indexed from start
+func NewReader(store cafs.Filestore, st *dataset.Structure, name string, chunks []Chunk, start int) cafs.File {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(copyChunks(store, st, pw, chunks, start))
+	}()
+	return cafs.NewMemfileReader(name, pr)
+}
+
+func copyChunks(store cafs.Filestore, st *dataset.Structure, w io.Writer, chunks []Chunk, index int) error {
+	// chunks are compressed the same way as their manifest
+	format, err := compress.StructureFormat(st)
+	if err != nil {
+		return err
+	}
+	ew, err := dsio.NewEntryWriter(st, w)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range chunks {
+		f, err := store.Get(datastore.NewKey(c.Path))
+		if err != nil {
+			return fmt.Errorf("loading chunk %s: %s", c.Path, err.Error())
+		}
+		if f, err = compress.Decompress(format, f); err != nil {
+			return err
+		}
+
+		rr, err := dsio.NewEntryReader(st, f)
+		if err != nil {
+			f.Close()
+			return err
+		}
+		for {
+			ent, err := rr.ReadEntry()
+			if err != nil {
+				if err.Error() == "EOF" {
+					break
+				}
+				f.Close()
+				return fmt.Errorf("reading chunk %s: %s", c.Path, err.Error())
+			}
+			ent.Index = index
+			index++
+			if err := ew.WriteEntry(ent); err != nil {
+				f.Close()
+				return err
+			}
+		}
+		f.Close()
+	}
+	return ew.Close()
+}
+
+// Fetch gets any chunks of a dataset body that aren't in the store from
+// peers, returning the number of chunks fetched. Stores that can't fetch
+// are left as-is
+func Fetch(store cafs.Filestore, ds *dataset.Dataset) (int, error) {
+	fetcher, ok := store.(cafs.Fetcher)
+	if !ok {
+		return 0, nil
+	}
+	m, err := LoadManifest(store, ds)
+	if err != nil || m == nil {
+		return 0, err
+	}
+	missing, err := Missing(store, m)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, path := range missing {
+		key := datastore.NewKey(path)
+		if _, err := fetcher.Fetch(cafs.SourceAny, key); err != nil {
+			return 0, fmt.Errorf("fetching chunk %s: %s", path, err.Error())
+		}
+		if pinner, ok := store.(cafs.Pinner); ok {
+			if err := pinner.Pin(key, true); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return len(missing), nil
+}
+
+// readFile is a cafs.File that reads from r
+type readFile struct {
+	cafs.File
+	r io.Reader
+}
+
+// Read implements the io.Reader interface
+func (f *readFile) Read(p []byte) (int, error) { return f.r.Read(p) }
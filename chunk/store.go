@@ -0,0 +1,105 @@
+package chunk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/qri-io/cafs"
+	"github.com/qri-io/dataset"
+)
+
+// Store wraps a cafs.Filestore to write a chunked dataset body. Files named
+// bodyName are split into chunks as they're added, and replaced with their
+// manifest. Everything else passes through, so a Store can be handed to dsfs
+// to save a dataset with a chunked body: checksums & stats are calculated
+// from the whole body before it's added
+type Store struct {
+	cafs.Filestore
+	ds       *dataset.Dataset
+	bodyName string
+	size     int
+}
+
+// NewStore wraps store, splitting files named bodyName into chunks of
+// roughly size bytes. The body is read using the structure ds has when the
+// body is added, so ds can be filled in after the store is created
+func NewStore(store cafs.Filestore, ds *dataset.Dataset, bodyName string, size int) *Store {
+	return &Store{Filestore: store, ds: ds, bodyName: bodyName, size: size}
+}
+
+// Put implements the cafs.Filestore interface, chunking the body file
+func (s *Store) Put(file cafs.File, pin bool) (datastore.Key, error) {
+	f, err := s.split(file, pin)
+	if err != nil {
+		return datastore.Key{}, err
+	}
+	return s.Filestore.Put(f, pin)
+}
+
+// NewAdder implements the cafs.Filestore interface, chunking the body file
+// as it's added
+func (s *Store) NewAdder(pin, wrap bool) (cafs.Adder, error) {
+	a, err := s.Filestore.NewAdder(pin, wrap)
+	if err != nil {
+		return nil, err
+	}
+	return &adder{Adder: a, store: s, pin: pin}, nil
+}
+
+// split replaces the body file with a manifest of its chunks
+func (s *Store) split(file cafs.File, pin bool) (cafs.File, error) {
+	if file.IsDirectory() {
+		return &dir{File: file, store: s, pin: pin}, nil
+	}
+	if file.FileName() != s.bodyName {
+		return file, nil
+	}
+	defer file.Close()
+	if s.ds.Structure == nil {
+		return nil, fmt.Errorf("a structure is required to chunk a dataset body")
+	}
+
+	m, err := Write(s.Filestore, s.ds.Structure, file, s.bodyName, s.size, pin)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return cafs.NewMemfileReader(s.bodyName, bytes.NewReader(data)), nil
+}
+
+// adder chunks the body file as it's added
+type adder struct {
+	cafs.Adder
+	store *Store
+	pin   bool
+}
+
+// AddFile implements the cafs.Adder interface
+func (a *adder) AddFile(f cafs.File) error {
+	f, err := a.store.split(f, a.pin)
+	if err != nil {
+		return err
+	}
+	return a.Adder.AddFile(f)
+}
+
+// dir chunks the body file if it's one of a directory's children
+type dir struct {
+	cafs.File
+	store *Store
+	pin   bool
+}
+
+// NextFile implements the cafs.File interface
+func (d *dir) NextFile() (cafs.File, error) {
+	f, err := d.File.NextFile()
+	if err != nil {
+		return f, err
+	}
+	return d.store.split(f, d.pin)
+}
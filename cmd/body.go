@@ -7,6 +7,8 @@ import (
 	"os"
 
 	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/qri/export"
 	"github.com/qri-io/qri/lib"
 	"github.com/qri-io/qri/repo"
 	"github.com/spf13/cobra"
@@ -27,7 +29,10 @@ func NewBodyCommand(f Factory, ioStreams IOStreams) *cobra.Command {
   $ qri body --offset 50 me/dataset_name
 
   save the body as csv to file
-  $ qri body -o new_file.csv -f csv me/dataset_name`,
+  $ qri body -o new_file.csv -f csv me/dataset_name
+
+  save the whole body as a parquet file
+  $ qri body --all -o new_file.parquet -f parquet me/dataset_name`,
 		Annotations: map[string]string{
 			"group": "dataset",
 		},
@@ -41,7 +46,7 @@ func NewBodyCommand(f Factory, ioStreams IOStreams) *cobra.Command {
 
 	cmd.Flags().StringVarP(&o.Output, "output", "o", "", "path to write to, default is stdout")
 	cmd.Flags().BoolVarP(&o.All, "all", "a", false, "read all dataset entries (overrides limit, offest)")
-	cmd.Flags().StringVarP(&o.Format, "format", "f", "json", "format to export. one of [json,csv,cbor]")
+	cmd.Flags().StringVarP(&o.Format, "format", "f", "json", "format to export. one of [json,csv,cbor,ndjson,parquet,xlsx,sqlite]")
 	cmd.Flags().IntVarP(&o.Limit, "limit", "l", 50, "max number of records to read")
 	cmd.Flags().IntVarP(&o.Offset, "offset", "s", 0, "number of records to skip")
 
@@ -98,6 +103,10 @@ func (o *BodyOptions) Run() error {
 	}
 
 	ds := res.Dataset
+	if export.IsFormat(o.Format) {
+		return o.export(res)
+	}
+
 	df, err := dataset.ParseDataFormatString(o.Format)
 	if err != nil {
 		return err
@@ -129,3 +138,42 @@ func (o *BodyOptions) Run() error {
 
 	return nil
 }
+
+// export streams body entries in one of the formats the export package
+// writes, reading only the requested page of entries unless All is set
+func (o *BodyOptions) export(ref *repo.DatasetRef) error {
+	if o.Format == export.FormatSQLite && o.Output == "" {
+		return fmt.Errorf("sqlite bodies must be written to a file, use --output")
+	}
+
+	return writeBody(o.Repo.Store(), ref.Path, func(r dsio.EntryReader) error {
+		if !o.All {
+			r = &dsio.PagedReader{Reader: r, Limit: o.Limit, Offset: o.Offset}
+		}
+
+		if o.Format == export.FormatSQLite {
+			db, err := export.OpenSQLite(o.Output)
+			if err != nil {
+				return err
+			}
+			if err = db.WriteTable(ref.Name, r); err != nil {
+				db.Close()
+				return err
+			}
+			return db.Close()
+		}
+
+		if o.Output == "" {
+			return export.Write(o.Format, o.Out, r, ref.Name)
+		}
+		f, err := os.Create(o.Output)
+		if err != nil {
+			return err
+		}
+		if err = export.Write(o.Format, f, r, ref.Name); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	})
+}
@@ -40,6 +40,7 @@ peers & swapping data.`,
 
 	cmd.Flags().IntVarP(&o.APIPort, "api-port", "", 0, "port to start api on")
 	cmd.Flags().IntVarP(&o.RPCPort, "rpc-port", "", 0, "port to start rpc listener on")
+	cmd.Flags().StringVarP(&o.RPCSocket, "rpc-socket", "", "", "path of a unix socket to serve JSON-RPC 2.0 on")
 	cmd.Flags().IntVarP(&o.WebappPort, "webapp-port", "", 0, "port to serve webapp on")
 	cmd.Flags().IntVarP(&o.DisconnectAfter, "disconnect-after", "", 0, "duration to keep connected in seconds, 0 means run indefinitely")
 
@@ -51,6 +52,7 @@ peers & swapping data.`,
 
 	cmd.Flags().BoolVarP(&o.Setup, "setup", "", false, "run setup if necessary, reading options from environment variables")
 	cmd.Flags().BoolVarP(&o.ReadOnly, "read-only", "", false, "run qri in read-only mode, limits the api endpoints")
+	cmd.Flags().BoolVarP(&o.RequireTokens, "require-tokens", "", false, "require api requests to carry a token made with qri token create")
 	cmd.Flags().StringVarP(&o.Registry, "registry", "", "", "specify registry to setup with. only works when --setup is true")
 
 	return cmd
@@ -62,6 +64,7 @@ type ConnectOptions struct {
 
 	APIPort         int
 	RPCPort         int
+	RPCSocket       string
 	WebappPort      int
 	DisconnectAfter int
 
@@ -70,9 +73,10 @@ type ConnectOptions struct {
 	DisableWebapp bool
 	DisableP2P    bool
 
-	Registry string
-	Setup    bool
-	ReadOnly bool
+	Registry      string
+	Setup         bool
+	ReadOnly      bool
+	RequireTokens bool
 
 	Repo   repo.Repo
 	Config *config.Config
@@ -116,6 +120,9 @@ func (o *ConnectOptions) Run() (err error) {
 		if o.RPCPort != 0 {
 			c.RPC.Port = o.RPCPort
 		}
+		if o.RPCSocket != "" {
+			c.RPC.Socket = o.RPCSocket
+		}
 		if o.WebappPort != 0 {
 			c.Webapp.Port = o.WebappPort
 		}
@@ -127,6 +134,9 @@ func (o *ConnectOptions) Run() (err error) {
 		if o.ReadOnly {
 			c.API.ReadOnly = true
 		}
+		if o.RequireTokens {
+			c.API.RequireTokens = true
+		}
 		if o.DisableP2P {
 			c.P2P.Enabled = false
 		}
@@ -11,9 +11,15 @@ import (
 
 	"github.com/ghodss/yaml"
 	"github.com/ipfs/go-datastore"
+	"github.com/qri-io/cafs"
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/dataset/dsfs"
-	"github.com/qri-io/dataset/dsutil"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/qri/actions"
+	"github.com/qri-io/qri/catalog"
+	"github.com/qri-io/qri/chunk"
+	"github.com/qri-io/qri/compress"
+	"github.com/qri-io/qri/export"
 	"github.com/qri-io/qri/lib"
 	"github.com/qri-io/qri/repo"
 	"github.com/qri-io/qri/repo/profile"
@@ -32,6 +38,18 @@ Export gets datasets out of qri. By default it exports only the dataset body.
 
 To export to a specific directory, use the --output flag.
 
+Bodies can be exported as json, csv, cbor, ndjson, parquet, xlsx or sqlite with the
+--body-format flag. SQLite exports write every dataset listed to a table in a single
+database file, datasets.sqlite, with column types taken from each dataset's schema.
+
+Metadata can also be exported in standard catalog vocabularies with --meta-format:
+dcat (W3C DCAT as JSON-LD), schemaorg (schema.org Dataset as JSON-LD) or
+frictionless (a Frictionless Data Package datapackage.json). Each maps the dataset's
+meta & structure to the vocabulary, and is written alongside dataset.yaml.
+
+To move a dataset to another repo, use --zip. Zip archives include every
+version of a dataset, and can be added to another repo with qri import.
+
 If you want an empty dataset that can be filled in with details to create a
 new dataset, use --blank.`,
 		Example: `  # export dataset
@@ -41,7 +59,16 @@ new dataset, use --blank.`,
   qri export --no-body me/annual_pop
 
   # export to a specific directory
-  qri export -o ~/new_directory me/annual_pop`,
+  qri export -o ~/new_directory me/annual_pop
+
+  # export DCAT metadata for a data catalog
+  qri export --meta-format dcat me/annual_pop
+
+  # export a dataset & its history as a zip archive
+  qri export --zip me/annual_pop
+
+  # export the bodies of two datasets to a sqlite database
+  qri export --body-format sqlite me/annual_pop me/annual_gdp`,
 		Annotations: map[string]string{
 			"group": "dataset",
 		},
@@ -56,10 +83,11 @@ new dataset, use --blank.`,
 	cmd.Flags().BoolVarP(&o.Blank, "blank", "", false, "export a blank dataset YAML file, overrides all other flags except output")
 	cmd.Flags().StringVarP(&o.Output, "output", "o", "", "path to write to, default is current directory")
 	cmd.Flags().StringVarP(&o.Format, "format", "f", "yaml", "format for all exported files, except for body. yaml is the default format. options: yaml, json")
-	cmd.Flags().StringVarP(&o.BodyFormat, "body-format", "", "", "format for dataset body. default is the original data format. options: json, csv, cbor")
+	cmd.Flags().StringVarP(&o.BodyFormat, "body-format", "", "", "format for dataset body. default is the original data format. options: json, csv, cbor, ndjson, parquet, xlsx, sqlite")
+	cmd.Flags().StringVarP(&o.MetaFormat, "meta-format", "", "", "also export metadata in a standard vocabulary. options: dcat, schemaorg, frictionless")
 	cmd.Flags().BoolVarP(&o.NoBody, "no-body", "b", false, "don't include dataset body in export")
 	cmd.Flags().BoolVarP(&o.PeerDir, "peer-dir", "d", false, "export to a peer name namespaced directory")
-	// cmd.Flags().BoolVarP(&o.Zipped, "zip", "z", false, "compress export as zip archive, export all parts of dataset, data in original format")
+	cmd.Flags().BoolVarP(&o.Zipped, "zip", "z", false, "export a zip archive of the dataset & its full history, which can be added to another repo with qri import")
 	// exportCmd.Flags().BoolVarP(&exportCmdVis, "vis-conf", "c", false, "export viz config file")
 
 	return cmd
@@ -69,13 +97,14 @@ new dataset, use --blank.`,
 type ExportOptions struct {
 	IOStreams
 
-	Ref        string
+	Refs       []string
 	PeerDir    bool
 	Zipped     bool
 	Blank      bool
 	Output     string
 	Format     string
 	BodyFormat string
+	MetaFormat string
 	NoBody     bool
 
 	UsingRPC        bool
@@ -86,9 +115,7 @@ type ExportOptions struct {
 
 // Complete adds any missing configuration that can only be added just before calling Run
 func (o *ExportOptions) Complete(f Factory, args []string) (err error) {
-	if len(args) > 0 {
-		o.Ref = args[0]
-	}
+	o.Refs = args
 	if f.RPC() != nil {
 		return usingRPCError("export")
 	}
@@ -104,10 +131,8 @@ func (o *ExportOptions) Complete(f Factory, args []string) (err error) {
 }
 
 // Run executes the Export command
-func (o *ExportOptions) Run() error {
+func (o *ExportOptions) Run() (err error) {
 	path := o.Output
-	format := o.Format
-	bodyFormat := o.BodyFormat
 
 	if o.Blank {
 		if path == "" {
@@ -123,11 +148,58 @@ func (o *ExportOptions) Run() error {
 		return fmt.Errorf("'%s' already exists", path)
 	}
 
-	if bodyFormat != "" && !(bodyFormat == "json" || bodyFormat == "csv" || bodyFormat == "cbor") {
-		return fmt.Errorf("%s is not an accepted data format, options are json, csv, and cbor", bodyFormat)
+	if bf := o.BodyFormat; bf != "" && !(bf == "json" || bf == "csv" || bf == "cbor" || export.IsFormat(bf)) {
+		return fmt.Errorf("%s is not an accepted data format, options are json, csv, cbor, %s", bf, strings.Join(export.Formats, ", "))
 	}
 
-	dsr, err := repo.ParseDatasetRef(o.Ref)
+	if mf := o.MetaFormat; mf != "" && !catalog.IsFormat(mf) {
+		return fmt.Errorf("%s is not an accepted metadata format, options are %s", mf, strings.Join(catalog.Formats, ", "))
+	}
+
+	refs := o.Refs
+	if len(refs) == 0 {
+		refs = []string{""}
+	}
+
+	// sqlite exports write every dataset to one database
+	var db *export.SQLite
+	if o.BodyFormat == export.FormatSQLite && !o.NoBody {
+		if path != "" {
+			if err = os.MkdirAll(path, os.ModePerm); err != nil {
+				return err
+			}
+		}
+		dbPath := filepath.Join(path, "datasets.sqlite")
+		if db, err = export.OpenSQLite(dbPath); err != nil {
+			return err
+		}
+		defer func() {
+			if cerr := db.Close(); err == nil {
+				err = cerr
+			}
+			if err == nil {
+				printSuccess(o.Out, "exported data to: %s", dbPath)
+			}
+		}()
+	}
+
+	tables := map[string]bool{}
+	for _, ref := range refs {
+		if err = o.exportRef(ref, db, tables); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportRef exports a single dataset. tables tracks the sqlite table names
+// already written to db
+func (o *ExportOptions) exportRef(refstr string, db *export.SQLite, tables map[string]bool) error {
+	path := o.Output
+	format := o.Format
+	bodyFormat := o.BodyFormat
+
+	dsr, err := repo.ParseDatasetRef(refstr)
 	if err != nil && err != repo.ErrEmptyRef {
 		return err
 	}
@@ -155,15 +227,23 @@ func (o *ExportOptions) Run() error {
 	path = filepath.Join(path, dsr.Name)
 
 	if o.Zipped {
+		if err = os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			return err
+		}
 		dst, err := os.Create(fmt.Sprintf("%s.zip", path))
 		if err != nil {
 			return err
 		}
 
-		if err = dsutil.WriteZipArchive(o.Repo.Store(), ds, dst); err != nil {
+		if err = (actions.Dataset{o.Repo}).WriteArchive(*res, dst); err != nil {
+			dst.Close()
+			return err
+		}
+		if err = dst.Close(); err != nil {
 			return err
 		}
-		return dst.Close()
+		printSuccess(o.Out, "exported archive to: %s.zip", path)
+		return nil
 	}
 
 	if path != "" {
@@ -172,7 +252,38 @@ func (o *ExportOptions) Run() error {
 		}
 	}
 
-	if !o.NoBody {
+	if !o.NoBody && db != nil {
+		// prefer dataset names for tables, falling back to peername_name
+		// when a name is taken
+		table := res.Name
+		if tables[table] {
+			table = res.Peername + "_" + res.Name
+		}
+		tables[table] = true
+
+		if err = writeBody(o.Repo.Store(), ds.Path().String(), func(r dsio.EntryReader) error {
+			return db.WriteTable(table, r)
+		}); err != nil {
+			return err
+		}
+		printSuccess(o.Out, "exported %s body to table: %s", res.AliasString(), table)
+	} else if !o.NoBody && export.IsFormat(bodyFormat) {
+		dataPath := filepath.Join(path, fmt.Sprintf("data.%s", bodyFormat))
+		dst, err := os.Create(dataPath)
+		if err != nil {
+			return err
+		}
+		if err = writeBody(o.Repo.Store(), ds.Path().String(), func(r dsio.EntryReader) error {
+			return export.Write(bodyFormat, dst, r, res.Name)
+		}); err != nil {
+			dst.Close()
+			return err
+		}
+		if err = dst.Close(); err != nil {
+			return err
+		}
+		printSuccess(o.Out, "exported data to: %s", dataPath)
+	} else if !o.NoBody {
 		if bodyFormat == "" {
 			bodyFormat = ds.Structure.Format.String()
 		}
@@ -214,15 +325,17 @@ func (o *ExportOptions) Run() error {
 
 	dsPath := filepath.Join(path, dsfs.PackageFileDataset.String())
 	var dsBytes []byte
+	// exported bodies are written decompressed
+	exported := compress.Decompressed(ds)
 
 	switch format {
 	case "json":
-		dsBytes, err = json.MarshalIndent(ds, "", "  ")
+		dsBytes, err = json.MarshalIndent(exported, "", "  ")
 		if err != nil {
 			return err
 		}
 	default:
-		dsBytes, err = yaml.Marshal(ds)
+		dsBytes, err = yaml.Marshal(exported)
 		if err != nil {
 			return err
 		}
@@ -250,9 +363,49 @@ func (o *ExportOptions) Run() error {
 
 	printSuccess(o.Out, "exported dataset.json to: %s", dsPath)
 
+	if o.MetaFormat != "" {
+		pod := ds.Encode()
+		pod.Peername = res.Peername
+		pod.Name = res.Name
+
+		metaPath := filepath.Join(path, catalog.Filename(o.MetaFormat))
+		dst, err := os.Create(metaPath)
+		if err != nil {
+			return err
+		}
+		if err = catalog.Write(o.MetaFormat, dst, pod); err != nil {
+			dst.Close()
+			return err
+		}
+		if err = dst.Close(); err != nil {
+			return err
+		}
+		printSuccess(o.Out, "exported %s metadata to: %s", o.MetaFormat, metaPath)
+	}
+
 	return nil
 }
 
+// writeBody streams the body of the dataset at path to write, one entry at
+// a time
+func writeBody(store cafs.Filestore, path string, write func(r dsio.EntryReader) error) error {
+	ds, err := dsfs.LoadDataset(store, datastore.NewKey(path))
+	if err != nil {
+		return err
+	}
+	file, err := chunk.LoadBody(store, ds)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	r, err := dsio.NewEntryReader(ds.Structure, file)
+	if err != nil {
+		return fmt.Errorf("error allocating data reader: %s", err)
+	}
+	return write(r)
+}
+
 const blankYamlDataset = `# This file defines a qri dataset. Change this file, save it, then from a terminal run:
 # $ qri add --file=dataset.yaml
 # For more info check out https://qri.io/docs
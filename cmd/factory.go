@@ -30,6 +30,9 @@ type Factory interface {
 	SearchRequests() (*lib.SearchRequests, error)
 	RenderRequests() (*lib.RenderRequests, error)
 	SelectionRequests() (*lib.SelectionRequests, error)
+	ScheduleRequests() (*lib.ScheduleRequests, error)
+	TokenRequests() (*lib.TokenRequests, error)
+	WebhookRequests() (*lib.WebhookRequests, error)
 }
 
 // PathFactory is a function that returns paths to qri & ipfs repos
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"path/filepath"
+
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/repo"
+	"github.com/spf13/cobra"
+)
+
+// NewImportCommand creates an import command
+func NewImportCommand(f Factory, ioStreams IOStreams) *cobra.Command {
+	o := &ImportOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Add a dataset from a zip archive",
+		Annotations: map[string]string{
+			"group": "dataset",
+		},
+		Long: `
+Import adds a dataset and its full version history from a zip archive created
+with ` + "`qri export --zip`" + `. Every file in the archive is checked against its
+hash as it's added, so the imported dataset has exactly the same paths it had
+in the repo it was exported from. The dataset reference stays the same,
+including the name of the peer that created the dataset.`,
+		Example: `  add a dataset exported to a zip archive:
+  $ qri import annual_pop.zip`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	return cmd
+}
+
+// ImportOptions encapsulates state for the import command
+type ImportOptions struct {
+	IOStreams
+
+	Paths []string
+
+	DatasetRequests *lib.DatasetRequests
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *ImportOptions) Complete(f Factory, args []string) (err error) {
+	o.Paths = args
+	o.DatasetRequests, err = f.DatasetRequests()
+	return
+}
+
+// Run imports each archive
+func (o *ImportOptions) Run() error {
+	for _, path := range o.Paths {
+		// relative paths would resolve against the working directory of a
+		// connected server
+		path, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+		p := &lib.ImportParams{ArchivePath: path}
+		res := repo.DatasetRef{}
+		if err := o.DatasetRequests.Import(p, &res); err != nil {
+			return err
+		}
+
+		printDatasetRefInfo(o.Out, 1, res)
+		printSuccess(o.Out, "imported dataset %s", res)
+	}
+	return nil
+}
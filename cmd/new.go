@@ -10,6 +10,7 @@ import (
 
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/dataset/dsutil"
+	"github.com/qri-io/qri/catalog"
 	"github.com/qri-io/qri/lib"
 	"github.com/qri-io/qri/repo"
 	"github.com/spf13/cobra"
@@ -46,6 +47,16 @@ qri currently supports three data formats:
 - JSON (Javascript Object Notation)
 - CBOR (Concise Binary Object Representation)
 
+Bodies in these formats are converted to a supported format when added:
+- XLSX (Excel workbooks, use --sheet to pick a sheet other than the first)
+- NDJSON (newline-delimited JSON, with a .ndjson or .jsonl extension)
+- Parquet
+
+The --file flag also accepts metadata in standard catalog vocabularies: a
+Frictionless Data Package (datapackage.json), or DCAT or schema.org JSON-LD.
+Metadata & structure are read from the descriptor, and the body from its first
+resource or distribution unless --body is given.
+
 Once you’ve added data, you can use the export command to pull the data out of
 qri, change the data outside of qri, and use the save command to record those
 changes to qri.`,
@@ -53,7 +64,13 @@ changes to qri.`,
   $ qri new --body data.csv me/annual_pop
 
 create a dataset with a dataset data file:
-  $ qri new --file dataset.yaml --body comics.csv me/comic_characters`,
+  $ qri new --file dataset.yaml --body comics.csv me/comic_characters
+
+create a dataset from the "2018" sheet of a workbook:
+  $ qri new --body sales.xlsx --sheet 2018 me/sales_2018
+
+create a dataset from a frictionless data package:
+  $ qri new --file datapackage.json me/gdp`,
 		Run: func(cmd *cobra.Command, args []string) {
 			ExitIfErr(o.ErrOut, o.Complete(f))
 			ExitIfErr(o.ErrOut, o.Run(args))
@@ -67,6 +84,11 @@ create a dataset with a dataset data file:
 	cmd.Flags().BoolVarP(&o.Private, "private", "", false, "make dataset private. WARNING: not yet implimented. Please refer to https://github.com/qri-io/qri/issues/291 for updates")
 	cmd.Flags().StringSliceVar(&o.Secrets, "secrets", nil, "transform secrets as comma separated key,value,key,value,... sequence")
 	cmd.Flags().BoolVarP(&o.Publish, "publish", "p", false, "publish this dataset to the registry")
+	cmd.Flags().StringVarP(&o.Sheet, "sheet", "", "", "name of the sheet to use when the body is an xlsx workbook. default is the first sheet")
+	cmd.Flags().StringVarP(&o.Validation, "validation", "", "", "validation policy for this dataset, one of: strict, warn, off")
+	cmd.Flags().StringVarP(&o.Compression, "compression", "", "", "compression for the stored body, one of: none, gzip, zstd")
+	cmd.Flags().IntVarP(&o.ChunkSize, "chunk-size", "", 0, "split the stored body into chunks of roughly this many bytes, -1 stores the body whole")
+	cmd.Flags().BoolVarP(&o.NoCache, "no-cache", "", false, "always execute the transform, instead of reusing a cached result for the same inputs")
 
 	return cmd
 }
@@ -82,6 +104,11 @@ type NewOptions struct {
 	Private        bool
 	Publish        bool
 	Secrets        []string
+	Validation     string
+	Sheet          string
+	Compression    string
+	ChunkSize      int
+	NoCache        bool
 
 	DatasetRequests *lib.DatasetRequests
 }
@@ -124,8 +151,25 @@ func (o *NewOptions) Run(args []string) error {
 			if err = dsutil.UnmarshalYAMLDatasetPod(data, dsp); err != nil {
 				return err
 			}
-		case ".json":
-			if err = json.NewDecoder(f).Decode(dsp); err != nil {
+		case ".json", ".jsonld":
+			data, err := ioutil.ReadAll(f)
+			if err != nil {
+				return err
+			}
+			// DCAT, schema.org & frictionless descriptors are mapped to a dataset
+			doc := map[string]interface{}{}
+			if err = json.Unmarshal(data, &doc); err != nil {
+				return err
+			}
+			if catalog.Detect(doc) != "" {
+				dir, err := filepath.Abs(filepath.Dir(o.File))
+				if err != nil {
+					return err
+				}
+				if dsp, err = catalog.Decode(data, dir); err != nil {
+					return err
+				}
+			} else if err = json.Unmarshal(data, dsp); err != nil {
 				return err
 			}
 		}
@@ -180,18 +224,23 @@ func (o *NewOptions) Run(args []string) error {
 	}
 
 	p := &lib.SaveParams{
-		Dataset: dsp,
-		Private: o.Private,
-		Publish: o.Publish,
+		Dataset:          dsp,
+		Private:          o.Private,
+		Publish:          o.Publish,
+		ValidationPolicy: o.Validation,
+		Sheet:            o.Sheet,
+		Compression:      o.Compression,
+		ChunkSize:        o.ChunkSize,
+		NoTransformCache: o.NoCache,
 	}
 
 	ref = repo.DatasetRef{}
 	if err = o.DatasetRequests.New(p, &ref); err != nil {
-		return err
+		return printSaveValidation(o.Out, err, ref, o.Validation)
 	}
 
-	if ref.Dataset.Structure.ErrCount > 0 {
-		printWarning(o.Out, fmt.Sprintf("this dataset has %d validation errors", ref.Dataset.Structure.ErrCount))
+	if err = printSaveValidation(o.Out, nil, ref, o.Validation); err != nil {
+		return err
 	}
 
 	ref.Peername = "me"
@@ -53,6 +53,7 @@ https://github.com/qri-io/qri/issues`,
 		NewDiffCommand(opt, ioStreams),
 		NewExportCommand(opt, ioStreams),
 		NewGetCommand(opt, ioStreams),
+		NewImportCommand(opt, ioStreams),
 		NewInfoCommand(opt, ioStreams),
 		NewListCommand(opt, ioStreams),
 		NewLogCommand(opt, ioStreams),
@@ -63,11 +64,14 @@ https://github.com/qri-io/qri/issues`,
 		NewRenameCommand(opt, ioStreams),
 		NewRenderCommand(opt, ioStreams),
 		NewSaveCommand(opt, ioStreams),
+		NewScheduleCommand(opt, ioStreams),
 		NewSearchCommand(opt, ioStreams),
 		NewSetupCommand(opt, ioStreams),
+		NewTokenCommand(opt, ioStreams),
 		NewUseCommand(opt, ioStreams),
 		NewValidateCommand(opt, ioStreams),
 		NewVersionCommand(opt, ioStreams),
+		NewWebhooksCommand(opt, ioStreams),
 	)
 
 	for _, sub := range cmd.Commands() {
@@ -264,3 +268,27 @@ func (o *QriOptions) RenderRequests() (*lib.RenderRequests, error) {
 	}
 	return lib.NewRenderRequests(o.repo, o.rpc), nil
 }
+
+// ScheduleRequests generates a lib.ScheduleRequests from internal state
+func (o *QriOptions) ScheduleRequests() (*lib.ScheduleRequests, error) {
+	if err := o.init(); err != nil {
+		return nil, err
+	}
+	return lib.NewScheduleRequests(o.repo, o.rpc), nil
+}
+
+// TokenRequests generates a lib.TokenRequests from internal state
+func (o *QriOptions) TokenRequests() (*lib.TokenRequests, error) {
+	if err := o.init(); err != nil {
+		return nil, err
+	}
+	return lib.NewTokenRequests(o.repo, o.rpc), nil
+}
+
+// WebhookRequests generates a lib.WebhookRequests from internal state
+func (o *QriOptions) WebhookRequests() (*lib.WebhookRequests, error) {
+	if err := o.init(); err != nil {
+		return nil, err
+	}
+	return lib.NewWebhookRequests(o.repo, o.rpc), nil
+}
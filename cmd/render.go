@@ -3,8 +3,11 @@ package cmd
 import (
 	"fmt"
 	"io/ioutil"
+	"path/filepath"
+	"strings"
 
 	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/render"
 	"github.com/qri-io/qri/repo"
 	"github.com/spf13/cobra"
 )
@@ -16,19 +19,41 @@ func NewRenderCommand(f Factory, ioStreams IOStreams) *cobra.Command {
 		Use:   "render",
 		Short: "Execute a template against a dataset",
 		Long: `
-You can use html templates, formatted in the go/html template style, 
-to render visualizations from your dataset. These visualizations can be charts, 
-graphs, or just display your dataset in a different format.
+You can use templates to render visualizations from your dataset. These
+visualizations can be charts, graphs, or just display your dataset in a
+different format.
+
+Templates can be written in one of a few formats, set with the ` + "`--format`" + `
+flag: go html templates ("html", the default), go text templates ("text"),
+markdown ("markdown"), which is rendered to html after executing as a go
+text template, or mustache ("mustache"). If no format is given, it's
+inferred from the template file extension. All formats can use helper
+functions for number formatting, tables and charts over the dataset body:
+
+  {{ formatNumber .Structure.Entries }}
+  {{ table .Body }}
+  {{ chart "bar" .Body 1 }}
 
 Use the ` + "`--output`" + ` flag to save the rendered html to a file.
 
 Use the ` + "`--template`" + ` flag to use a custom template. If no template is
-provided, Qri will render the dataset with a default template.`,
+provided, Qri will render the dataset with a default template.
+
+Use the ` + "`--site`" + ` flag to render every dataset in your repo to a directory
+of static html pages, including the history of each dataset, an index page
+and a search.json manifest. Site pages only use relative links, making them
+easy to host anywhere.`,
 		Example: `  render a dataset called me/schools:
   $ qri render -o=schools.html me/schools
 
   render a dataset with a custom template:
-  $ qri render --template=template.html me/schools`,
+  $ qri render --template=template.html me/schools
+
+  render a markdown data report:
+  $ qri render --template=report.md -o=report.html me/schools
+
+  render a static site of all datasets to the "catalog" directory:
+  $ qri render --site -o=catalog`,
 		Annotations: map[string]string{
 			"group": "dataset",
 		},
@@ -41,7 +66,9 @@ provided, Qri will render the dataset with a default template.`,
 	}
 
 	cmd.Flags().StringVarP(&o.Template, "template", "t", "", "path to template file")
+	cmd.Flags().StringVarP(&o.Format, "format", "f", "", "template format. one of: html, text, markdown, mustache")
 	cmd.Flags().StringVarP(&o.Output, "output", "o", "", "path to write output file")
+	cmd.Flags().BoolVarP(&o.Site, "site", "", false, "render all datasets to a static site in the output directory")
 	cmd.Flags().BoolVarP(&o.All, "all", "a", false, "read all dataset entries (overrides limit, offest)")
 	cmd.Flags().IntVarP(&o.Limit, "limit", "l", 50, "max number of records to read")
 	cmd.Flags().IntVarP(&o.Offset, "offset", "s", 0, "number of records to skip")
@@ -55,7 +82,9 @@ type RenderOptions struct {
 
 	Ref      string
 	Template string
+	Format   string
 	Output   string
+	Site     bool
 	All      bool
 	Limit    int
 	Offset   int
@@ -76,22 +105,30 @@ func (o *RenderOptions) Complete(f Factory, args []string) (err error) {
 func (o *RenderOptions) Run() (err error) {
 	var template []byte
 
+	if o.Site {
+		return o.RunSite()
+	}
+
 	ref, err := repo.ParseDatasetRef(o.Ref)
 	if err != nil && err != repo.ErrEmptyRef {
 		return err
 	}
 
+	format := o.Format
 	if o.Template != "" {
 		template, err = ioutil.ReadFile(o.Template)
 		if err != nil {
 			return err
 		}
+		if format == "" {
+			format = templateFormat(o.Template)
+		}
 	}
 
 	p := &lib.RenderParams{
 		Ref:            ref,
 		Template:       template,
-		TemplateFormat: "html",
+		TemplateFormat: format,
 		All:            o.All,
 		Limit:          o.Limit,
 		Offset:         o.Offset,
@@ -112,3 +149,55 @@ func (o *RenderOptions) Run() (err error) {
 	}
 	return nil
 }
+
+// RunSite renders all datasets to a static site
+func (o *RenderOptions) RunSite() (err error) {
+	if o.Output == "" {
+		return lib.NewError(lib.ErrBadArgs, "please provide an output directory with --output when rendering a site")
+	}
+
+	// resolve the output directory here, rpc calls are run from the server's
+	// working directory
+	dir, err := filepath.Abs(o.Output)
+	if err != nil {
+		return err
+	}
+
+	p := &lib.SiteParams{
+		Dir:            dir,
+		TemplateFormat: o.Format,
+		All:            o.All,
+		Limit:          o.Limit,
+		Offset:         o.Offset,
+	}
+	if o.Template != "" {
+		if p.Template, err = ioutil.ReadFile(o.Template); err != nil {
+			return err
+		}
+		if p.TemplateFormat == "" {
+			p.TemplateFormat = templateFormat(o.Template)
+		}
+	}
+
+	res := []string{}
+	if err = o.RenderRequests.RenderSite(p, &res); err != nil {
+		return err
+	}
+
+	printSuccess(o.Out, "rendered %d files to %s", len(res), dir)
+	return nil
+}
+
+// templateFormat infers a template format from a template filename extension
+func templateFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return render.FormatMarkdown
+	case ".mustache", ".mst":
+		return render.FormatMustache
+	case ".txt", ".tmpl":
+		return render.FormatText
+	default:
+		return render.FormatHTML
+	}
+}
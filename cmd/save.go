@@ -2,7 +2,7 @@ package cmd
 
 import (
 	"encoding/json"
-	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -11,6 +11,7 @@ import (
 	"github.com/qri-io/dataset/dsutil"
 	"github.com/qri-io/qri/lib"
 	"github.com/qri-io/qri/repo"
+	"github.com/qri-io/qri/validation"
 	"github.com/spf13/cobra"
 	"io/ioutil"
 )
@@ -36,12 +37,40 @@ When you make an update and save a dataset that you originally added from a diff
 peer, the dataset gets renamed from ` + "`peers_name/dataset_name`" + ` to ` + "`my_name/dataset_name`" + `.
 
 The ` + "`--message`" + `" and ` + "`--title`" + ` flags allow you to add a commit message and title 
-to the save.`,
+to the save.
+
+The ` + "`--validation`" + ` flag sets how save treats a body that doesn't match the dataset
+schema: "strict" refuses to save, "warn" saves with a warning, "off" saves silently.
+Set defaults with ` + "`qri config set repo.validation strict`" + `, or per dataset with
+` + "`repo.datasetValidation`" + `.
+
+The ` + "`--compression`" + ` flag stores the body compressed with gzip or zstd. Reading the
+body decompresses it transparently. Set defaults with
+` + "`qri config set repo.compression zstd`" + `, or per dataset with ` + "`repo.datasetCompression`" + `.
+
+The ` + "`--refresh`" + ` flag re-fetches the body from the url it was last downloaded
+from. Saving a body from a url records its ETag & Last-Modified headers, and refresh
+sends them back as a conditional request, skipping the save entirely if the server
+reports the body hasn't changed.
+
+The ` + "`--chunk-size`" + ` flag splits very large bodies into chunks of roughly that
+many bytes. Saving a small edit re-uses every chunk the edit doesn't touch, and
+peers only fetch chunks they don't have. Set defaults with
+` + "`qri config set repo.chunkSize 4194304`" + `, or per dataset with ` + "`repo.datasetChunkSize`" + `.
+
+When a save changes the dataset schema, save lists each change & whether it's
+backward-compatible. Removing or moving columns, narrowing or changing column types,
+and newly required columns are breaking changes. Set 
+` + "`qri config set repo.blockBreakingSchemaChanges true`" + ` to refuse breaking changes
+unless ` + "`--allow-breaking`" + ` is passed.`,
 		Example: `  # save updated data to dataset annual_pop:
   qri --body /path/to/data.csv me/annual_pop
 
   # save updated dataset (no data) to annual_pop:
-  qri --file /path/to/dataset.yaml me/annual_pop`,
+  qri --file /path/to/dataset.yaml me/annual_pop
+
+  # re-fetch the body of annual_pop from the url it was downloaded from:
+  qri save --refresh me/annual_pop`,
 		Annotations: map[string]string{
 			"group": "dataset",
 		},
@@ -63,6 +92,13 @@ to the save.`,
 	// cmd.Flags().BoolVarP(&o.ShowValidation, "show-validation", "s", false, "display a list of validation errors upon adding")
 	cmd.Flags().StringSliceVar(&o.Secrets, "secrets", nil, "transform secrets as comma separated key,value,key,value,... sequence")
 	cmd.Flags().BoolVarP(&o.Publish, "publish", "p", false, "publish this dataset to the registry")
+	cmd.Flags().StringVarP(&o.Sheet, "sheet", "", "", "name of the sheet to use when the body is an xlsx workbook. default is the first sheet")
+	cmd.Flags().StringVarP(&o.Validation, "validation", "", "", "validation policy for this save, one of: strict, warn, off")
+	cmd.Flags().StringVarP(&o.Compression, "compression", "", "", "compression for the stored body, one of: none, gzip, zstd")
+	cmd.Flags().IntVarP(&o.ChunkSize, "chunk-size", "", 0, "split the stored body into chunks of roughly this many bytes, -1 stores the body whole")
+	cmd.Flags().BoolVarP(&o.AllowBreaking, "allow-breaking", "", false, "save schema changes that break compatibility with the previous version")
+	cmd.Flags().BoolVarP(&o.Refresh, "refresh", "", false, "re-fetch the body from the url it was downloaded from, skipping the save if it hasn't changed")
+	cmd.Flags().BoolVarP(&o.NoCache, "no-cache", "", false, "always execute the transform, instead of reusing a cached result for the same inputs")
 
 	return cmd
 }
@@ -81,6 +117,13 @@ type SaveOptions struct {
 	ShowValidation bool
 	Publish        bool
 	Secrets        []string
+	Validation     string
+	Sheet          string
+	Compression    string
+	ChunkSize      int
+	AllowBreaking  bool
+	Refresh        bool
+	NoCache        bool
 
 	DatasetRequests *lib.DatasetRequests
 }
@@ -100,7 +143,7 @@ func (o *SaveOptions) Validate() error {
 	if o.Ref == "" {
 		return lib.NewError(lib.ErrBadArgs, "please provide the peername and dataset name you would like to update, in the format of `peername/dataset_name`\nsee `qri save --help` for more info")
 	}
-	if o.FilePath == "" && o.BodyPath == "" {
+	if o.FilePath == "" && o.BodyPath == "" && !o.Refresh {
 		return lib.NewError(lib.ErrBadArgs, "please an updated/changed dataset file (--file) or body file (--body), or both\nsee `qri save --help` for more info")
 	}
 	return nil
@@ -167,19 +210,88 @@ continue?`, true) {
 	}
 
 	p := &lib.SaveParams{
-		Dataset: dsp,
-		Private: false,
-		Publish: o.Publish,
+		Dataset:          dsp,
+		Private:          false,
+		Publish:          o.Publish,
+		ValidationPolicy: o.Validation,
+		Sheet:            o.Sheet,
+		Compression:      o.Compression,
+		ChunkSize:        o.ChunkSize,
+		Refresh:          o.Refresh,
+		NoTransformCache: o.NoCache,
+
+		AllowBreakingSchemaChanges: o.AllowBreaking,
+	}
+
+	// grab the previous version to compare schemas with, failing to load it
+	// isn't an error, save will report the problem
+	prev := &repo.DatasetRef{}
+	if err := o.DatasetRequests.Get(&ref, prev); err != nil {
+		prev = nil
 	}
 
 	res := &repo.DatasetRef{}
 	if err = o.DatasetRequests.Save(p, res); err != nil {
-		return err
+		// errors lose their identity over rpc, compare messages
+		if err.Error() == repo.ErrBodyNotModified.Error() {
+			printInfo(o.Out, "body at %s hasn't changed, nothing to save", bodyURL(dsp, prev))
+			return nil
+		}
+		if serr, ok := err.(validation.SchemaChangeError); ok {
+			printSchemaChanges(o.Out, serr.Changes)
+			printInfo(o.Out, "use --allow-breaking to save anyway")
+			return err
+		}
+		return printSaveValidation(o.Out, err, *res, o.Validation)
 	}
 
 	printSuccess(o.Out, "dataset saved: %s", res)
-	if res.Dataset.Structure.ErrCount > 0 {
-		printWarning(o.Out, fmt.Sprintf("this dataset has %d validation errors", res.Dataset.Structure.ErrCount))
+	if prev != nil {
+		if changes, err := schemaChanges(prev, res); err == nil {
+			printSchemaChanges(o.Out, changes)
+		}
+	}
+	return printSaveValidation(o.Out, nil, *res, o.Validation)
+}
+
+// bodyURL is the url a refreshed body is fetched from
+func bodyURL(dsp *dataset.DatasetPod, prev *repo.DatasetRef) string {
+	if dsp.BodyPath == "" && prev != nil && prev.Dataset != nil && prev.Dataset.Meta != nil {
+		return prev.Dataset.Meta.DownloadPath
+	}
+	return dsp.BodyPath
+}
+
+// schemaChanges compares the schemas of two versions of a dataset
+func schemaChanges(prev, next *repo.DatasetRef) ([]validation.SchemaChange, error) {
+	if prev.Dataset == nil || next.Dataset == nil {
+		return nil, nil
+	}
+	pds, err := prev.DecodeDataset()
+	if err != nil {
+		return nil, err
+	}
+	nds, err := next.DecodeDataset()
+	if err != nil {
+		return nil, err
+	}
+	if pds.Structure == nil || nds.Structure == nil {
+		return nil, nil
+	}
+	return validation.CompareSchemas(pds.Structure.Schema, nds.Structure.Schema)
+}
+
+// printSchemaChanges lists schema changes, marking breaking changes
+func printSchemaChanges(w io.Writer, changes []validation.SchemaChange) {
+	if len(changes) == 0 {
+		return
+	}
+	printInfo(w, "schema changes:")
+	for _, c := range changes {
+		if c.Breaking {
+			printWarning(w, "  %s (breaking)", c)
+			continue
+		}
+		printInfo(w, "  %s", c)
 	}
-	return nil
 }
@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/fatih/color"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/repo"
+	"github.com/spf13/cobra"
+)
+
+// NewScheduleCommand creates a new `qri schedule` cobra command for managing
+// recurring dataset updates
+func NewScheduleCommand(f Factory, ioStreams IOStreams) *cobra.Command {
+	o := &ScheduleOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "schedule",
+		Short: "Schedule recurring dataset updates",
+		Long: `
+Schedule keeps datasets up to date by re-fetching a dataset body from a url, or
+re-running a dataset's transform on a recurring basis. Scheduled updates only
+run while ` + "`qri connect`" + ` is running. If an update doesn't change anything
+no new version is saved.
+
+Schedules are written in a cron-like syntax with five fields:
+minute, hour, day of month, month, day of week. The shorthands @yearly,
+@monthly, @weekly, @daily, @hourly and "@every <duration>" are also accepted.
+
+When no --body url is given, updates re-fetch from the url the dataset body
+was originally downloaded from, falling back to the dataset's transform.`,
+		Example: `  # update a dataset every day at 6am:
+  qri schedule add --spec "0 6 * * *" me/annual_pop
+
+  # re-fetch a dataset body from a url every 12 hours:
+  qri schedule add --spec "@every 12h" --body https://example.com/data.csv me/annual_pop
+
+  # list scheduled updates:
+  qri schedule list
+
+  # stop updating a dataset:
+  qri schedule rm me/annual_pop`,
+		Annotations: map[string]string{
+			"group": "dataset",
+		},
+	}
+
+	add := &cobra.Command{
+		Use:   "add",
+		Short: "Schedule recurring updates for a dataset",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Add()
+		},
+	}
+
+	list := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List scheduled dataset updates",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.List()
+		},
+	}
+
+	remove := &cobra.Command{
+		Use:     "rm",
+		Aliases: []string{"remove"},
+		Short:   "Stop scheduled updates for a dataset",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Remove()
+		},
+	}
+
+	add.Flags().StringVarP(&o.Spec, "spec", "", "@daily", "cron-like schedule specification")
+	add.Flags().StringVarP(&o.BodyPath, "body", "", "", "url to re-fetch the dataset body from")
+
+	list.Flags().IntVarP(&o.Limit, "limit", "l", 25, "limit max number of schedules to show")
+	list.Flags().IntVarP(&o.Offset, "offset", "s", 0, "number of schedules to skip during listing")
+
+	cmd.AddCommand(add, list, remove)
+	return cmd
+}
+
+// ScheduleOptions encapsulates state for the schedule command
+type ScheduleOptions struct {
+	IOStreams
+
+	Ref      string
+	Spec     string
+	BodyPath string
+	Limit    int
+	Offset   int
+
+	ScheduleRequests *lib.ScheduleRequests
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *ScheduleOptions) Complete(f Factory, args []string) (err error) {
+	if len(args) > 0 {
+		o.Ref = args[0]
+	}
+	o.ScheduleRequests, err = f.ScheduleRequests()
+	return
+}
+
+// Add schedules updates for a dataset
+func (o *ScheduleOptions) Add() error {
+	ref, err := repo.ParseDatasetRef(o.Ref)
+	if err != nil {
+		return lib.NewError(lib.ErrBadArgs, "please provide a dataset reference in the form peername/dataset_name")
+	}
+
+	p := &lib.ScheduleParams{
+		Ref:      ref,
+		Spec:     o.Spec,
+		BodyPath: o.BodyPath,
+	}
+	res := &repo.Schedule{}
+	if err = o.ScheduleRequests.Add(p, res); err != nil {
+		return err
+	}
+
+	printSuccess(o.Out, "scheduled updates for %s: %s", res.Ref.AliasString(), res.Spec)
+	return nil
+}
+
+// List shows scheduled updates
+func (o *ScheduleOptions) List() error {
+	p := &lib.ListParams{
+		Limit:  o.Limit,
+		Offset: o.Offset,
+	}
+	res := []*repo.Schedule{}
+	if err := o.ScheduleRequests.List(p, &res); err != nil {
+		return err
+	}
+
+	if len(res) == 0 {
+		printInfo(o.Out, "no scheduled updates")
+		return nil
+	}
+
+	for i, sch := range res {
+		printSchedule(o.Out, i+o.Offset+1, sch)
+	}
+	return nil
+}
+
+// Remove stops scheduled updates for a dataset
+func (o *ScheduleOptions) Remove() error {
+	ref, err := repo.ParseDatasetRef(o.Ref)
+	if err != nil {
+		return lib.NewError(lib.ErrBadArgs, "please provide a dataset reference in the form peername/dataset_name")
+	}
+
+	done := false
+	if err = o.ScheduleRequests.Remove(&ref, &done); err != nil {
+		return err
+	}
+
+	printSuccess(o.Out, "removed schedule for %s", ref.AliasString())
+	return nil
+}
+
+func printSchedule(w io.Writer, i int, sch *repo.Schedule) {
+	white := color.New(color.FgWhite).SprintFunc()
+	cyan := color.New(color.FgCyan).SprintFunc()
+	blue := color.New(color.FgBlue).SprintFunc()
+
+	fmt.Fprintf(w, "%s  %s\n", cyan(i), white(sch.Ref.AliasString()))
+	fmt.Fprintf(w, "    spec: %s\n", sch.Spec)
+	if sch.BodyPath != "" {
+		fmt.Fprintf(w, "    body: %s\n", blue(sch.BodyPath))
+	}
+	if !sch.LastRun.IsZero() {
+		fmt.Fprintf(w, "    last run: %s\n", sch.LastRun.Format("Mon, 02 Jan 2006 15:04"))
+	}
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScheduleRun(t *testing.T) {
+	streams, in, out, errs := NewTestIOStreams()
+	setNoColor(true)
+
+	f, err := NewTestFactory(nil)
+	if err != nil {
+		t.Fatalf("error creating new test factory: %s", err)
+	}
+
+	opt := &ScheduleOptions{IOStreams: streams, Spec: "@daily"}
+	if err := opt.Complete(f, []string{"me/movies"}); err != nil {
+		t.Fatalf("error completing: %s", err)
+	}
+	if opt.ScheduleRequests == nil {
+		t.Fatalf("expected ScheduleRequests to be set")
+	}
+
+	if err := opt.Add(); err != nil {
+		t.Fatalf("error adding schedule: %s", err)
+	}
+	if expect := "scheduled updates for peer/movies: @daily"; !strings.Contains(out.String(), expect) {
+		t.Errorf("expected output to contain '%s', got: '%s'", expect, out.String())
+	}
+	ioReset(in, out, errs)
+
+	opt.Limit = 25
+	if err := opt.List(); err != nil {
+		t.Fatalf("error listing schedules: %s", err)
+	}
+	if !strings.Contains(out.String(), "peer/movies") {
+		t.Errorf("expected list output to contain scheduled dataset, got: '%s'", out.String())
+	}
+	ioReset(in, out, errs)
+
+	if err := opt.Remove(); err != nil {
+		t.Fatalf("error removing schedule: %s", err)
+	}
+	ioReset(in, out, errs)
+
+	if err := opt.List(); err != nil {
+		t.Fatalf("error listing schedules: %s", err)
+	}
+	if !strings.Contains(out.String(), "no scheduled updates") {
+		t.Errorf("expected empty list output, got: '%s'", out.String())
+	}
+
+	opt.Spec = "every other tuesday"
+	if err := opt.Add(); err == nil {
+		t.Errorf("expected invalid spec to error")
+	}
+}
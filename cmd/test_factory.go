@@ -113,3 +113,18 @@ func (t TestFactory) SearchRequests() (*lib.SearchRequests, error) {
 func (t TestFactory) RenderRequests() (*lib.RenderRequests, error) {
 	return lib.NewRenderRequests(t.repo, t.rpc), nil
 }
+
+// ScheduleRequests generates a lib.ScheduleRequests from internal state
+func (t TestFactory) ScheduleRequests() (*lib.ScheduleRequests, error) {
+	return lib.NewScheduleRequests(t.repo, t.rpc), nil
+}
+
+// TokenRequests generates a lib.TokenRequests from internal state
+func (t TestFactory) TokenRequests() (*lib.TokenRequests, error) {
+	return lib.NewTokenRequests(t.repo, t.rpc), nil
+}
+
+// WebhookRequests generates a lib.WebhookRequests from internal state
+func (t TestFactory) WebhookRequests() (*lib.WebhookRequests, error) {
+	return lib.NewWebhookRequests(t.repo, t.rpc), nil
+}
@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/repo"
+	"github.com/spf13/cobra"
+)
+
+// NewTokenCommand creates a new `qri token` cobra command for managing
+// api access tokens
+func NewTokenCommand(f Factory, ioStreams IOStreams) *cobra.Command {
+	o := &TokenOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "token",
+		Short: "Manage access tokens for the qri api",
+		Long: `
+Token creates & revokes tokens that grant access to the JSON api started by
+` + "`qri connect`" + `. Tokens are only checked when the api.requiretokens config
+value is true. Clients present a token in an Authorization header:
+
+  Authorization: Bearer <token>
+
+Each token has a scope:
+- read: view datasets, profiles & peers
+- write: read, plus create & update datasets
+- admin: write, plus delete datasets, edit the profile & connect to peers
+
+Tokens can be limited to a list of dataset patterns, like "me/*" or
+"me/annual_pop". Only a hash of each token is stored, so a token is only
+shown once, when it's created.
+
+Instead of creating a secret, --peer grants access to a peer this node knows
+about. Peers authenticate by signing requests with their profile key, sending
+an Authorization header of the form:
+
+  Authorization: QriSignature id=<profileID>,ts=<unix seconds>,key=<base64 public key>,sig=<base64 signature>
+
+where sig signs the request method, path & query, timestamp and the hex sha256
+hash of the request body, each separated by a newline. Requests signed by this
+node's own profile always have admin scope. Signatures older than five minutes
+or used more than once are rejected.`,
+		Example: `  # create a token that can update any of your datasets:
+  qri token create --scope write --datasets me/*
+
+  # create a read-only token:
+  qri token create --scope read
+
+  # let a peer update your datasets by signing requests:
+  qri token create --scope write --datasets me/* --peer some_peer
+
+  # list tokens:
+  qri token list
+
+  # revoke a token:
+  qri token rm 3f2a9c0d41b7e865`,
+		Annotations: map[string]string{
+			"group": "network",
+		},
+	}
+
+	create := &cobra.Command{
+		Use:   "create",
+		Short: "Create an api token",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Create()
+		},
+	}
+
+	list := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List api tokens",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.List()
+		},
+	}
+
+	remove := &cobra.Command{
+		Use:     "rm",
+		Aliases: []string{"remove"},
+		Short:   "Revoke an api token",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Remove()
+		},
+	}
+
+	create.Flags().StringVarP(&o.Scope, "scope", "", "read", "token scope, one of read, write, admin")
+	create.Flags().StringSliceVarP(&o.Datasets, "datasets", "", nil, "limit the token to datasets matching patterns, eg: me/*")
+	create.Flags().StringVarP(&o.Peer, "peer", "", "", "grant access to a peername or profile ID that signs requests")
+
+	list.Flags().IntVarP(&o.Limit, "limit", "l", 25, "limit max number of tokens to show")
+	list.Flags().IntVarP(&o.Offset, "offset", "s", 0, "number of tokens to skip during listing")
+
+	cmd.AddCommand(create, list, remove)
+	return cmd
+}
+
+// TokenOptions encapsulates state for the token command
+type TokenOptions struct {
+	IOStreams
+
+	ID       string
+	Scope    string
+	Datasets []string
+	Peer     string
+	Limit    int
+	Offset   int
+
+	TokenRequests *lib.TokenRequests
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *TokenOptions) Complete(f Factory, args []string) (err error) {
+	if len(args) > 0 {
+		o.ID = args[0]
+	}
+	o.TokenRequests, err = f.TokenRequests()
+	return
+}
+
+// Create makes a new token, printing its secret
+func (o *TokenOptions) Create() error {
+	p := &lib.TokenParams{
+		Scope:    o.Scope,
+		Datasets: o.Datasets,
+		Peer:     o.Peer,
+	}
+	res := &lib.TokenResult{}
+	if err := o.TokenRequests.Create(p, res); err != nil {
+		return err
+	}
+
+	if res.Token.ProfileID != "" {
+		printSuccess(o.Out, "granted %s access to peer %s", res.Token.Scope, o.Peer)
+		return nil
+	}
+
+	printSuccess(o.Out, "created %s token %s", res.Token.Scope, res.Token.ID)
+	printInfo(o.Out, "store this token somewhere safe, it won't be shown again:")
+	fmt.Fprintf(o.Out, "\n  %s\n\n", res.Secret)
+	return nil
+}
+
+// List shows api tokens
+func (o *TokenOptions) List() error {
+	p := &lib.ListParams{
+		Limit:  o.Limit,
+		Offset: o.Offset,
+	}
+	res := []*repo.Token{}
+	if err := o.TokenRequests.List(p, &res); err != nil {
+		return err
+	}
+
+	if len(res) == 0 {
+		printInfo(o.Out, "no api tokens")
+		return nil
+	}
+
+	for i, tok := range res {
+		printToken(o.Out, i+o.Offset+1, tok)
+	}
+	return nil
+}
+
+// Remove revokes an api token
+func (o *TokenOptions) Remove() error {
+	done := false
+	if err := o.TokenRequests.Delete(&o.ID, &done); err != nil {
+		return err
+	}
+
+	printSuccess(o.Out, "revoked token %s", o.ID)
+	return nil
+}
+
+func printToken(w io.Writer, i int, tok *repo.Token) {
+	white := color.New(color.FgWhite).SprintFunc()
+	cyan := color.New(color.FgCyan).SprintFunc()
+
+	fmt.Fprintf(w, "%s  %s\n", cyan(i), white(tok.ID))
+	fmt.Fprintf(w, "    scope: %s\n", tok.Scope)
+	if tok.ProfileID != "" {
+		fmt.Fprintf(w, "    peer: %s\n", tok.ProfileID)
+	}
+	if len(tok.Datasets) > 0 {
+		fmt.Fprintf(w, "    datasets: %s\n", strings.Join(tok.Datasets, ", "))
+	}
+	fmt.Fprintf(w, "    created: %s\n", tok.Created.Format("Mon, 02 Jan 2006 15:04"))
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTokenRun(t *testing.T) {
+	streams, in, out, errs := NewTestIOStreams()
+	setNoColor(true)
+
+	f, err := NewTestFactory(nil)
+	if err != nil {
+		t.Fatalf("error creating new test factory: %s", err)
+	}
+
+	opt := &TokenOptions{IOStreams: streams, Scope: "write", Datasets: []string{"me/*"}}
+	if err := opt.Complete(f, []string{}); err != nil {
+		t.Fatalf("error completing: %s", err)
+	}
+	if opt.TokenRequests == nil {
+		t.Fatalf("expected TokenRequests to be set")
+	}
+
+	if err := opt.Create(); err != nil {
+		t.Fatalf("error creating token: %s", err)
+	}
+	if expect := "created write token"; !strings.Contains(out.String(), expect) {
+		t.Errorf("expected output to contain '%s', got: '%s'", expect, out.String())
+	}
+	ioReset(in, out, errs)
+
+	opt.Limit = 25
+	if err := opt.List(); err != nil {
+		t.Fatalf("error listing tokens: %s", err)
+	}
+	if !strings.Contains(out.String(), "datasets: peer/*") {
+		t.Errorf("expected list output to contain token datasets, got: '%s'", out.String())
+	}
+	lines := strings.Fields(out.String())
+	if len(lines) < 2 {
+		t.Fatalf("expected list output to contain a token id, got: '%s'", out.String())
+	}
+	opt.ID = lines[1]
+	ioReset(in, out, errs)
+
+	if err := opt.Remove(); err != nil {
+		t.Fatalf("error removing token: %s", err)
+	}
+	ioReset(in, out, errs)
+
+	if err := opt.List(); err != nil {
+		t.Fatalf("error listing tokens: %s", err)
+	}
+	if !strings.Contains(out.String(), "no api tokens") {
+		t.Errorf("expected empty list output, got: '%s'", out.String())
+	}
+
+	opt.Scope = "root"
+	if err := opt.Create(); err == nil {
+		t.Errorf("expected invalid scope to error")
+	}
+}
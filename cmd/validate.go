@@ -1,13 +1,15 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 
-	"github.com/qri-io/jsonschema"
 	"github.com/qri-io/qri/lib"
 	"github.com/qri-io/qri/repo"
+	"github.com/qri-io/qri/validation"
 	"github.com/spf13/cobra"
 )
 
@@ -51,6 +53,13 @@ will affect a dataset before saving changes to a dataset.
 You can get the current schema of a dataset by running the ` + "`qri get structure.schema`" + `
 command.
 
+Validation reads the body one entry at a time, so it works on bodies of any
+size. Each error is reported with the row & column it was found in, the
+offending value, and the schema rule it breaks, followed by a summary of
+error counts by rule. Only the first --max-errors errors are listed, but
+all errors are counted in the summary. Use --format to print errors as json
+or csv instead.
+
 Note: --body and --schema flags will override the dataset if both flags are provided.`,
 		Example: `  # show errors in an existing dataset:
   qri validate b5/comics
@@ -59,7 +68,10 @@ Note: --body and --schema flags will override the dataset if both flags are prov
   qri validate --body new_data.csv me/annual_pop
 
   # validate data against a new schema
-  qri validate --body data.csv --schema schema.json`,
+  qri validate --body data.csv --schema schema.json
+
+  # write all errors to a csv file
+  qri validate --format csv --max-errors -1 me/annual_pop > errors.csv`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := o.Complete(f, args); err != nil {
 				return err
@@ -72,6 +84,8 @@ Note: --body and --schema flags will override the dataset if both flags are prov
 	// cmd.Flags().StringVarP(&o.URL, "url", "u", "", "url to file to initialize from")
 	cmd.Flags().StringVarP(&o.Filepath, "body", "b", "", "data file to initialize from")
 	cmd.Flags().StringVarP(&o.SchemaFilepath, "schema", "", "", "json schema file to use for validation")
+	cmd.Flags().StringVarP(&o.Format, "format", "f", "", "output format. one of: json, csv. default is a human-readable list")
+	cmd.Flags().IntVarP(&o.MaxErrors, "max-errors", "", validation.DefaultMaxErrors, "max number of errors to list, -1 lists all errors")
 
 	return cmd
 }
@@ -84,6 +98,8 @@ type ValidateOptions struct {
 	Filepath       string
 	SchemaFilepath string
 	URL            string
+	Format         string
+	MaxErrors      int
 	// validateDsPassive        bool
 
 	DatasetRequests *lib.DatasetRequests
@@ -99,6 +115,10 @@ func (o *ValidateOptions) Complete(f Factory, args []string) (err error) {
 		o.Ref = args[0]
 	}
 
+	if o.Format != "" && o.Format != "json" && o.Format != "csv" {
+		return lib.NewError(lib.ErrBadArgs, fmt.Sprintf("invalid format '%s'. format must be one of: json, csv", o.Format))
+	}
+
 	o.DatasetRequests, err = f.DatasetRequests()
 	return
 }
@@ -132,6 +152,7 @@ func (o *ValidateOptions) Run() (err error) {
 		// TODO: restore
 		// URL:          addDsURL,
 		DataFilename: filepath.Base(o.Filepath),
+		MaxErrors:    o.MaxErrors,
 	}
 
 	// this is because passing nil to interfaces is bad
@@ -143,17 +164,67 @@ func (o *ValidateOptions) Run() (err error) {
 		p.Schema = schemaFile
 	}
 
-	res := []jsonschema.ValError{}
-	if err = o.DatasetRequests.Validate(p, &res); err != nil {
+	res := &validation.Report{}
+	if err = o.DatasetRequests.Validate(p, res); err != nil {
+		return err
+	}
+
+	switch o.Format {
+	case "json":
+		data, err := json.MarshalIndent(res, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = o.Out.Write(append(data, '\n'))
 		return err
+	case "csv":
+		return res.WriteCSV(o.Out)
 	}
-	if len(res) == 0 {
+
+	if res.ErrCount == 0 {
 		printSuccess(o.Out, "✔ All good!")
 		return
 	}
 
-	for i, err := range res {
-		fmt.Fprintf(o.Out, "%d: %s\n", i, err.Error())
+	printValidationReport(o.Out, res)
+	return nil
+}
+
+// printValidationReport writes a human-readable list of validation errors,
+// followed by a summary of errors by rule
+func printValidationReport(w io.Writer, res *validation.Report) {
+	for i, e := range res.Errors {
+		fmt.Fprintf(w, "%d: %s\n", i, e.Error())
+	}
+	if res.Truncated {
+		printInfo(w, "showing %d of %d errors", len(res.Errors), res.ErrCount)
+	}
+
+	fmt.Fprintln(w, "")
+	printWarning(w, "%d errors in %d entries", res.ErrCount, res.Entries)
+	for _, rule := range res.Rules() {
+		fmt.Fprintf(w, "  %s: %d\n", rule, res.Summary[rule])
+	}
+}
+
+// printSaveValidation reports validation results for a save. errors from a
+// validation policy rejecting the body are printed in full, otherwise a
+// warning is shown for datasets with errors unless the policy is "off"
+func printSaveValidation(w io.Writer, err error, ref repo.DatasetRef, policy string) error {
+	if err != nil {
+		if perr, ok := err.(validation.PolicyError); ok && perr.Report != nil {
+			printValidationReport(w, perr.Report)
+			fmt.Fprintln(w, "")
+		}
+		return err
+	}
+
+	if ref.Dataset == nil || ref.Dataset.Structure == nil || ref.Dataset.Structure.ErrCount == 0 {
+		return nil
+	}
+	if p, perr := lib.ValidationPolicy(policy, ref.Peername, ref.Name); perr == nil && p == validation.PolicyOff {
+		return nil
 	}
+	printWarning(w, fmt.Sprintf("this dataset has %d validation errors", ref.Dataset.Structure.ErrCount))
 	return nil
 }
@@ -140,19 +140,22 @@ func TestValidateRun(t *testing.T) {
 	}
 }
 
-var movieOutput = `0: /4/1: "" type should be integer
-1: /199/1: "" type should be integer
-2: /206/1: "" type should be integer
-3: /1510/1: "" type should be integer
-4: /3604/1: "" type should be integer
-5: /3815/1: "" type should be integer
-6: /3834/1: "" type should be integer
-7: /4299/1: "" type should be integer
-8: /4392/1: "" type should be integer
-9: /4397/1: "" type should be integer
-10: /4517/1: "" type should be integer
-11: /4609/1: "" type should be integer
-12: /4690/1: "" type should be integer
-13: /4948/1: "" type should be integer
-14: /4989/1: "" type should be integer
+var movieOutput = `0: row 4, column duration: type should be integer (rule: type, value: "")
+1: row 199, column duration: type should be integer (rule: type, value: "")
+2: row 206, column duration: type should be integer (rule: type, value: "")
+3: row 1510, column duration: type should be integer (rule: type, value: "")
+4: row 3604, column duration: type should be integer (rule: type, value: "")
+5: row 3815, column duration: type should be integer (rule: type, value: "")
+6: row 3834, column duration: type should be integer (rule: type, value: "")
+7: row 4299, column duration: type should be integer (rule: type, value: "")
+8: row 4392, column duration: type should be integer (rule: type, value: "")
+9: row 4397, column duration: type should be integer (rule: type, value: "")
+10: row 4517, column duration: type should be integer (rule: type, value: "")
+11: row 4609, column duration: type should be integer (rule: type, value: "")
+12: row 4690, column duration: type should be integer (rule: type, value: "")
+13: row 4948, column duration: type should be integer (rule: type, value: "")
+14: row 4989, column duration: type should be integer (rule: type, value: "")
+
+15 errors in 5042 entries
+  type: 15
 `
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/fatih/color"
+	"github.com/qri-io/qri/lib"
+	"github.com/qri-io/qri/repo"
+	"github.com/spf13/cobra"
+)
+
+// NewWebhooksCommand creates a new `qri webhooks` cobra command for
+// inspecting webhook deliveries
+func NewWebhooksCommand(f Factory, ioStreams IOStreams) *cobra.Command {
+	o := &WebhooksOptions{IOStreams: ioStreams}
+	cmd := &cobra.Command{
+		Use:   "webhooks",
+		Short: "Inspect webhook deliveries",
+		Long: `
+Webhooks POST repo events, like a dataset getting a new version, to urls listed
+in the webhooks.hooks section of your config. Webhooks are sent while qri is
+connected with ` + "`qri connect`" + `. Events logged while qri isn't connected
+are sent the next time ` + "`qri connect`" + ` starts. The first time webhooks run
+only new events are sent. Each webhook can set:
+- url: the address events are sent to
+- events: event types to send, eg: ds_created. all events are sent if empty
+- datasets: only send events for datasets matching a pattern, eg: me/*
+- secret: a shared secret used to sign events
+
+Events are sent as JSON, with the event type in an X-Qri-Event header & a
+delivery ID in an X-Qri-Delivery header. When a secret is set, an
+X-Qri-Signature header carries "sha256=" followed by the hex HMAC-SHA256 of the
+request body, keyed with the secret. Failed deliveries are retried with
+backoff.
+
+Log shows the outcome of recent deliveries.`,
+		Example: `  # show recent webhook deliveries:
+  qri webhooks log`,
+		Annotations: map[string]string{
+			"group": "network",
+		},
+	}
+
+	logCmd := &cobra.Command{
+		Use:   "log",
+		Short: "Show webhook deliveries",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(f, args); err != nil {
+				return err
+			}
+			return o.Log()
+		},
+	}
+
+	logCmd.Flags().IntVarP(&o.Limit, "limit", "l", 25, "limit max number of deliveries to show")
+	logCmd.Flags().IntVarP(&o.Offset, "offset", "s", 0, "number of deliveries to skip during listing")
+
+	cmd.AddCommand(logCmd)
+	return cmd
+}
+
+// WebhooksOptions encapsulates state for the webhooks command
+type WebhooksOptions struct {
+	IOStreams
+
+	Limit  int
+	Offset int
+
+	WebhookRequests *lib.WebhookRequests
+}
+
+// Complete adds any missing configuration that can only be added just before calling Run
+func (o *WebhooksOptions) Complete(f Factory, args []string) (err error) {
+	o.WebhookRequests, err = f.WebhookRequests()
+	return
+}
+
+// Log shows webhook deliveries
+func (o *WebhooksOptions) Log() error {
+	p := &lib.ListParams{
+		Limit:  o.Limit,
+		Offset: o.Offset,
+	}
+	res := []*repo.WebhookDelivery{}
+	if err := o.WebhookRequests.Log(p, &res); err != nil {
+		return err
+	}
+
+	if len(res) == 0 {
+		printInfo(o.Out, "no webhook deliveries")
+		return nil
+	}
+
+	for i, d := range res {
+		printDelivery(o.Out, i+o.Offset+1, d)
+	}
+	return nil
+}
+
+func printDelivery(w io.Writer, i int, d *repo.WebhookDelivery) {
+	white := color.New(color.FgWhite).SprintFunc()
+	cyan := color.New(color.FgCyan).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+
+	status := green("delivered")
+	if !d.Delivered {
+		status = red("failed")
+	}
+
+	fmt.Fprintf(w, "%s  %s %s\n", cyan(i), white(d.ID), status)
+	fmt.Fprintf(w, "    url: %s\n", d.URL)
+	fmt.Fprintf(w, "    event: %s\n", d.Event)
+	if alias := d.Ref.AliasString(); alias != "" {
+		fmt.Fprintf(w, "    dataset: %s\n", alias)
+	}
+	fmt.Fprintf(w, "    attempts: %d\n", d.Attempts)
+	if d.Status != 0 {
+		fmt.Fprintf(w, "    status: %d\n", d.Status)
+	}
+	if d.Error != "" {
+		fmt.Fprintf(w, "    error: %s\n", d.Error)
+	}
+	fmt.Fprintf(w, "    time: %s\n", d.Time.Format("Mon, 02 Jan 2006 15:04"))
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qri-io/qri/repo"
+)
+
+func TestWebhooksRun(t *testing.T) {
+	streams, in, out, errs := NewTestIOStreams()
+	setNoColor(true)
+
+	f, err := NewTestFactory(nil)
+	if err != nil {
+		t.Fatalf("error creating new test factory: %s", err)
+	}
+
+	opt := &WebhooksOptions{IOStreams: streams, Limit: 25}
+	if err := opt.Complete(f, []string{}); err != nil {
+		t.Fatalf("error completing: %s", err)
+	}
+	if opt.WebhookRequests == nil {
+		t.Fatalf("expected WebhookRequests to be set")
+	}
+
+	if err := opt.Log(); err != nil {
+		t.Fatalf("error listing deliveries: %s", err)
+	}
+	if !strings.Contains(out.String(), "no webhook deliveries") {
+		t.Errorf("expected empty log output, got: '%s'", out.String())
+	}
+	ioReset(in, out, errs)
+
+	r, err := f.Repo()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	wl, ok := r.(repo.WebhookLog)
+	if !ok {
+		t.Fatal("expected test repo to implement WebhookLog")
+	}
+	if err := wl.LogDelivery(&repo.WebhookDelivery{
+		ID:       "3f2a9c0d41b7e865",
+		URL:      "https://ci.example.com/hook",
+		Event:    repo.ETDsCreated,
+		Ref:      repo.DatasetRef{Peername: "peer", Name: "movies"},
+		Attempts: 5,
+		Status:   502,
+		Error:    "webhook responded with status 502",
+		Time:     time.Date(2018, 7, 4, 12, 0, 0, 0, time.UTC),
+	}); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if err := opt.Log(); err != nil {
+		t.Fatalf("error listing deliveries: %s", err)
+	}
+	for _, expect := range []string{"3f2a9c0d41b7e865 failed", "url: https://ci.example.com/hook", "dataset: peer/movies", "attempts: 5", "status: 502"} {
+		if !strings.Contains(out.String(), expect) {
+			t.Errorf("expected log output to contain '%s', got: '%s'", expect, out.String())
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// Package compress stores dataset bodies compressed. The compression of a
+// stored body is recorded in its dataset's structure, so readers can
+// decompress it whatever compression it was saved with. Stored bodies are
+// plain compressed data, readable with any gzip or zstd tool
+package compress
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/qri-io/cafs"
+)
+
+// Format is a compression format for stored bodies
+type Format string
+
+const (
+	// None stores bodies uncompressed
+	None = Format("")
+	// Gzip compresses bodies with gzip
+	Gzip = Format("gzip")
+	// Zstd compresses bodies with zstandard
+	Zstd = Format("zstd")
+)
+
+// ParseFormat parses a compression format name, "none" & "" both mean no
+// compression
+func ParseFormat(s string) (Format, error) {
+	switch s {
+	case "", "none":
+		return None, nil
+	case "gzip":
+		return Gzip, nil
+	case "zstd":
+		return Zstd, nil
+	}
+	return None, fmt.Errorf("invalid compression '%s', must be one of none, gzip, zstd", s)
+}
+
+// String implements the fmt.Stringer interface
+func (f Format) String() string {
+	if f == None {
+		return "none"
+	}
+	return string(f)
+}
+
+// NewReader returns a reader of r decompressed from format f
+func NewReader(f Format, r io.Reader) (io.ReadCloser, error) {
+	switch f {
+	case None:
+		return ioutil.NopCloser(r), nil
+	case Gzip:
+		return gzip.NewReader(r)
+	case Zstd:
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return zr.IOReadCloser(), nil
+	}
+	return nil, fmt.Errorf("invalid compression '%s', must be one of none, gzip, zstd", f)
+}
+
+// NewWriter wraps w, compressing everything written to it in format f. The
+// returned writer must be closed to flush compressed data
+func NewWriter(f Format, w io.Writer) (io.WriteCloser, error) {
+	switch f {
+	case None:
+		return nopWriteCloser{w}, nil
+	case Gzip:
+		return gzip.NewWriter(w), nil
+	case Zstd:
+		return zstd.NewWriter(w)
+	}
+	return nil, fmt.Errorf("invalid compression '%s', must be one of none, gzip, zstd", f)
+}
+
+// Compress returns a copy of file that reads compressed in format f. File
+// names are left as-is
+func Compress(f Format, file cafs.File) cafs.File {
+	if f == None || file.IsDirectory() {
+		return file
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		defer file.Close()
+		w, err := NewWriter(f, pw)
+		if err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		if _, err = io.Copy(w, file); err == nil {
+			err = w.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+	return &readFile{File: file, r: pr, close: pr.Close}
+}
+
+// Decompress returns a copy of file that reads decompressed from format f.
+// Uncompressed files are returned unchanged
+func Decompress(f Format, file cafs.File) (cafs.File, error) {
+	if f == None || file.IsDirectory() {
+		return file, nil
+	}
+	r, err := NewReader(f, file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("decompressing %s body: %s", f, err.Error())
+	}
+	return &readFile{File: file, r: r, close: func() error {
+		r.Close()
+		return file.Close()
+	}}, nil
+}
+
+// readFile is a cafs.File that reads from r
+type readFile struct {
+	cafs.File
+	r     io.Reader
+	close func() error
+}
+
+// Read implements the io.Reader interface
+func (f *readFile) Read(p []byte) (int, error) { return f.r.Read(p) }
+
+// Close implements the io.Closer interface
+func (f *readFile) Close() error { return f.close() }
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
@@ -0,0 +1,172 @@
+package compress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/qri-io/cafs"
+	"github.com/qri-io/dataset"
+)
+
+var body = []byte(strings.Repeat("city,pop,avg_age,in_usa\ntoronto,40000000,55.5,false\n", 100))
+
+func TestParseFormat(t *testing.T) {
+	cases := []struct {
+		in     string
+		expect Format
+		err    string
+	}{
+		{"", None, ""},
+		{"none", None, ""},
+		{"gzip", Gzip, ""},
+		{"zstd", Zstd, ""},
+		{"lz4", None, "invalid compression 'lz4', must be one of none, gzip, zstd"},
+	}
+	for i, c := range cases {
+		got, err := ParseFormat(c.in)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%v'", i, c.err, err)
+			continue
+		}
+		if got != c.expect {
+			t.Errorf("case %d format mismatch. expected: %s, got: %s", i, c.expect, got)
+		}
+	}
+}
+
+func TestCompressDecompress(t *testing.T) {
+	for _, f := range []Format{None, Gzip, Zstd} {
+		compressed, err := ioutil.ReadAll(Compress(f, cafs.NewMemfileBytes("body.csv", body)))
+		if err != nil {
+			t.Fatalf("%s: compressing: %s", f, err.Error())
+		}
+		if f != None && len(compressed) >= len(body) {
+			t.Errorf("%s: expected compressed body to be smaller. body: %d bytes, compressed: %d", f, len(body), len(compressed))
+		}
+
+		file, err := Decompress(f, cafs.NewMemfileBytes("body.csv", compressed))
+		if err != nil {
+			t.Fatalf("%s: decompressing: %s", f, err.Error())
+		}
+		if file.FileName() != "body.csv" {
+			t.Errorf("%s: expected file name to be preserved, got: %s", f, file.FileName())
+		}
+		got, err := ioutil.ReadAll(file)
+		if err != nil {
+			t.Fatalf("%s: reading: %s", f, err.Error())
+		}
+		if !bytes.Equal(got, body) {
+			t.Errorf("%s: decompressed body mismatch", f)
+		}
+	}
+}
+
+func TestCompressPlain(t *testing.T) {
+	// stored bodies are plain compressed data, readable without qri
+	compressed, err := ioutil.ReadAll(Compress(Gzip, cafs.NewMemfileBytes("body.csv", body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatalf("expected a gzip stream, got error: %s", err.Error())
+	}
+	got, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, body) {
+		t.Errorf("gzip body mismatch")
+	}
+
+	if _, err := NewReader(Format("lz4"), bytes.NewReader(compressed)); err == nil {
+		t.Errorf("expected an unknown compression to error")
+	}
+}
+
+func TestStructureFormat(t *testing.T) {
+	for _, f := range []Format{None, Gzip, Zstd} {
+		ds := &dataset.Dataset{}
+		SetStructureFormat(ds, f)
+		got, err := StructureFormat(ds.Structure)
+		if err != nil {
+			t.Fatalf("%s: %s", f, err.Error())
+		}
+		if got != f {
+			t.Errorf("format mismatch. expected: %s, got: %s", f, got)
+		}
+		if f == None {
+			continue
+		}
+
+		// compressions are recorded by name
+		data, err := json.Marshal(ds.Structure)
+		if err != nil {
+			t.Fatal(err)
+		}
+		st := &dataset.Structure{}
+		if err := json.Unmarshal(data, st); err != nil {
+			t.Fatalf("%s: unmarshaling structure: %s", f, err.Error())
+		}
+		if got, err = StructureFormat(st); err != nil || got != f {
+			t.Errorf("%s: expected compression to survive encoding, got: %s, %v", f, got, err)
+		}
+		if Decompressed(ds).Structure.Compression.String() != "" || ds.Structure.Compression.String() == "" {
+			t.Errorf("%s: expected Decompressed to clear compression on a copy", f)
+		}
+	}
+}
+
+func TestStore(t *testing.T) {
+	ms := cafs.NewMapstore()
+	store := NewStore(ms, Zstd, "body.csv")
+
+	key, err := store.Put(cafs.NewMemfileBytes("body.csv", body), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// bodies are compressed in the underlying store
+	raw, err := ms.Get(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) >= len(body) {
+		t.Errorf("expected stored body to be compressed")
+	}
+
+	// & decompress to the original body
+	if raw, err = ms.Get(key); err != nil {
+		t.Fatal(err)
+	}
+	f, err := Decompress(Zstd, raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err = ioutil.ReadAll(f); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, body) {
+		t.Errorf("body mismatch decompressing stored body")
+	}
+
+	// other files pass through
+	key, err = store.Put(cafs.NewMemfileBytes("structure.json", []byte(`{"format":"csv"}`)), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw, err = ms.Get(datastore.NewKey(key.String())); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ = ioutil.ReadAll(raw); string(data) != `{"format":"csv"}` {
+		t.Errorf("expected other files to be stored as-is, got: %s", string(data))
+	}
+}
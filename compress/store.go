@@ -0,0 +1,90 @@
+package compress
+
+import (
+	"github.com/ipfs/go-datastore"
+	"github.com/qri-io/cafs"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsfs"
+)
+
+// LoadBody loads the body of a dataset from the store, decompressing it if
+// its structure records a compression
+func LoadBody(store cafs.Filestore, ds *dataset.Dataset) (cafs.File, error) {
+	format, err := StructureFormat(ds.Structure)
+	if err != nil {
+		return nil, err
+	}
+	f, err := dsfs.LoadBody(store, ds)
+	if err != nil {
+		return nil, err
+	}
+	return Decompress(format, f)
+}
+
+// Store wraps a cafs.Filestore to write a compressed dataset body. Files
+// named bodyName are compressed as they're added, everything else passes
+// through, so a Store can be handed to dsfs to save a dataset with a
+// compressed body: checksums & stats are calculated from the uncompressed
+// body before it's added. The dataset's structure must record the
+// compression, see SetStructureFormat
+type Store struct {
+	cafs.Filestore
+	format   Format
+	bodyName string
+}
+
+// NewStore wraps store, compressing files named bodyName in format f
+func NewStore(store cafs.Filestore, f Format, bodyName string) *Store {
+	return &Store{Filestore: store, format: f, bodyName: bodyName}
+}
+
+// Put implements the cafs.Filestore interface, compressing the body file
+func (s *Store) Put(file cafs.File, pin bool) (datastore.Key, error) {
+	return s.Filestore.Put(s.compress(file), pin)
+}
+
+// NewAdder implements the cafs.Filestore interface, compressing the body
+// file as it's added
+func (s *Store) NewAdder(pin, wrap bool) (cafs.Adder, error) {
+	a, err := s.Filestore.NewAdder(pin, wrap)
+	if err != nil {
+		return nil, err
+	}
+	return &adder{Adder: a, store: s}, nil
+}
+
+func (s *Store) compress(file cafs.File) cafs.File {
+	if file.IsDirectory() {
+		return &dir{File: file, store: s}
+	}
+	if file.FileName() == s.bodyName {
+		return Compress(s.format, file)
+	}
+	return file
+}
+
+// adder compresses the body file as it's added
+type adder struct {
+	cafs.Adder
+	store *Store
+}
+
+// AddFile implements the cafs.Adder interface
+func (a *adder) AddFile(f cafs.File) error {
+	return a.Adder.AddFile(a.store.compress(f))
+}
+
+// dir compresses the body file if it's one of a directory's children
+type dir struct {
+	cafs.File
+	store *Store
+}
+
+// NextFile implements the cafs.File interface
+func (d *dir) NextFile() (cafs.File, error) {
+	f, err := d.File.NextFile()
+	if err != nil {
+		return f, err
+	}
+	return d.store.compress(f), nil
+}
@@ -0,0 +1,70 @@
+package compress
+
+import (
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/compression"
+)
+
+// ZstdType is the compression.Type that records zstd compression in a
+// dataset structure. The compression package doesn't define one, so it's
+// registered alongside the package's own codes
+var ZstdType compression.Type
+
+func init() {
+	for t := range compression.Names {
+		if t >= ZstdType {
+			ZstdType = t + 1
+		}
+	}
+	compression.Names[ZstdType] = string(Zstd)
+	compression.Codes[string(Zstd)] = ZstdType
+	if _, ok := compression.Codes[string(Gzip)]; !ok {
+		compression.Codes[string(Gzip)] = compression.Gzip
+	}
+}
+
+// Type gives the compression.Type that records f in a dataset structure
+func (f Format) Type() compression.Type {
+	switch f {
+	case Gzip:
+		return compression.Gzip
+	case Zstd:
+		return ZstdType
+	}
+	return compression.None
+}
+
+// StructureFormat gives the compression a structure records for its body.
+// A nil structure has an uncompressed body
+func StructureFormat(st *dataset.Structure) (Format, error) {
+	if st == nil {
+		return None, nil
+	}
+	return ParseFormat(st.Compression.String())
+}
+
+// SetStructureFormat records that the body ds describes is stored
+// compressed in format f, adding a structure if ds doesn't have one
+func SetStructureFormat(ds *dataset.Dataset, f Format) {
+	if ds.Structure == nil {
+		if f == None {
+			return
+		}
+		ds.Structure = &dataset.Structure{}
+	}
+	ds.Structure.Compression = f.Type()
+}
+
+// Decompressed returns a shallow copy of ds with a structure that describes
+// its body decompressed, for exporting a dataset with a body read through
+// LoadBody
+func Decompressed(ds *dataset.Dataset) *dataset.Dataset {
+	if ds.Structure == nil || ds.Structure.Compression == compression.None {
+		return ds
+	}
+	cpy := *ds
+	st := *ds.Structure
+	st.Compression = compression.None
+	cpy.Structure = &st
+	return &cpy
+}
@@ -10,6 +10,18 @@ import (
 // DefaultAPIPort is local the port webapp serves on by default
 var DefaultAPIPort = 2503
 
+// TLS modes select where the api gets https certificates from
+const (
+	// TLSModeAutocert provisions certificates from LetsEncrypt, which
+	// requires public DNS for URLRoot & an open port 80
+	TLSModeAutocert = "autocert"
+	// TLSModeCert serves a user-supplied certificate & key
+	TLSModeCert = "cert"
+	// TLSModeSelfSigned serves a self-signed certificate that's generated on
+	// setup & stored in the qri repo. it's regenerated if missing or expired
+	TLSModeSelfSigned = "selfsigned"
+)
+
 // API holds configuration for the qri JSON api
 type API struct {
 	Enabled bool `json:"enabled"`
@@ -19,8 +31,18 @@ type API struct {
 	ReadOnly bool `json:"readonly"`
 	// URLRoot is the base url for this server
 	URLRoot string `json:"urlroot"`
-	// TLS enables https via letsEyncrypt
+	// TLS enables https, with certificates sourced according to TLSMode
 	TLS bool `json:"tls"`
+	// TLSMode is one of "autocert", "cert" or "selfsigned". default is
+	// "autocert", which uses letsEncrypt
+	TLSMode string `json:"tlsmode,omitempty"`
+	// TLSCertFile & TLSKeyFile are paths to a PEM-encoded certificate & key,
+	// used in "cert" & "selfsigned" modes
+	TLSCertFile string `json:"tlscertfile,omitempty"`
+	TLSKeyFile  string `json:"tlskeyfile,omitempty"`
+	// TLSClientCAFile is the path to a PEM-encoded bundle of CA certificates.
+	// when set, clients must present a certificate signed by one of these CAs
+	TLSClientCAFile string `json:"tlsclientcafile,omitempty"`
 	// Time in seconds to stop the server after,
 	// default 0 means keep alive indefinitely
 	DisconnectAfter int `json:"disconnectafter,omitempty"`
@@ -29,6 +51,9 @@ type API struct {
 	ProxyForceHTTPS bool `json:"proxyforcehttps"`
 	// support CORS signing from a list of origins
 	AllowedOrigins []string `json:"allowedorigins"`
+	// RequireTokens rejects requests that don't carry a valid api token or
+	// request signature with enough scope. tokens are managed with `qri token`
+	RequireTokens bool `json:"requiretokens,omitempty"`
 }
 
 // Validate validates all fields of api returning all errors found.
@@ -57,9 +82,31 @@ func (a API) Validate() error {
         "type": "string"
       },
       "tls": {
-        "description": "Enables https via letsEncrypt",
+        "description": "Enables https, with certificates sourced according to tlsmode",
         "type": "boolean"
       },
+      "tlsmode": {
+        "description": "Where https certificates come from. autocert uses letsEncrypt, cert uses tlscertfile & tlskeyfile, selfsigned uses a certificate generated on setup",
+        "type": "string",
+        "enum": [
+          "",
+          "autocert",
+          "cert",
+          "selfsigned"
+        ]
+      },
+      "tlscertfile": {
+        "description": "Path to a PEM-encoded certificate",
+        "type": "string"
+      },
+      "tlskeyfile": {
+        "description": "Path to the PEM-encoded private key of tlscertfile",
+        "type": "string"
+      },
+      "tlsclientcafile": {
+        "description": "Path to PEM-encoded CA certificates. When set, clients must present a certificate signed by one of these CAs",
+        "type": "string"
+      },
       "disconnectafter": {
         "description": "time in seconds to stop the server after",
         "type": "integer"
@@ -74,6 +121,10 @@ func (a API) Validate() error {
         "items": {
           "type": "string"
         }
+      },
+      "requiretokens": {
+        "description": "When true, requests must carry a valid api token or request signature with enough scope",
+        "type": "boolean"
       }
     }
   }`)
@@ -103,8 +154,13 @@ func (a *API) Copy() *API {
 		ReadOnly:        a.ReadOnly,
 		URLRoot:         a.URLRoot,
 		TLS:             a.TLS,
+		TLSMode:         a.TLSMode,
+		TLSCertFile:     a.TLSCertFile,
+		TLSKeyFile:      a.TLSKeyFile,
+		TLSClientCAFile: a.TLSClientCAFile,
 		DisconnectAfter: a.DisconnectAfter,
 		ProxyForceHTTPS: a.ProxyForceHTTPS,
+		RequireTokens:   a.RequireTokens,
 	}
 	if a.AllowedOrigins != nil {
 		res.AllowedOrigins = make([]string, len(a.AllowedOrigins))
@@ -10,6 +10,12 @@ func TestAPIValidate(t *testing.T) {
 	if err != nil {
 		t.Errorf("error validating default api: %s", err)
 	}
+
+	a := DefaultAPI()
+	a.TLSMode = "sometimes"
+	if err := a.Validate(); err == nil {
+		t.Errorf("expected invalid tls mode to fail validation")
+	}
 }
 
 func TestAPICopy(t *testing.T) {
@@ -17,6 +23,8 @@ func TestAPICopy(t *testing.T) {
 		api *API
 	}{
 		{DefaultAPI()},
+		{&API{Port: 8080, RequireTokens: true, AllowedOrigins: []string{"http://localhost"}}},
+		{&API{Port: 8080, TLS: true, TLSMode: TLSModeCert, TLSCertFile: "cert.pem", TLSKeyFile: "key.pem", TLSClientCAFile: "ca.pem", AllowedOrigins: []string{"http://localhost"}}},
 	}
 	for i, c := range cases {
 		cpy := c.api.Copy()
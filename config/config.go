@@ -29,7 +29,8 @@ type Config struct {
 	RPC     *RPC
 	Logging *Logging
 
-	Render *Render
+	Render   *Render
+	Webhooks *Webhooks
 }
 
 // DefaultConfig gives a new default qri configuration
@@ -47,7 +48,8 @@ func DefaultConfig() *Config {
 		RPC:     DefaultRPC(),
 		Logging: DefaultLogging(),
 
-		Render: DefaultRender(),
+		Render:   DefaultRender(),
+		Webhooks: DefaultWebhooks(),
 	}
 }
 
@@ -65,6 +67,9 @@ func (cfg Config) SummaryString() (summary string) {
 
 	if cfg.RPC != nil && cfg.RPC.Enabled {
 		summary += fmt.Sprintf("RPC port:\t%d\n", cfg.RPC.Port)
+		if cfg.RPC.Socket != "" {
+			summary += fmt.Sprintf("RPC socket:\t%s\n", cfg.RPC.Socket)
+		}
 	}
 
 	if cfg.Webapp != nil && cfg.Webapp.Enabled {
@@ -282,6 +287,11 @@ func (cfg Config) Validate() error {
 	if err := cfg.RPC.Validate(); err != nil {
 		return err
 	}
+	if cfg.Webhooks != nil {
+		if err := cfg.Webhooks.Validate(); err != nil {
+			return err
+		}
+	}
 	return cfg.Logging.Validate()
 }
 
@@ -322,6 +332,9 @@ func (cfg *Config) Copy() *Config {
 	if cfg.Render != nil {
 		res.Render = cfg.Render.Copy()
 	}
+	if cfg.Webhooks != nil {
+		res.Webhooks = cfg.Webhooks.Copy()
+	}
 
 	return res
 }
@@ -332,6 +345,11 @@ func (cfg *Config) WithoutPrivateValues() *Config {
 
 	res.Profile.PrivKey = ""
 	res.P2P.PrivKey = ""
+	if res.Webhooks != nil {
+		for _, h := range res.Webhooks.Hooks {
+			h.Secret = ""
+		}
+	}
 
 	return res
 }
@@ -343,6 +361,17 @@ func (cfg *Config) WithPrivateValues(p *Config) *Config {
 
 	res.Profile.PrivKey = p.Profile.PrivKey
 	res.P2P.PrivKey = p.P2P.PrivKey
+	// webhook secrets are restored for hooks that keep the same url
+	if res.Webhooks != nil && p.Webhooks != nil {
+		for _, h := range res.Webhooks.Hooks {
+			for _, prev := range p.Webhooks.Hooks {
+				if h.Secret == "" && h.URL == prev.URL {
+					h.Secret = prev.Secret
+					break
+				}
+			}
+		}
+	}
 
 	return res
 }
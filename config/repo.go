@@ -10,6 +10,29 @@ import (
 type Repo struct {
 	Middleware []string `json:"middleware"`
 	Type       string   `json:"type"`
+	// Validation sets how saving a dataset treats bodies with schema
+	// validation errors, one of "strict", "warn", or "off". default is "warn".
+	// "off" only silences warnings, errors are still counted
+	Validation string `json:"validation,omitempty"`
+	// DatasetValidation overrides Validation for individual datasets,
+	// keyed by "peername/dataset_name"
+	DatasetValidation map[string]string `json:"datasetValidation,omitempty"`
+	// BlockBreakingSchemaChanges refuses saves that change a dataset schema
+	// in ways that aren't backward-compatible, like removing a column
+	BlockBreakingSchemaChanges bool `json:"blockBreakingSchemaChanges,omitempty"`
+	// Compression sets how dataset bodies are compressed in the store, one
+	// of "none", "gzip", or "zstd". default is "none"
+	Compression string `json:"compression,omitempty"`
+	// DatasetCompression overrides Compression for individual datasets,
+	// keyed by "peername/dataset_name"
+	DatasetCompression map[string]string `json:"datasetCompression,omitempty"`
+	// ChunkSize splits dataset bodies into chunks of roughly this many bytes
+	// when they're stored, so versions can share unchanged chunks. default
+	// is 0, which stores whole bodies
+	ChunkSize int `json:"chunkSize,omitempty"`
+	// DatasetChunkSize overrides ChunkSize for individual datasets, keyed by
+	// "peername/dataset_name"
+	DatasetChunkSize map[string]int `json:"datasetChunkSize,omitempty"`
 }
 
 // DefaultRepo creates & returns a new default repo configuration
@@ -42,6 +65,49 @@ func (cfg Repo) Validate() error {
         "enum": [
           "fs"
         ]
+      },
+      "validation": {
+        "description": "How saves treat bodies with validation errors",
+        "type": "string",
+        "enum": ["strict", "warn", "off"]
+      },
+      "datasetValidation": {
+        "description": "Validation policies for individual datasets, keyed by peername/dataset_name",
+        "type": "object",
+        "additionalProperties": {
+          "type": "string",
+          "enum": ["strict", "warn", "off"]
+        }
+      },
+      "blockBreakingSchemaChanges": {
+        "description": "Refuse saves that make breaking changes to a dataset schema",
+        "type": "boolean"
+      },
+      "compression": {
+        "description": "Compression for stored dataset bodies",
+        "type": "string",
+        "enum": ["none", "gzip", "zstd"]
+      },
+      "datasetCompression": {
+        "description": "Compression for the bodies of individual datasets, keyed by peername/dataset_name",
+        "type": "object",
+        "additionalProperties": {
+          "type": "string",
+          "enum": ["none", "gzip", "zstd"]
+        }
+      },
+      "chunkSize": {
+        "description": "Size in bytes of the chunks dataset bodies are split into, 0 stores whole bodies",
+        "type": "integer",
+        "minimum": 0
+      },
+      "datasetChunkSize": {
+        "description": "Chunk sizes for the bodies of individual datasets, keyed by peername/dataset_name",
+        "type": "object",
+        "additionalProperties": {
+          "type": "integer",
+          "minimum": 0
+        }
       }
     }
   }`)
@@ -51,12 +117,62 @@ func (cfg Repo) Validate() error {
 // Copy returns a deep copy of the Repo struct
 func (cfg *Repo) Copy() *Repo {
 	res := &Repo{
-		Type: cfg.Type,
+		Type:        cfg.Type,
+		Validation:  cfg.Validation,
+		Compression: cfg.Compression,
+		ChunkSize:   cfg.ChunkSize,
+
+		BlockBreakingSchemaChanges: cfg.BlockBreakingSchemaChanges,
 	}
 	if cfg.Middleware != nil {
 		res.Middleware = make([]string, len(cfg.Middleware))
 		reflect.Copy(reflect.ValueOf(res.Middleware), reflect.ValueOf(cfg.Middleware))
 	}
+	if cfg.DatasetValidation != nil {
+		res.DatasetValidation = map[string]string{}
+		for alias, policy := range cfg.DatasetValidation {
+			res.DatasetValidation[alias] = policy
+		}
+	}
+	if cfg.DatasetCompression != nil {
+		res.DatasetCompression = map[string]string{}
+		for alias, compression := range cfg.DatasetCompression {
+			res.DatasetCompression[alias] = compression
+		}
+	}
+	if cfg.DatasetChunkSize != nil {
+		res.DatasetChunkSize = map[string]int{}
+		for alias, size := range cfg.DatasetChunkSize {
+			res.DatasetChunkSize[alias] = size
+		}
+	}
 
 	return res
 }
+
+// ValidationPolicy returns the validation policy for a dataset, falling back
+// to the repo-wide policy
+func (cfg *Repo) ValidationPolicy(peername, name string) string {
+	if policy, ok := cfg.DatasetValidation[peername+"/"+name]; ok {
+		return policy
+	}
+	return cfg.Validation
+}
+
+// BodyCompression returns the body compression for a dataset, falling back
+// to the repo-wide compression
+func (cfg *Repo) BodyCompression(peername, name string) string {
+	if compression, ok := cfg.DatasetCompression[peername+"/"+name]; ok {
+		return compression
+	}
+	return cfg.Compression
+}
+
+// BodyChunkSize returns the body chunk size for a dataset, falling back to
+// the repo-wide chunk size
+func (cfg *Repo) BodyChunkSize(peername, name string) int {
+	if size, ok := cfg.DatasetChunkSize[peername+"/"+name]; ok {
+		return size
+	}
+	return cfg.ChunkSize
+}
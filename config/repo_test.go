@@ -36,3 +36,84 @@ func TestRepoCopy(t *testing.T) {
 		}
 	}
 }
+
+func TestRepoValidationPolicy(t *testing.T) {
+	r := DefaultRepo()
+	r.Validation = "warn"
+	r.DatasetValidation = map[string]string{"b5/comics": "strict"}
+
+	if err := r.Validate(); err != nil {
+		t.Errorf("error validating repo: %s", err)
+	}
+	if got := r.ValidationPolicy("b5", "comics"); got != "strict" {
+		t.Errorf("expected dataset policy to override repo policy. got: %s", got)
+	}
+	if got := r.ValidationPolicy("b5", "other"); got != "warn" {
+		t.Errorf("expected repo policy. got: %s", got)
+	}
+
+	cpy := r.Copy()
+	cpy.DatasetValidation["b5/comics"] = "off"
+	if r.DatasetValidation["b5/comics"] != "strict" {
+		t.Errorf("editing a copy should not affect the original")
+	}
+
+	r.Validation = "sometimes"
+	if err := r.Validate(); err == nil {
+		t.Errorf("expected invalid policy to error")
+	}
+}
+
+func TestRepoBodyCompression(t *testing.T) {
+	r := DefaultRepo()
+	r.Compression = "gzip"
+	r.DatasetCompression = map[string]string{"b5/comics": "zstd"}
+
+	if err := r.Validate(); err != nil {
+		t.Errorf("error validating repo: %s", err)
+	}
+	if got := r.BodyCompression("b5", "comics"); got != "zstd" {
+		t.Errorf("expected dataset compression to override repo compression. got: %s", got)
+	}
+	if got := r.BodyCompression("b5", "other"); got != "gzip" {
+		t.Errorf("expected repo compression. got: %s", got)
+	}
+
+	cpy := r.Copy()
+	cpy.DatasetCompression["b5/comics"] = "none"
+	if r.DatasetCompression["b5/comics"] != "zstd" {
+		t.Errorf("editing a copy should not affect the original")
+	}
+
+	r.Compression = "lz4"
+	if err := r.Validate(); err == nil {
+		t.Errorf("expected invalid compression to error")
+	}
+}
+
+func TestRepoBodyChunkSize(t *testing.T) {
+	r := DefaultRepo()
+	r.ChunkSize = 1024
+	r.DatasetChunkSize = map[string]int{"b5/comics": 0}
+
+	if err := r.Validate(); err != nil {
+		t.Errorf("error validating repo: %s", err)
+	}
+	if got := r.BodyChunkSize("b5", "comics"); got != 0 {
+		t.Errorf("expected dataset chunk size to override repo chunk size. got: %d", got)
+	}
+	if got := r.BodyChunkSize("b5", "other"); got != 1024 {
+		t.Errorf("expected repo chunk size. got: %d", got)
+	}
+
+	cpy := r.Copy()
+	cpy.DatasetChunkSize["b5/comics"] = 2048
+	if r.DatasetChunkSize["b5/comics"] != 0 {
+		t.Errorf("editing a copy should not affect the original")
+	}
+
+	r.ChunkSize = -1
+	if err := r.Validate(); err == nil {
+		t.Errorf("expected negative chunk size to error")
+	}
+}
@@ -6,6 +6,14 @@ import "github.com/qri-io/jsonschema"
 type RPC struct {
 	Enabled bool `json:"enabled"`
 	Port    int  `json:"port"`
+	// HTTP serves JSON-RPC 2.0 requests at the api /rpc endpoint. /rpc gives
+	// full control of the node, so it's only served when the api requires
+	// tokens, and needs an admin token. the unix socket is the way to make
+	// local calls without a token
+	HTTP bool `json:"http"`
+	// Socket is the path of a unix domain socket to serve JSON-RPC 2.0
+	// requests on. an empty path disables the socket
+	Socket string `json:"socket,omitempty"`
 }
 
 // DefaultRPCPort is local the port RPC serves on by default
@@ -36,6 +44,14 @@ func (cfg RPC) Validate() error {
       "port": {
         "description": "The port on which to listen for rpc calls",
         "type": "integer"
+      },
+      "http": {
+        "description": "When true, JSON-RPC 2.0 calls are served at the api /rpc endpoint. requires api tokens",
+        "type": "boolean"
+      },
+      "socket": {
+        "description": "Path of a unix domain socket to serve JSON-RPC 2.0 calls on",
+        "type": "string"
       }
     }
   }`)
@@ -47,6 +63,8 @@ func (cfg *RPC) Copy() *RPC {
 	res := &RPC{
 		Enabled: cfg.Enabled,
 		Port:    cfg.Port,
+		HTTP:    cfg.HTTP,
+		Socket:  cfg.Socket,
 	}
 
 	return res
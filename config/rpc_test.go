@@ -17,6 +17,7 @@ func TestRPCCopy(t *testing.T) {
 		rpc *RPC
 	}{
 		{DefaultRPC()},
+		{&RPC{Enabled: true, Port: 2504, Socket: "/tmp/qri.sock"}},
 	}
 	for i, c := range cases {
 		cpy := c.rpc.Copy()
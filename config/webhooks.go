@@ -0,0 +1,103 @@
+package config
+
+import (
+	"reflect"
+
+	"github.com/qri-io/jsonschema"
+)
+
+// Webhooks configures http requests qri sends when repo events happen.
+// webhooks are sent while qri is connected. events logged while qri isn't
+// connected are sent the next time it connects
+type Webhooks struct {
+	Hooks []*Webhook `json:"hooks,omitempty"`
+}
+
+// Webhook is a single destination for repo events
+type Webhook struct {
+	// URL is the address events are POSTed to
+	URL string `json:"url"`
+	// Events limits the event types sent to this webhook, eg: "ds_created".
+	// an empty list sends all events
+	Events []string `json:"events,omitempty"`
+	// Datasets limits events to datasets with aliases that match a pattern,
+	// eg: "me/*" or "peer/cities". an empty pattern sends events for all
+	// datasets
+	Datasets string `json:"datasets,omitempty"`
+	// Secret is shared with the receiver to sign event payloads
+	Secret string `json:"secret,omitempty"`
+}
+
+// DefaultWebhooks creates a new default Webhooks configuration
+func DefaultWebhooks() *Webhooks {
+	return &Webhooks{}
+}
+
+// Validate validates all fields of webhooks returning all errors found.
+func (cfg Webhooks) Validate() error {
+	schema := jsonschema.Must(`{
+    "$schema": "http://json-schema.org/draft-06/schema#",
+    "title": "Webhooks",
+    "description": "Config for webhooks",
+    "type": "object",
+    "properties": {
+      "hooks": {
+        "description": "Destinations for repo events",
+        "type": "array",
+        "items": {
+          "type": "object",
+          "required": ["url"],
+          "properties": {
+            "url": {
+              "description": "The address events are POSTed to",
+              "type": "string",
+              "pattern": "^https?://"
+            },
+            "events": {
+              "description": "Event types to send, all events are sent when empty",
+              "type": "array",
+              "items": {
+                "type": "string"
+              }
+            },
+            "datasets": {
+              "description": "Only send events for datasets that match this pattern, eg: me/*",
+              "type": "string"
+            },
+            "secret": {
+              "description": "Shared secret used to sign event payloads",
+              "type": "string"
+            }
+          }
+        }
+      }
+    }
+  }`)
+	return validate(schema, &cfg)
+}
+
+// Copy returns a deep copy of the Webhooks struct
+func (cfg *Webhooks) Copy() *Webhooks {
+	res := &Webhooks{}
+	if cfg.Hooks != nil {
+		res.Hooks = make([]*Webhook, len(cfg.Hooks))
+		for i, h := range cfg.Hooks {
+			res.Hooks[i] = h.Copy()
+		}
+	}
+	return res
+}
+
+// Copy returns a deep copy of the Webhook struct
+func (h *Webhook) Copy() *Webhook {
+	res := &Webhook{
+		URL:      h.URL,
+		Datasets: h.Datasets,
+		Secret:   h.Secret,
+	}
+	if h.Events != nil {
+		res.Events = make([]string, len(h.Events))
+		reflect.Copy(reflect.ValueOf(res.Events), reflect.ValueOf(h.Events))
+	}
+	return res
+}
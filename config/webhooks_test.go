@@ -0,0 +1,61 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWebhooksValidate(t *testing.T) {
+	if err := DefaultWebhooks().Validate(); err != nil {
+		t.Errorf("error validating default webhooks: %s", err)
+	}
+
+	cfg := &Webhooks{Hooks: []*Webhook{{URL: "https://ci.example.com/hook", Events: []string{"ds_created"}, Datasets: "me/*"}}}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("error validating webhooks: %s", err)
+	}
+
+	cfg.Hooks[0].URL = "ftp://ci.example.com"
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected validating a non-http webhook url to error")
+	}
+}
+
+func TestWebhooksCopy(t *testing.T) {
+	cases := []struct {
+		webhooks *Webhooks
+	}{
+		{DefaultWebhooks()},
+		{&Webhooks{Hooks: []*Webhook{{URL: "https://ci.example.com/hook", Events: []string{"ds_created"}, Secret: "secret"}}}},
+	}
+	for i, c := range cases {
+		cpy := c.webhooks.Copy()
+		if !reflect.DeepEqual(cpy, c.webhooks) {
+			t.Errorf("Webhooks Copy test case %v, webhooks structs are not equal: \ncopy: %v, \noriginal: %v", i, cpy, c.webhooks)
+			continue
+		}
+		cpy.Hooks = append(cpy.Hooks, &Webhook{URL: "http://localhost"})
+		if reflect.DeepEqual(cpy, c.webhooks) {
+			t.Errorf("Webhooks Copy test case %v, editing one webhooks struct should not affect the other: \ncopy: %v, \noriginal: %v", i, cpy, c.webhooks)
+			continue
+		}
+	}
+}
+
+func TestWebhookSecretsArePrivate(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Webhooks.Hooks = []*Webhook{{URL: "https://ci.example.com/hook", Secret: "secret"}}
+
+	public := cfg.WithoutPrivateValues()
+	if public.Webhooks.Hooks[0].Secret != "" {
+		t.Errorf("expected webhook secret to be removed")
+	}
+	if cfg.Webhooks.Hooks[0].Secret != "secret" {
+		t.Errorf("removing private values shouldn't alter the original config")
+	}
+
+	restored := public.WithPrivateValues(cfg)
+	if restored.Webhooks.Hooks[0].Secret != "secret" {
+		t.Errorf("expected webhook secret to be restored, got: '%s'", restored.Webhooks.Hooks[0].Secret)
+	}
+}
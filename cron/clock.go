@@ -0,0 +1,24 @@
+package cron
+
+import "time"
+
+// Clock is the source of time for anything that runs on a schedule.
+// Swapping in a fake clock lets schedules be tested deterministically
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// After waits for duration d to elapse, then sends the current time
+	// on the returned channel
+	After(d time.Duration) <-chan time.Time
+}
+
+// RealClock is a Clock backed by the time package
+var RealClock Clock = realClock{}
+
+type realClock struct{}
+
+// Now implements the Clock interface
+func (realClock) Now() time.Time { return time.Now() }
+
+// After implements the Clock interface
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
@@ -0,0 +1,252 @@
+// Package cron parses cron-like schedule specifications & calculates when
+// a schedule should next run. Specs are either the classic five-field
+// "minute hour day-of-month month day-of-week" form, one of the predefined
+// descriptors (@yearly, @monthly, @weekly, @daily, @hourly), or a fixed
+// interval in the form "@every <duration>", eg: "@every 6h"
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Spec is a parsed schedule specification
+type Spec struct {
+	raw   string
+	every time.Duration
+
+	minute, hour, dom, month, dow uint64
+	// domStar & dowStar record if day-of-month or day-of-week were wildcards,
+	// which changes how day matching works. see Spec.dayMatches
+	domStar, dowStar bool
+}
+
+// bounds defines the inclusive range of valid values for a field
+type bounds struct {
+	name     string
+	min, max uint
+}
+
+var (
+	minutes     = bounds{"minute", 0, 59}
+	hours       = bounds{"hour", 0, 23}
+	daysOfMonth = bounds{"day of month", 1, 31}
+	months      = bounds{"month", 1, 12}
+	daysOfWeek  = bounds{"day of week", 0, 7}
+)
+
+// descriptors maps predefined schedules to their five-field equivalents
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// maxSearchYears caps how far into the future Next will look for a
+// matching time, which protects against specs like "0 0 30 2 *" that
+// can never occur
+const maxSearchYears = 5
+
+// Parse reads a schedule specification string
+func Parse(spec string) (*Spec, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, fmt.Errorf("empty schedule spec")
+	}
+
+	s := &Spec{raw: spec}
+
+	if strings.HasPrefix(spec, "@every") {
+		d, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(spec, "@every")))
+		if err != nil {
+			return nil, fmt.Errorf("invalid @every duration: %s", err.Error())
+		}
+		if d < time.Minute {
+			return nil, fmt.Errorf("@every duration must be at least one minute")
+		}
+		s.every = d
+		return s, nil
+	}
+
+	if strings.HasPrefix(spec, "@") {
+		expanded, ok := descriptors[spec]
+		if !ok {
+			return nil, fmt.Errorf("unrecognized descriptor: %s", spec)
+		}
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected 5 fields in schedule spec, got %d: '%s'", len(fields), spec)
+	}
+
+	var err error
+	if s.minute, err = parseField(fields[0], minutes); err != nil {
+		return nil, err
+	}
+	if s.hour, err = parseField(fields[1], hours); err != nil {
+		return nil, err
+	}
+	if s.dom, err = parseField(fields[2], daysOfMonth); err != nil {
+		return nil, err
+	}
+	if s.month, err = parseField(fields[3], months); err != nil {
+		return nil, err
+	}
+	if s.dow, err = parseField(fields[4], daysOfWeek); err != nil {
+		return nil, err
+	}
+	// sunday can be specified as either 0 or 7
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+
+	s.domStar = fields[2] == "*"
+	s.dowStar = fields[4] == "*"
+	return s, nil
+}
+
+// MustParse parses a spec, panicing on error
+func MustParse(spec string) *Spec {
+	s, err := Parse(spec)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// String returns the spec as it was originally written
+func (s *Spec) String() string {
+	return s.raw
+}
+
+// Next returns the first time after t that matches this spec. Next returns
+// the zero time if no matching time can be found
+func (s *Spec) Next(t time.Time) time.Time {
+	if s.every > 0 {
+		return t.Add(s.every)
+	}
+
+	// start at the next whole minute
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Year() + maxSearchYears
+
+	for t.Year() <= limit {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
+
+// dayMatches follows the cron convention: if both day-of-month and day-of-week
+// are restricted, a day matching either field is a match
+func (s *Spec) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// parseField turns a comma-separated list of values, ranges & steps
+// into a bitset of matching values
+func parseField(field string, b bounds) (bits uint64, err error) {
+	for _, expr := range strings.Split(field, ",") {
+		var set uint64
+		if set, err = parseExpr(expr, b); err != nil {
+			return 0, err
+		}
+		bits |= set
+	}
+	return bits, nil
+}
+
+// parseExpr handles a single list element of the form
+// "*", "n", "n-m", with an optional "/step" suffix
+func parseExpr(expr string, b bounds) (uint64, error) {
+	var (
+		start, end = b.min, b.max
+		step       = uint(1)
+		rangeExpr  = expr
+	)
+
+	if i := strings.Index(expr, "/"); i >= 0 {
+		rangeExpr = expr[:i]
+		s, err := parseUint(expr[i+1:], b)
+		if err != nil {
+			return 0, err
+		}
+		if s == 0 {
+			return 0, fmt.Errorf("invalid %s step: 0", b.name)
+		}
+		step = s
+	}
+
+	if rangeExpr != "*" {
+		if i := strings.Index(rangeExpr, "-"); i >= 0 {
+			var err error
+			if start, err = parseUint(rangeExpr[:i], b); err != nil {
+				return 0, err
+			}
+			if end, err = parseUint(rangeExpr[i+1:], b); err != nil {
+				return 0, err
+			}
+		} else {
+			v, err := parseUint(rangeExpr, b)
+			if err != nil {
+				return 0, err
+			}
+			start = v
+			// a single value with a step means "starting at"
+			if step == 1 {
+				end = v
+			}
+		}
+	}
+
+	if start < b.min || end > b.max || start > end {
+		return 0, fmt.Errorf("%s range %d-%d is outside of %d-%d", b.name, start, end, b.min, b.max)
+	}
+
+	var bits uint64
+	for i := start; i <= end; i += step {
+		bits |= 1 << i
+	}
+	return bits, nil
+}
+
+func parseUint(str string, b bounds) (uint, error) {
+	v, err := strconv.ParseUint(str, 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s value: '%s'", b.name, str)
+	}
+	if uint(v) < b.min || uint(v) > b.max {
+		return 0, fmt.Errorf("%s value %d is outside of %d-%d", b.name, v, b.min, b.max)
+	}
+	return uint(v), nil
+}
@@ -0,0 +1,77 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		spec string
+		err  string
+	}{
+		{"* * * * *", ""},
+		{"0 0 * * *", ""},
+		{"*/15 9-17 * * 1-5", ""},
+		{"0 0 1,15 * *", ""},
+		{"@daily", ""},
+		{"@every 6h", ""},
+		{"", "empty schedule spec"},
+		{"* * * *", "expected 5 fields in schedule spec, got 4: '* * * *'"},
+		{"60 * * * *", "minute value 60 is outside of 0-59"},
+		{"* 24 * * *", "hour value 24 is outside of 0-23"},
+		{"* * 0 * *", "day of month value 0 is outside of 1-31"},
+		{"*/0 * * * *", "invalid minute step: 0"},
+		{"5-1 * * * *", "minute range 5-1 is outside of 0-59"},
+		{"@sometimes", "unrecognized descriptor: @sometimes"},
+		{"@every 30s", "@every duration must be at least one minute"},
+		{"@every forever", "invalid @every duration: time: invalid duration \"forever\""},
+	}
+
+	for i, c := range cases {
+		_, err := Parse(c.spec)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%v'", i, c.err, err)
+		}
+	}
+}
+
+func TestSpecNext(t *testing.T) {
+	// 2018-07-04 was a wednesday
+	start := time.Date(2018, 7, 4, 10, 30, 15, 0, time.UTC)
+
+	cases := []struct {
+		spec   string
+		expect time.Time
+	}{
+		{"* * * * *", time.Date(2018, 7, 4, 10, 31, 0, 0, time.UTC)},
+		{"0 * * * *", time.Date(2018, 7, 4, 11, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2018, 7, 4, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2018, 7, 5, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2018, 7, 8, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2018, 8, 1, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"*/20 * * * *", time.Date(2018, 7, 4, 10, 40, 0, 0, time.UTC)},
+		{"30 9 * * 1-5", time.Date(2018, 7, 5, 9, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2018, 7, 8, 0, 0, 0, 0, time.UTC)},
+		// day of month or day of week when both are restricted
+		{"0 0 10 * 5", time.Date(2018, 7, 6, 0, 0, 0, 0, time.UTC)},
+		{"0 12 29 2 *", time.Date(2020, 2, 29, 12, 0, 0, 0, time.UTC)},
+		{"@every 90m", time.Date(2018, 7, 4, 12, 0, 15, 0, time.UTC)},
+		// impossible dates never match
+		{"0 0 30 2 *", time.Time{}},
+	}
+
+	for i, c := range cases {
+		got := MustParse(c.spec).Next(start)
+		if !got.Equal(c.expect) {
+			t.Errorf("case %d '%s' expected: %s, got: %s", i, c.spec, c.expect, got)
+		}
+	}
+}
+
+func TestSpecString(t *testing.T) {
+	if got := MustParse("@daily").String(); got != "@daily" {
+		t.Errorf("expected spec string to be '@daily', got: '%s'", got)
+	}
+}
@@ -0,0 +1,265 @@
+// Package export writes dataset bodies to formats qri doesn't store bodies
+// in: NDJSON, parquet, xlsx workbooks & SQLite databases. Writers read one
+// entry at a time from a dsio.EntryReader, so bodies of any size can be
+// exported without buffering the whole body
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/qri/render"
+)
+
+const (
+	// FormatNDJSON is newline-delimited JSON, one entry per line
+	FormatNDJSON = "ndjson"
+	// FormatParquet is the apache parquet columnar format
+	FormatParquet = "parquet"
+	// FormatXLSX is an Excel workbook with a single sheet
+	FormatXLSX = "xlsx"
+	// FormatSQLite is a SQLite database file with a table per dataset
+	FormatSQLite = "sqlite"
+)
+
+// Formats lists the formats this package can write
+var Formats = []string{FormatNDJSON, FormatParquet, FormatXLSX, FormatSQLite}
+
+// IsFormat checks if format is one this package can write
+func IsFormat(format string) bool {
+	for _, f := range Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// Write streams entries from r to w. name is used where a format names its
+// contents, like the sheet of an xlsx workbook. SQLite databases can't be
+// streamed, use OpenSQLite instead
+func Write(format string, w io.Writer, r dsio.EntryReader, name string) error {
+	switch format {
+	case FormatNDJSON:
+		return WriteNDJSON(w, r)
+	case FormatParquet:
+		return WriteParquet(w, r)
+	case FormatXLSX:
+		return WriteXLSX(w, r, name)
+	case FormatSQLite:
+		return fmt.Errorf("sqlite databases must be written to a file")
+	}
+	return fmt.Errorf("unsupported export format: '%s'. supported formats are: %s", format, strings.Join(Formats, ", "))
+}
+
+// table lays body entries out as rows of a table. Columns come from the body
+// schema, falling back to the first entry when the schema doesn't describe
+// columns. Entries of object bodies get a leading "key" column
+type table struct {
+	cols  []render.Column
+	keyed bool
+	// scalar is true for bodies of plain values, which are written to a
+	// single "value" column
+	scalar bool
+}
+
+// newTable reads the first entry of r to lay out a table, returning a reader
+// that replays it
+func newTable(r dsio.EntryReader) (*table, dsio.EntryReader, error) {
+	first, err := r.ReadEntry()
+	if err != nil {
+		if err.Error() == "EOF" {
+			return &table{cols: columns(r, nil)}, r, nil
+		}
+		return nil, nil, err
+	}
+
+	t := &table{cols: columns(r, first.Value), keyed: first.Key != ""}
+	if len(t.cols) == 0 {
+		t.scalar = true
+		t.cols = []render.Column{{Title: "value", Type: valueType(first.Value)}}
+	}
+	return t, &replayReader{EntryReader: r, first: &first}, nil
+}
+
+func columns(r dsio.EntryReader, first interface{}) []render.Column {
+	var schema interface{}
+	if st := r.Structure(); st != nil && st.Schema != nil {
+		schema = st.Schema
+	}
+	var body []interface{}
+	if first != nil {
+		body = []interface{}{first}
+	}
+	return render.SchemaColumns(schema, body)
+}
+
+// titles lists column titles, including the key column of keyed tables
+func (t *table) titles() []string {
+	titles := make([]string, 0, len(t.cols)+1)
+	if t.keyed {
+		titles = append(titles, "key")
+	}
+	for _, c := range t.cols {
+		titles = append(titles, c.Title)
+	}
+	return titles
+}
+
+// types lists column types, including the key column of keyed tables
+func (t *table) types() []string {
+	types := make([]string, 0, len(t.cols)+1)
+	if t.keyed {
+		types = append(types, "string")
+	}
+	for _, c := range t.cols {
+		types = append(types, c.Type)
+	}
+	return types
+}
+
+// row picks the values of an entry for each column, missing values are nil
+func (t *table) row(ent dsio.Entry) []interface{} {
+	row := make([]interface{}, 0, len(t.cols)+1)
+	if t.keyed {
+		row = append(row, ent.Key)
+	}
+	if t.scalar {
+		return append(row, ent.Value)
+	}
+
+	for _, c := range t.cols {
+		var v interface{}
+		switch r := ent.Value.(type) {
+		case []interface{}:
+			if !c.Object && c.Index < len(r) {
+				v = r[c.Index]
+			}
+		case map[string]interface{}:
+			v = r[c.Title]
+		}
+		row = append(row, v)
+	}
+	return row
+}
+
+// replayReader returns an entry that's already been read before reading on
+type replayReader struct {
+	dsio.EntryReader
+	first *dsio.Entry
+}
+
+// ReadEntry implements the dsio.EntryReader interface
+func (r *replayReader) ReadEntry() (dsio.Entry, error) {
+	if r.first != nil {
+		ent := *r.first
+		r.first = nil
+		return ent, nil
+	}
+	return r.EntryReader.ReadEntry()
+}
+
+// eachEntry calls fn with every entry in r
+func eachEntry(r dsio.EntryReader, fn func(ent dsio.Entry) error) error {
+	for {
+		ent, err := r.ReadEntry()
+		if err != nil {
+			if err.Error() == "EOF" {
+				return nil
+			}
+			return err
+		}
+		if err := fn(ent); err != nil {
+			return err
+		}
+	}
+}
+
+// valueType returns the jsonschema type name of a value
+func valueType(v interface{}) string {
+	switch x := v.(type) {
+	case bool:
+		return "boolean"
+	case float64:
+		if x == float64(int64(x)) {
+			return "integer"
+		}
+		return "number"
+	case int, int64:
+		return "integer"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return ""
+}
+
+// typedValue coerces a value to a column type, returning nil for values that
+// don't fit. Objects & arrays in columns that aren't typed as objects or
+// arrays are encoded as JSON strings
+func typedValue(typ string, v interface{}) interface{} {
+	switch typ {
+	case "integer":
+		switch x := v.(type) {
+		case float64:
+			if x == float64(int64(x)) {
+				return int64(x)
+			}
+		case int:
+			return int64(x)
+		case int64:
+			return x
+		}
+		return nil
+	case "number":
+		switch x := v.(type) {
+		case float64:
+			return x
+		case int:
+			return float64(x)
+		case int64:
+			return float64(x)
+		}
+		return nil
+	case "boolean":
+		if b, ok := v.(bool); ok {
+			return b
+		}
+		return nil
+	case "string":
+		if s, ok := v.(string); ok {
+			return s
+		}
+	}
+
+	switch v.(type) {
+	case nil:
+		return nil
+	case []interface{}, map[string]interface{}:
+		return jsonString(v)
+	case string, bool, float64, int, int64:
+		if typ == "" {
+			return v
+		}
+	}
+	return jsonString(v)
+}
+
+// jsonString formats a value as text, strings are returned as-is & other
+// values are encoded as JSON
+func jsonString(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
@@ -0,0 +1,222 @@
+package export
+
+import (
+	"bytes"
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/cafs"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/jsonschema"
+	"github.com/qri-io/qri/ingest"
+)
+
+var citiesSchema = jsonschema.Must(`{
+  "type": "array",
+  "items": {
+    "type": "array",
+    "items": [
+      { "title": "city", "type": "string" },
+      { "title": "pop", "type": "integer" },
+      { "title": "avg_age", "type": "number" },
+      { "title": "in_usa", "type": "boolean" }
+    ]
+  }
+}`)
+
+const citiesBody = `[
+  ["toronto", 40000000, 55.5, false],
+  ["new york", 8500000, 44.4, true],
+  ["<chatham>", 35000, 65.25, true]
+]`
+
+func citiesReader(t *testing.T) dsio.EntryReader {
+	st := &dataset.Structure{Format: dataset.JSONDataFormat, Schema: citiesSchema}
+	r, err := dsio.NewEntryReader(st, strings.NewReader(citiesBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestWriteNDJSON(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := Write(FormatNDJSON, buf, citiesReader(t), "cities"); err != nil {
+		t.Fatal(err)
+	}
+	expect := `["toronto",40000000,55.5,false]
+["new york",8500000,44.4,true]
+["<chatham>",35000,65.25,true]
+`
+	if buf.String() != expect {
+		t.Errorf("output mismatch. expected:\n%s\ngot:\n%s", expect, buf.String())
+	}
+
+	st := &dataset.Structure{Format: dataset.JSONDataFormat, Schema: jsonschema.Must(`{"type":"object"}`)}
+	r, err := dsio.NewEntryReader(st, strings.NewReader(`{"a": 1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err := WriteNDJSON(buf, r); err != nil {
+		t.Fatal(err)
+	}
+	if expect := "{\"key\":\"a\",\"value\":1}\n"; buf.String() != expect {
+		t.Errorf("object output mismatch. expected:\n%s\ngot:\n%s", expect, buf.String())
+	}
+}
+
+func TestWriteXLSX(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := Write(FormatXLSX, buf, citiesReader(t), "cities"); err != nil {
+		t.Fatal(err)
+	}
+
+	// read the workbook back with the xlsx importer
+	f, _, err := ingest.Import(cafs.NewMemfileBytes("cities.xlsx", buf.Bytes()), ingest.Options{Sheet: "cities"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := "city,pop,avg_age,in_usa\ntoronto,40000000,55.5,false\nnew york,8500000,44.4,true\n<chatham>,35000,65.25,true\n"
+	if string(got) != expect {
+		t.Errorf("output mismatch. expected:\n%s\ngot:\n%s", expect, string(got))
+	}
+}
+
+func TestWriteXLSXLimits(t *testing.T) {
+	row := strings.TrimSuffix(strings.Repeat("0,", ingest.XLSXMaxCols+1), ",")
+	st := &dataset.Structure{Format: dataset.JSONDataFormat, Schema: dataset.BaseSchemaArray}
+	r, err := dsio.NewEntryReader(st, strings.NewReader("[["+row+"]]"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = WriteXLSX(&bytes.Buffer{}, r, "wide")
+	if err == nil || !strings.Contains(err.Error(), "more than the 16384 an xlsx sheet can hold") {
+		t.Errorf("expected a body wider than a sheet to error, got: %v", err)
+	}
+}
+
+func TestWriteParquet(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := Write(FormatParquet, buf, citiesReader(t), "cities"); err != nil {
+		t.Fatal(err)
+	}
+
+	// read the file back with the parquet importer
+	f, _, err := ingest.Import(cafs.NewMemfileBytes("cities.parquet", buf.Bytes()), ingest.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `[["toronto",40000000,55.5,false],["new york",8500000,44.4,true],["<chatham>",35000,65.25,true]]`
+	if string(got) != expect {
+		t.Errorf("output mismatch. expected:\n%s\ngot:\n%s", expect, string(got))
+	}
+}
+
+func TestSQLite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "qri_export_sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "datasets.sqlite")
+
+	db, err := OpenSQLite(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"cities", "more_cities"} {
+		if err := db.WriteTable(name, citiesReader(t)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// writing a table again replaces it
+	if err := db.WriteTable("cities", citiesReader(t)); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	for _, name := range []string{"cities", "more_cities"} {
+		var count int
+		if err := conn.QueryRow("SELECT count(*) FROM " + name).Scan(&count); err != nil {
+			t.Fatal(err)
+		}
+		if count != 3 {
+			t.Errorf("expected table %s to have 3 rows, got: %d", name, count)
+		}
+	}
+
+	var (
+		city        string
+		pop, inUSA  int64
+		avgAge      float64
+		createTable string
+	)
+	if err := conn.QueryRow(`SELECT city, pop, avg_age, in_usa FROM cities WHERE city = 'new york'`).Scan(&city, &pop, &avgAge, &inUSA); err != nil {
+		t.Fatal(err)
+	}
+	if pop != 8500000 || avgAge != 44.4 || inUSA != 1 {
+		t.Errorf("unexpected row values: %s, %d, %f, %d", city, pop, avgAge, inUSA)
+	}
+
+	if err := conn.QueryRow(`SELECT sql FROM sqlite_master WHERE name = 'cities'`).Scan(&createTable); err != nil {
+		t.Fatal(err)
+	}
+	expect := `CREATE TABLE "cities" ("city" TEXT, "pop" INTEGER, "avg_age" REAL, "in_usa" INTEGER)`
+	if createTable != expect {
+		t.Errorf("table schema mismatch. expected:\n%s\ngot:\n%s", expect, createTable)
+	}
+}
+
+func TestSQLiteNoColumns(t *testing.T) {
+	dir, err := ioutil.TempDir("", "qri_export_sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := OpenSQLite(filepath.Join(dir, "datasets.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	st := &dataset.Structure{Format: dataset.JSONDataFormat, Schema: dataset.BaseSchemaArray}
+	r, err := dsio.NewEntryReader(st, strings.NewReader("[]"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.WriteTable("empty", r); err == nil || !strings.Contains(err.Error(), "no columns") {
+		t.Errorf("expected a body without columns to error, got: %v", err)
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	cases := map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"}
+	for i, expect := range cases {
+		if got := columnName(i); got != expect {
+			t.Errorf("column %d name mismatch. expected: %s, got: %s", i, expect, got)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+
+	"github.com/qri-io/dataset/dsio"
+)
+
+// WriteNDJSON writes each entry as a line of JSON. Entries of object bodies
+// are written as {"key": key, "value": value} objects
+func WriteNDJSON(w io.Writer, r dsio.EntryReader) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false)
+	err := eachEntry(r, func(ent dsio.Entry) error {
+		if ent.Key != "" {
+			return enc.Encode(map[string]interface{}{"key": ent.Key, "value": ent.Value})
+		}
+		return enc.Encode(ent.Value)
+	})
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,113 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/qri-io/dataset/dsio"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// parquetRowGroupSize caps how much data parquet export buffers before
+// writing a row group
+const parquetRowGroupSize = 16 * 1024 * 1024
+
+// WriteParquet writes entries as parquet rows. Every column is optional &
+// typed from the body schema: integers as INT64, numbers as DOUBLE, booleans
+// as BOOLEAN & everything else as UTF8 strings, with objects & arrays
+// encoded as JSON. Values that don't match their column type are written
+// as nulls
+func WriteParquet(w io.Writer, r dsio.EntryReader) error {
+	t, r, err := newTable(r)
+	if err != nil {
+		return err
+	}
+
+	names := parquetNames(t.titles())
+	types := t.types()
+	schema, err := parquetSchema(names, types)
+	if err != nil {
+		return err
+	}
+
+	pw, err := writer.NewJSONWriterFromWriter(schema, w, 1)
+	if err != nil {
+		return fmt.Errorf("creating parquet writer: %s", err.Error())
+	}
+	pw.RowGroupSize = parquetRowGroupSize
+
+	err = eachEntry(r, func(ent dsio.Entry) error {
+		rec := map[string]interface{}{}
+		for i, v := range t.row(ent) {
+			// untyped columns are stored as strings
+			if v = typedValue(types[i], v); v != nil && parquetType(types[i]) == "BYTE_ARRAY" {
+				v = jsonString(v)
+			}
+			rec[names[i]] = v
+		}
+		data, err := json.Marshal(rec)
+		if err != nil {
+			return err
+		}
+		return pw.Write(string(data))
+	})
+	if err != nil {
+		return err
+	}
+	return pw.WriteStop()
+}
+
+func parquetSchema(names, types []string) (string, error) {
+	fields := make([]map[string]string, len(names))
+	for i, name := range names {
+		tag := fmt.Sprintf("name=%s, type=%s, repetitiontype=OPTIONAL", name, parquetType(types[i]))
+		if parquetType(types[i]) == "BYTE_ARRAY" {
+			tag += ", convertedtype=UTF8"
+		}
+		fields[i] = map[string]string{"Tag": tag}
+	}
+	data, err := json.Marshal(map[string]interface{}{
+		"Tag":    "name=parquet_go_root, repetitiontype=REQUIRED",
+		"Fields": fields,
+	})
+	return string(data), err
+}
+
+// parquetType maps a jsonschema type to a parquet physical type
+func parquetType(typ string) string {
+	switch typ {
+	case "integer":
+		return "INT64"
+	case "number":
+		return "DOUBLE"
+	case "boolean":
+		return "BOOLEAN"
+	}
+	return "BYTE_ARRAY"
+}
+
+var invalidParquetName = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// parquetNames makes column titles safe to use as parquet column names,
+// which can't contain the punctuation parquet schema tags are written with.
+// Names are kept unique
+func parquetNames(titles []string) []string {
+	names := make([]string, len(titles))
+	used := map[string]bool{}
+	for i, title := range titles {
+		name := invalidParquetName.ReplaceAllString(title, "_")
+		if name == "" || strings.Trim(name, "_") == "" {
+			name = "column_" + strconv.Itoa(i)
+		}
+		for base, n := name, 1; used[strings.ToLower(name)]; n++ {
+			name = base + "_" + strconv.Itoa(n)
+		}
+		used[strings.ToLower(name)] = true
+		names[i] = name
+	}
+	return names
+}
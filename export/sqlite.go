@@ -0,0 +1,123 @@
+package export
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/qri-io/dataset/dsio"
+	// register the sqlite3 database/sql driver
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// SQLite is a SQLite database file that dataset bodies are written to, one
+// table per body
+type SQLite struct {
+	db *sql.DB
+}
+
+// OpenSQLite opens a SQLite database file, creating it if it doesn't exist
+func OpenSQLite(path string) (*SQLite, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("opening sqlite database: %s", err.Error())
+	}
+	return &SQLite{db: db}, nil
+}
+
+// WriteTable writes entries from r to a table, replacing any existing table
+// with the same name. Column types are derived from the body schema:
+// integers & booleans are stored as INTEGER, numbers as REAL, strings as TEXT
+// and objects & arrays as JSON-encoded TEXT. All rows are inserted in a
+// single transaction. Bodies without any columns can't be written
+func (s *SQLite) WriteTable(name string, r dsio.EntryReader) error {
+	t, r, err := newTable(r)
+	if err != nil {
+		return err
+	}
+
+	titles, types := t.titles(), t.types()
+	if len(titles) == 0 {
+		return fmt.Errorf("can't write table %s: body schema has no columns", name)
+	}
+	cols := make([]string, len(titles))
+	params := make([]string, len(titles))
+	for i, title := range titles {
+		cols[i] = strings.TrimSpace(quoteIdent(title) + " " + sqliteType(types[i]))
+		params[i] = "?"
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	// rollback is a no-op after commit
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", quoteIdent(name))); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(fmt.Sprintf("CREATE TABLE %s (%s)", quoteIdent(name), strings.Join(cols, ", "))); err != nil {
+		return fmt.Errorf("creating table %s: %s", name, err.Error())
+	}
+
+	insert, err := tx.Prepare(fmt.Sprintf("INSERT INTO %s VALUES (%s)", quoteIdent(name), strings.Join(params, ", ")))
+	if err != nil {
+		return err
+	}
+	defer insert.Close()
+
+	err = eachEntry(r, func(ent dsio.Entry) error {
+		row := t.row(ent)
+		for i, v := range row {
+			row[i] = sqliteValue(types[i], v)
+		}
+		if _, err := insert.Exec(row...); err != nil {
+			return fmt.Errorf("inserting entry %d: %s", ent.Index, err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Close closes the database
+func (s *SQLite) Close() error {
+	return s.db.Close()
+}
+
+// sqliteType maps a jsonschema type to a SQLite column type. untyped columns
+// are left without a type, so they keep values as they're given
+func sqliteType(typ string) string {
+	switch typ {
+	case "integer", "boolean":
+		return "INTEGER"
+	case "number":
+		return "REAL"
+	case "string", "object", "array":
+		return "TEXT"
+	}
+	return ""
+}
+
+func sqliteValue(typ string, v interface{}) interface{} {
+	v = typedValue(typ, v)
+	if b, ok := v.(bool); ok {
+		if b {
+			return 1
+		}
+		return 0
+	}
+	return v
+}
+
+// quoteIdent quotes a SQL identifier like a table or column name
+func quoteIdent(name string) string {
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
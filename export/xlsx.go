@@ -0,0 +1,172 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/qri/ingest"
+)
+
+// maxSheetName is the longest sheet name excel accepts
+const maxSheetName = 31
+
+// WriteXLSX writes entries as rows of a single sheet workbook, with a header
+// row of column titles. Text is written inline so rows can be streamed
+// without building a shared strings table. Bodies larger than a sheet can
+// hold are an error
+func WriteXLSX(w io.Writer, r dsio.EntryReader, sheet string) error {
+	t, r, err := newTable(r)
+	if err != nil {
+		return err
+	}
+	if cols := len(t.titles()); cols > ingest.XLSXMaxCols {
+		return fmt.Errorf("body has %d columns, more than the %d an xlsx sheet can hold", cols, ingest.XLSXMaxCols)
+	}
+
+	zw := zip.NewWriter(w)
+	for _, part := range []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRels},
+		{"xl/workbook.xml", fmt.Sprintf(xlsxWorkbook, xmlEscape(sheetName(sheet)))},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+	} {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return err
+		}
+	}
+
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(f)
+	bw.WriteString(xml.Header)
+	bw.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	titles := t.titles()
+	header := make([]interface{}, len(titles))
+	for i, title := range titles {
+		header[i] = title
+	}
+	writeXLSXRow(bw, 1, header)
+
+	types := t.types()
+	rowNum := 1
+	err = eachEntry(r, func(ent dsio.Entry) error {
+		rowNum++
+		if rowNum > ingest.XLSXMaxRows {
+			// the header row takes one of the sheet's rows
+			return fmt.Errorf("body has more than the %d rows an xlsx sheet can hold", ingest.XLSXMaxRows-1)
+		}
+		row := t.row(ent)
+		for i, v := range row {
+			row[i] = typedValue(types[i], v)
+		}
+		writeXLSXRow(bw, rowNum, row)
+		// flush as we go, bufio errors are sticky
+		if bw.Buffered() > 64*1024 {
+			return bw.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	bw.WriteString(`</sheetData></worksheet>`)
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func writeXLSXRow(w *bufio.Writer, rowNum int, values []interface{}) {
+	fmt.Fprintf(w, `<row r="%d">`, rowNum)
+	for i, v := range values {
+		ref := columnName(i) + strconv.Itoa(rowNum)
+		switch x := v.(type) {
+		case nil:
+			continue
+		case bool:
+			b := 0
+			if x {
+				b = 1
+			}
+			fmt.Fprintf(w, `<c r="%s" t="b"><v>%d</v></c>`, ref, b)
+		case int64:
+			fmt.Fprintf(w, `<c r="%s"><v>%d</v></c>`, ref, x)
+		case float64:
+			fmt.Fprintf(w, `<c r="%s"><v>%s</v></c>`, ref, strconv.FormatFloat(x, 'f', -1, 64))
+		default:
+			fmt.Fprintf(w, `<c r="%s" t="inlineStr"><is><t>%s</t></is></c>`, ref, xmlEscape(fmt.Sprint(x)))
+		}
+	}
+	w.WriteString(`</row>`)
+}
+
+// columnName converts a zero-based column index to a spreadsheet column name,
+// eg: 0 -> "A", 27 -> "AB"
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// sheetName makes a valid sheet name, excel doesn't allow []:*?/\ characters
+// or names longer than 31 characters
+func sheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if runes := []rune(name); len(runes) > maxSheetName {
+		name = string(runes[:maxSheetName])
+	}
+	if name == "" {
+		return "body"
+	}
+	return name
+}
+
+func xmlEscape(s string) string {
+	b := &strings.Builder{}
+	xml.EscapeText(b, []byte(s))
+	return b.String()
+}
+
+const xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+</Types>`
+
+const xlsxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+
+const xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`
+
+const xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+</Relationships>`
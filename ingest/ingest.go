@@ -0,0 +1,68 @@
+// Package ingest converts body files in formats qri can't store directly
+// (xlsx, NDJSON, parquet) into formats it can (csv, json). Importers are
+// chosen by file extension
+package ingest
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/qri-io/cafs"
+	"github.com/qri-io/dataset"
+)
+
+// Options configures an import
+type Options struct {
+	// Sheet selects a sheet from a workbook by name. Defaults to the first
+	// sheet
+	Sheet string
+}
+
+// Importer converts a body file to a supported data format
+type Importer interface {
+	// Import reads a file, returning a file in a supported format. Importers
+	// that know the types of their input return a structure for the result,
+	// a nil structure means the structure should be detected from the result
+	Import(f cafs.File, opts Options) (cafs.File, *dataset.Structure, error)
+}
+
+// importers maps lowercase file extensions to importers
+var importers = map[string]Importer{
+	".xlsx":    XLSXImporter{},
+	".ndjson":  NDJSONImporter{},
+	".jsonl":   NDJSONImporter{},
+	".parquet": ParquetImporter{},
+}
+
+// Register adds an importer for a file extension, replacing any existing
+// importer for the extension
+func Register(ext string, imp Importer) {
+	importers[strings.ToLower(ext)] = imp
+}
+
+// Lookup gets the importer for a filename, returning false if files with the
+// name's extension don't need importing
+func Lookup(filename string) (Importer, bool) {
+	imp, ok := importers[strings.ToLower(filepath.Ext(filename))]
+	return imp, ok
+}
+
+// Import converts f if an importer is registered for its extension. Files
+// that don't need importing are returned unchanged, with a nil structure
+func Import(f cafs.File, opts Options) (cafs.File, *dataset.Structure, error) {
+	imp, ok := Lookup(f.FileName())
+	if !ok {
+		return f, nil, nil
+	}
+	file, st, err := imp.Import(f, opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("importing %s: %s", f.FileName(), err.Error())
+	}
+	return file, st, nil
+}
+
+// convertedName swaps the extension of a filename for a data format
+func convertedName(filename string, df dataset.DataFormat) string {
+	return fmt.Sprintf("%s.%s", strings.TrimSuffix(filename, filepath.Ext(filename)), df.String())
+}
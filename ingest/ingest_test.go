@@ -0,0 +1,244 @@
+package ingest
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/qri-io/cafs"
+	"github.com/qri-io/dataset"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+func TestLookup(t *testing.T) {
+	for _, name := range []string{"data.xlsx", "DATA.XLSX", "data.ndjson", "data.jsonl", "data.parquet"} {
+		if _, ok := Lookup(name); !ok {
+			t.Errorf("expected importer for '%s'", name)
+		}
+	}
+	for _, name := range []string{"data.csv", "data.json", "data"} {
+		if _, ok := Lookup(name); ok {
+			t.Errorf("expected no importer for '%s'", name)
+		}
+	}
+}
+
+func TestImportPassthrough(t *testing.T) {
+	f := cafs.NewMemfileBytes("body.csv", []byte("a,b\n1,2\n"))
+	got, st, err := Import(f, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != f || st != nil {
+		t.Errorf("expected supported formats to pass through unchanged")
+	}
+}
+
+func TestNDJSONImporter(t *testing.T) {
+	body := "{\"city\":\"toronto\",\"pop\":40000000}\n\n{\"city\":\"new york\",\"pop\":8500000}\n"
+	f, st, err := Import(cafs.NewMemfileBytes("cities.ndjson", []byte(body)), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st != nil {
+		t.Errorf("expected ndjson structure to be left for detection")
+	}
+	if f.FileName() != "cities.json" {
+		t.Errorf("filename mismatch. expected: cities.json, got: %s", f.FileName())
+	}
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `[{"city":"toronto","pop":40000000},{"city":"new york","pop":8500000}]`
+	if string(data) != expect {
+		t.Errorf("body mismatch. expected:\n%s\ngot:\n%s", expect, string(data))
+	}
+
+	f, _, _ = Import(cafs.NewMemfileBytes("bad.ndjson", []byte("{\"a\":1}\n{nope")), Options{})
+	if _, err := ioutil.ReadAll(f); err == nil || err.Error() != "line 2: invalid JSON" {
+		t.Errorf("expected invalid line error, got: %v", err)
+	}
+}
+
+// testXLSX builds a minimal workbook with two sheets
+func testXLSX(t *testing.T) []byte {
+	files := map[string]string{
+		"xl/workbook.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+  <sheets>
+    <sheet name="cities" sheetId="1" r:id="rId1"/>
+    <sheet name="notes" sheetId="2" r:id="rId2"/>
+  </sheets>
+</workbook>`,
+		"xl/_rels/workbook.xml.rels": `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+  <Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+  <Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="/xl/worksheets/sheet2.xml"/>
+</Relationships>`,
+		"xl/sharedStrings.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+  <si><t>city</t></si>
+  <si><t>pop</t></si>
+  <si><r><t>toron</t></r><r><t>to</t></r></si>
+  <si><t>in_usa</t></si>
+</sst>`,
+		"xl/worksheets/sheet1.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+  <sheetData>
+    <row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="s"><v>3</v></c></row>
+    <row r="2"><c r="A2" t="s"><v>2</v></c><c r="B2"><v>40000000</v></c><c r="C2" t="b"><v>0</v></c></row>
+    <row r="4"><c r="A4" t="inlineStr"><is><t>new york</t></is></c><c r="C4" t="b"><v>1</v></c></row>
+  </sheetData>
+</worksheet>`,
+		"xl/worksheets/sheet2.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+  <sheetData>
+    <row r="1"><c r="A1" t="inlineStr"><is><t>note</t></is></c></row>
+  </sheetData>
+</worksheet>`,
+	}
+
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestXLSXImporter(t *testing.T) {
+	data := testXLSX(t)
+
+	cases := []struct {
+		sheet, expect, err string
+	}{
+		{"", "city,pop,in_usa\ntoronto,40000000,false\n,,\nnew york,,true\n", ""},
+		{"cities", "city,pop,in_usa\ntoronto,40000000,false\n,,\nnew york,,true\n", ""},
+		{"notes", "note\n", ""},
+		{"missing", "", "importing workbook.xlsx: sheet 'missing' not found. sheets are: cities, notes"},
+	}
+
+	for i, c := range cases {
+		f, st, err := Import(cafs.NewMemfileBytes("workbook.xlsx", data), Options{Sheet: c.sheet})
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%v'", i, c.err, err)
+			continue
+		}
+		if c.err != "" {
+			continue
+		}
+		if st != nil {
+			t.Errorf("case %d expected structure to be left for detection", i)
+		}
+		if f.FileName() != "workbook.csv" {
+			t.Errorf("case %d filename mismatch. expected: workbook.csv, got: %s", i, f.FileName())
+		}
+		got, err := ioutil.ReadAll(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != c.expect {
+			t.Errorf("case %d body mismatch. expected:\n%s\ngot:\n%s", i, c.expect, string(got))
+		}
+	}
+}
+
+func TestCellColumn(t *testing.T) {
+	cases := []struct {
+		ref    string
+		expect int
+		err    string
+	}{
+		{"A1", 0, ""},
+		{"AB12", 27, ""},
+		{"XFD1", 16383, ""},
+		{"XFE1", 0, "invalid cell reference: 'XFE1' is outside the sheet"},
+		{"ZZZZZZZZZZZZZZZ1", 0, "invalid cell reference: 'ZZZZZZZZZZZZZZZ1' is outside the sheet"},
+		{"12", 0, "invalid cell reference: '12'"},
+	}
+
+	for i, c := range cases {
+		got, err := cellColumn(c.ref)
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%v'", i, c.err, err)
+			continue
+		}
+		if got != c.expect {
+			t.Errorf("case %d column mismatch. expected: %d, got: %d", i, c.expect, got)
+		}
+	}
+}
+
+func TestParquetImporter(t *testing.T) {
+	schema := `{"Tag":"name=parquet_go_root, repetitiontype=REQUIRED","Fields":[
+    {"Tag":"name=city, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=REQUIRED"},
+    {"Tag":"name=pop, type=INT64, repetitiontype=OPTIONAL"},
+    {"Tag":"name=avg_age, type=DOUBLE, repetitiontype=REQUIRED"},
+    {"Tag":"name=in_usa, type=BOOLEAN, repetitiontype=REQUIRED"}
+  ]}`
+	buf := &bytes.Buffer{}
+	pw, err := writer.NewJSONWriterFromWriter(schema, buf, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range []string{
+		`{"city":"toronto","pop":40000000,"avg_age":55.5,"in_usa":false}`,
+		`{"city":"new york","pop":null,"avg_age":44.4,"in_usa":true}`,
+	} {
+		if err := pw.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := pw.WriteStop(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, st, err := Import(cafs.NewMemfileBytes("cities.parquet", buf.Bytes()), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.FileName() != "cities.json" {
+		t.Errorf("filename mismatch. expected: cities.json, got: %s", f.FileName())
+	}
+	if st == nil || st.Format != dataset.JSONDataFormat {
+		t.Fatalf("expected a json structure, got: %v", st)
+	}
+
+	data, err := json.Marshal(st.Schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sch := struct {
+		Items struct {
+			Items []map[string]interface{} `json:"items"`
+		} `json:"items"`
+	}{}
+	if err := json.Unmarshal(data, &sch); err != nil {
+		t.Fatal(err)
+	}
+	cols, _ := json.Marshal(sch.Items.Items)
+	expectCols := `[{"title":"city","type":"string"},{"title":"pop","type":["integer","null"]},{"title":"avg_age","type":"number"},{"title":"in_usa","type":"boolean"}]`
+	if string(cols) != expectCols {
+		t.Errorf("schema columns mismatch. expected:\n%s\ngot:\n%s", expectCols, string(cols))
+	}
+
+	if data, err = ioutil.ReadAll(f); err != nil {
+		t.Fatal(err)
+	}
+	expect := `[["toronto",40000000,55.5,false],["new york",null,44.4,true]]`
+	if string(data) != expect {
+		t.Errorf("body mismatch. expected:\n%s\ngot:\n%s", expect, string(data))
+	}
+}
@@ -0,0 +1,60 @@
+package ingest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/qri-io/cafs"
+	"github.com/qri-io/dataset"
+)
+
+// NDJSONImporter converts newline-delimited JSON to a JSON array, streaming
+// one line at a time. Blank lines are skipped
+type NDJSONImporter struct{}
+
+// Import implements the Importer interface
+func (NDJSONImporter) Import(f cafs.File, opts Options) (cafs.File, *dataset.Structure, error) {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(ndjsonToArray(pw, f))
+	}()
+	return cafs.NewMemfileReader(convertedName(f.FileName(), dataset.JSONDataFormat), pr), nil, nil
+}
+
+func ndjsonToArray(w io.Writer, r io.Reader) error {
+	sc := bufio.NewScanner(r)
+	// allow lines up to 64MB
+	sc.Buffer(make([]byte, 64*1024), 64*1024*1024)
+
+	if _, err := w.Write([]byte{'['}); err != nil {
+		return err
+	}
+	line, entries := 0, 0
+	for sc.Scan() {
+		line++
+		data := bytes.TrimSpace(sc.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		if !json.Valid(data) {
+			return fmt.Errorf("line %d: invalid JSON", line)
+		}
+		if entries > 0 {
+			if _, err := w.Write([]byte{','}); err != nil {
+				return err
+			}
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+		entries++
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	_, err := w.Write([]byte{']'})
+	return err
+}
@@ -0,0 +1,225 @@
+package ingest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/qri-io/cafs"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/jsonschema"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+)
+
+// parquetBatchSize is the number of rows read from a parquet file at a time
+const parquetBatchSize = 1000
+
+// ParquetImporter converts parquet files to a JSON array of rows. Column
+// titles & types are read from the parquet schema, so no detection is needed
+type ParquetImporter struct{}
+
+// Import implements the Importer interface
+func (ParquetImporter) Import(f cafs.File, opts Options) (cafs.File, *dataset.Structure, error) {
+	// parquet metadata is at the end of the file, which needs random access
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, nil, err
+	}
+	pr, err := reader.NewParquetReader(buffer.NewBufferFileFromBytes(data), nil, 1)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid parquet file: %s", err.Error())
+	}
+
+	cols := parquetColumns(pr)
+	st, err := parquetStructure(cols)
+	if err != nil {
+		pr.ReadStop()
+		return nil, nil, err
+	}
+
+	rd, wr := io.Pipe()
+	go func() {
+		defer pr.ReadStop()
+		wr.CloseWithError(parquetToJSON(wr, pr, cols))
+	}()
+	return cafs.NewMemfileReader(convertedName(f.FileName(), dataset.JSONDataFormat), rd), st, nil
+}
+
+// parquetColumn is a top level column of a parquet file
+type parquetColumn struct {
+	// title is the column name as written in the file, field is the name
+	// parquet-go gives the column in the rows it reads
+	title, field string
+	schema       *parquet.SchemaElement
+}
+
+// parquetColumns lists top level columns of a parquet file. Nested columns
+// are read as objects & arrays under their top level column
+func parquetColumns(pr *reader.ParquetReader) (cols []parquetColumn) {
+	elements := pr.Footer.Schema
+	if len(elements) == 0 {
+		return nil
+	}
+
+	// schema elements are a depth-first flattening of the schema tree, skip
+	// over the descendants of each top level column
+	var skip func(i int) int
+	skip = func(i int) int {
+		next := i + 1
+		for c := int32(0); c < elements[i].GetNumChildren(); c++ {
+			next = skip(next)
+		}
+		return next
+	}
+
+	for i := 1; i < len(elements); i = skip(i) {
+		cols = append(cols, parquetColumn{
+			title:  pr.SchemaHandler.GetExName(i),
+			field:  pr.SchemaHandler.GetInName(i),
+			schema: elements[i],
+		})
+	}
+	return cols
+}
+
+// parquetStructure describes parquet rows as a JSON array of arrays
+func parquetStructure(cols []parquetColumn) (*dataset.Structure, error) {
+	items := make([]interface{}, len(cols))
+	for i, c := range cols {
+		col := map[string]interface{}{"title": c.title}
+		if t := parquetType(c.schema); t != "" {
+			col["type"] = t
+			// optional columns hold nulls for missing values
+			if c.schema.GetRepetitionType() == parquet.FieldRepetitionType_OPTIONAL {
+				col["type"] = []interface{}{t, "null"}
+			}
+		}
+		items[i] = col
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"type":  "array",
+			"items": items,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	sch := &jsonschema.RootSchema{}
+	if err := sch.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+	return &dataset.Structure{Format: dataset.JSONDataFormat, Schema: sch}, nil
+}
+
+// parquetType maps a parquet column to a JSON schema type, returning "" for
+// columns that can hold any type
+func parquetType(el *parquet.SchemaElement) string {
+	if el.GetNumChildren() > 0 {
+		if el.GetRepetitionType() == parquet.FieldRepetitionType_REPEATED {
+			return "array"
+		}
+		// LIST & MAP groups have children, but aren't objects
+		if el.IsSetConvertedType() {
+			return ""
+		}
+		return "object"
+	}
+	if el.GetRepetitionType() == parquet.FieldRepetitionType_REPEATED {
+		return "array"
+	}
+
+	switch el.GetType() {
+	case parquet.Type_BOOLEAN:
+		return "boolean"
+	case parquet.Type_INT32, parquet.Type_INT64:
+		// decimals are stored as scaled integers
+		if el.IsSetConvertedType() && el.GetConvertedType() == parquet.ConvertedType_DECIMAL {
+			return ""
+		}
+		return "integer"
+	case parquet.Type_FLOAT, parquet.Type_DOUBLE:
+		return "number"
+	case parquet.Type_BYTE_ARRAY, parquet.Type_FIXED_LEN_BYTE_ARRAY, parquet.Type_INT96:
+		return "string"
+	}
+	return ""
+}
+
+// parquetToJSON streams parquet rows to w as a JSON array of arrays, in
+// batches of parquetBatchSize rows
+func parquetToJSON(w io.Writer, pr *reader.ParquetReader, cols []parquetColumn) error {
+	if _, err := w.Write([]byte{'['}); err != nil {
+		return err
+	}
+
+	total := int(pr.GetNumRows())
+	for read := 0; read < total; {
+		n := parquetBatchSize
+		if total-read < n {
+			n = total - read
+		}
+		rows, err := pr.ReadByNumber(n)
+		if err != nil {
+			return fmt.Errorf("reading rows %d-%d: %s", read, read+n, err.Error())
+		}
+
+		for _, row := range rows {
+			// rows are structs with a field for each column, round-trip through
+			// JSON to pick out columns in order
+			data, err := marshalJSON(row)
+			if err != nil {
+				return err
+			}
+			fields := map[string]json.RawMessage{}
+			if err := json.Unmarshal(data, &fields); err != nil {
+				return err
+			}
+			values := make([]json.RawMessage, len(cols))
+			for i, c := range cols {
+				if v, ok := fields[c.field]; ok {
+					values[i] = v
+				} else {
+					values[i] = json.RawMessage("null")
+				}
+			}
+			if data, err = marshalJSON(values); err != nil {
+				return err
+			}
+
+			if read > 0 {
+				if _, err := w.Write([]byte{','}); err != nil {
+					return err
+				}
+			}
+			if _, err := w.Write(data); err != nil {
+				return err
+			}
+			read++
+		}
+		if len(rows) == 0 {
+			break
+		}
+	}
+
+	_, err := w.Write([]byte{']'})
+	return err
+}
+
+// marshalJSON encodes v as JSON without escaping HTML characters, which
+// json.Marshal does by default
+func marshalJSON(v interface{}) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte{'\n'}), nil
+}
@@ -0,0 +1,296 @@
+package ingest
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/qri-io/cafs"
+	"github.com/qri-io/dataset"
+)
+
+const (
+	// XLSXMaxRows & XLSXMaxCols are the dimensions of the largest sheet
+	// Excel supports. rows & cells are placed by the positions they declare,
+	// so larger positions are refused rather than padded out to. Exports
+	// are held to the same limits
+	XLSXMaxRows = 1048576
+	XLSXMaxCols = 16384
+)
+
+// XLSXImporter converts a sheet of an Excel workbook to CSV. The first row
+// of the sheet is expected to be a header row. Cells are read as they're
+// stored, so dates come through as Excel serial numbers
+type XLSXImporter struct{}
+
+// Import implements the Importer interface
+func (XLSXImporter) Import(f cafs.File, opts Options) (cafs.File, *dataset.Structure, error) {
+	// xlsx files are zip archives, which need random access
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, nil, err
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid xlsx file: %s", err.Error())
+	}
+	wb, err := openWorkbook(zr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	if err := wb.writeCSV(buf, opts.Sheet); err != nil {
+		return nil, nil, err
+	}
+	return cafs.NewMemfileBytes(convertedName(f.FileName(), dataset.CSVDataFormat), buf.Bytes()), nil, nil
+}
+
+type workbook struct {
+	files   map[string]*zip.File
+	sheets  []xlsxSheet
+	rels    map[string]string
+	strings []string
+}
+
+type xlsxSheet struct {
+	Name string `xml:"name,attr"`
+	RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+}
+
+func openWorkbook(zr *zip.Reader) (*workbook, error) {
+	wb := &workbook{files: map[string]*zip.File{}, rels: map[string]string{}}
+	for _, f := range zr.File {
+		wb.files[f.Name] = f
+	}
+
+	book := struct {
+		Sheets []xlsxSheet `xml:"sheets>sheet"`
+	}{}
+	if err := wb.decode("xl/workbook.xml", &book); err != nil {
+		return nil, err
+	}
+	wb.sheets = book.Sheets
+
+	rels := struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}{}
+	if err := wb.decode("xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, err
+	}
+	for _, rel := range rels.Relationships {
+		// targets are relative to the xl directory, unless they're absolute
+		target := strings.TrimPrefix(rel.Target, "/")
+		if !strings.HasPrefix(rel.Target, "/") {
+			target = path.Join("xl", rel.Target)
+		}
+		wb.rels[rel.ID] = target
+	}
+
+	// workbooks without text cells don't have a shared strings table
+	if _, ok := wb.files["xl/sharedStrings.xml"]; ok {
+		sst := struct {
+			Items []struct {
+				T    string `xml:"t"`
+				Runs []struct {
+					T string `xml:"t"`
+				} `xml:"r"`
+			} `xml:"si"`
+		}{}
+		if err := wb.decode("xl/sharedStrings.xml", &sst); err != nil {
+			return nil, err
+		}
+		wb.strings = make([]string, len(sst.Items))
+		for i, si := range sst.Items {
+			s := si.T
+			for _, r := range si.Runs {
+				s += r.T
+			}
+			wb.strings[i] = s
+		}
+	}
+
+	return wb, nil
+}
+
+func (wb *workbook) open(name string) (io.ReadCloser, error) {
+	f, ok := wb.files[name]
+	if !ok {
+		return nil, fmt.Errorf("invalid xlsx file: missing %s", name)
+	}
+	return f.Open()
+}
+
+func (wb *workbook) decode(name string, v interface{}) error {
+	rc, err := wb.open(name)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("invalid xlsx file: reading %s: %s", name, err.Error())
+	}
+	return nil
+}
+
+// sheetPath finds the archive path of a sheet by name. An empty name selects
+// the first sheet
+func (wb *workbook) sheetPath(name string) (string, error) {
+	if len(wb.sheets) == 0 {
+		return "", fmt.Errorf("workbook has no sheets")
+	}
+	if name == "" {
+		return wb.rels[wb.sheets[0].RID], nil
+	}
+
+	names := make([]string, len(wb.sheets))
+	for i, s := range wb.sheets {
+		if s.Name == name {
+			return wb.rels[s.RID], nil
+		}
+		names[i] = s.Name
+	}
+	return "", fmt.Errorf("sheet '%s' not found. sheets are: %s", name, strings.Join(names, ", "))
+}
+
+type xlsxCell struct {
+	Ref    string `xml:"r,attr"`
+	Type   string `xml:"t,attr"`
+	Value  string `xml:"v"`
+	Inline struct {
+		T string `xml:"t"`
+	} `xml:"is"`
+}
+
+// writeCSV writes the rows of a sheet to w as CSV. Missing cells & rows are
+// written as empty values, and every row is padded to the width of the
+// widest row so all records have the same number of fields
+func (wb *workbook) writeCSV(w io.Writer, sheet string) error {
+	name, err := wb.sheetPath(sheet)
+	if err != nil {
+		return err
+	}
+	rc, err := wb.open(name)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	var (
+		records [][]string
+		width   int
+		dec     = xml.NewDecoder(rc)
+	)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("invalid xlsx file: reading %s: %s", name, err.Error())
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+		row := struct {
+			Num   int        `xml:"r,attr"`
+			Cells []xlsxCell `xml:"c"`
+		}{}
+		if err := dec.DecodeElement(&row, &start); err != nil {
+			return fmt.Errorf("invalid xlsx file: reading %s: %s", name, err.Error())
+		}
+
+		// the row number attribute is optional, rows are sequential without it
+		if row.Num == 0 {
+			row.Num = len(records) + 1
+		}
+		if row.Num < 0 || row.Num > XLSXMaxRows {
+			return fmt.Errorf("invalid xlsx file: row %d is outside the sheet", row.Num)
+		}
+		for len(records) < row.Num-1 {
+			records = append(records, nil)
+		}
+
+		var record []string
+		for i, c := range row.Cells {
+			col := i
+			if c.Ref != "" {
+				if col, err = cellColumn(c.Ref); err != nil {
+					return err
+				}
+			}
+			if col >= XLSXMaxCols {
+				return fmt.Errorf("invalid xlsx file: row %d has more than %d columns", row.Num, XLSXMaxCols)
+			}
+			for len(record) <= col {
+				record = append(record, "")
+			}
+			if record[col], err = wb.cellValue(c); err != nil {
+				return fmt.Errorf("cell %s: %s", c.Ref, err.Error())
+			}
+		}
+		if len(record) > width {
+			width = len(record)
+		}
+		records = append(records, record)
+	}
+
+	cw := csv.NewWriter(w)
+	for _, record := range records {
+		for len(record) < width {
+			record = append(record, "")
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (wb *workbook) cellValue(c xlsxCell) (string, error) {
+	switch c.Type {
+	case "s":
+		i, err := strconv.Atoi(c.Value)
+		if err != nil || i < 0 || i >= len(wb.strings) {
+			return "", fmt.Errorf("invalid shared string index: '%s'", c.Value)
+		}
+		return wb.strings[i], nil
+	case "inlineStr":
+		return c.Inline.T, nil
+	case "b":
+		return strconv.FormatBool(c.Value == "1"), nil
+	}
+	return c.Value, nil
+}
+
+// cellColumn reads the zero-based column index from a cell reference like
+// "AB12"
+func cellColumn(ref string) (int, error) {
+	col := 0
+	for i, r := range ref {
+		if r >= 'A' && r <= 'Z' {
+			col = col*26 + int(r-'A'+1)
+			if col > XLSXMaxCols {
+				return 0, fmt.Errorf("invalid cell reference: '%s' is outside the sheet", ref)
+			}
+			continue
+		}
+		if i == 0 {
+			break
+		}
+		return col - 1, nil
+	}
+	return 0, fmt.Errorf("invalid cell reference: '%s'", ref)
+}
@@ -105,11 +105,15 @@ func SetConfig(res *config.Config) error {
 	if err := res.Validate(); err != nil {
 		return fmt.Errorf("error validating config: %s", err)
 	}
+
+	// SaveConfig writes Config, so set it before saving, restoring the previous
+	// config if saving fails
+	prev := Config
+	Config = res.WithPrivateValues(Config)
 	if err := SaveConfig(); err != nil {
+		Config = prev
 		return fmt.Errorf("error saving config: %s", err)
 	}
 
-	Config = res.WithPrivateValues(Config)
-
 	return nil
 }
@@ -1,12 +1,14 @@
 package lib
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/rpc"
+	"os"
 
 	"github.com/ipfs/go-datastore"
 	"github.com/qri-io/cafs"
@@ -18,9 +20,13 @@ import (
 	"github.com/qri-io/dsdiff"
 	"github.com/qri-io/jsonschema"
 	"github.com/qri-io/qri/actions"
+	"github.com/qri-io/qri/chunk"
+	"github.com/qri-io/qri/compress"
+	"github.com/qri-io/qri/ingest"
 	"github.com/qri-io/qri/p2p"
 	"github.com/qri-io/qri/repo"
 	"github.com/qri-io/qri/repo/profile"
+	"github.com/qri-io/qri/validation"
 	"github.com/qri-io/varName"
 )
 
@@ -218,12 +224,185 @@ type SaveParams struct {
 	Dataset *dataset.DatasetPod // dataset to create
 	Private bool                // option to make dataset private. private data is not currently implimented, see https://github.com/qri-io/qri/issues/291 for updates
 	Publish bool
+	// ValidationPolicy overrides the configured validation policy for this
+	// save, one of "strict", "warn", or "off"
+	ValidationPolicy string
+	// AllowBreakingSchemaChanges saves schema changes that aren't
+	// backward-compatible, even if the repo is configured to block them
+	AllowBreakingSchemaChanges bool
+	// Sheet selects a sheet by name when importing a body from a workbook,
+	// defaulting to the first sheet
+	Sheet string
+	// Compression overrides the configured body compression for this save,
+	// one of "none", "gzip", or "zstd"
+	Compression string
+	// ChunkSize overrides the configured body chunk size in bytes for this
+	// save. zero uses the configured size, a negative size stores the body
+	// whole
+	ChunkSize int
+	// Refresh re-fetches the body from the url it was last downloaded from,
+	// or Dataset.BodyPath if set. The request is conditional, and save
+	// returns repo.ErrBodyNotModified without committing if the body
+	// hasn't changed
+	Refresh bool
+	// NoTransformCache always executes the dataset's transform, instead of
+	// reusing a cached result for the same inputs
+	NoTransformCache bool
+}
+
+// SaveResponse carries the result of New & Save over rpc. rpc calls drop
+// error types, so saves refused by a validation policy or for breaking
+// schema changes are described in the response instead, and turned back
+// into errors by the client
+type SaveResponse struct {
+	Ref repo.DatasetRef
+	// PolicyError is set when a validation policy refused the body
+	PolicyError *validation.PolicyError
+	// SchemaChangeError is set when a save was refused for breaking schema
+	// changes
+	SchemaChangeError *validation.SchemaChangeError
+}
+
+// setErr records err in the response if it's a refusal, returning any other
+// error
+func (res *SaveResponse) setErr(err error) error {
+	switch e := err.(type) {
+	case validation.PolicyError:
+		res.PolicyError = &e
+		return nil
+	case validation.SchemaChangeError:
+		res.SchemaChangeError = &e
+		return nil
+	}
+	return err
+}
+
+// Err gives the error a save was refused with, nil if the save wasn't refused
+func (res *SaveResponse) Err() error {
+	if res.PolicyError != nil {
+		return *res.PolicyError
+	}
+	if res.SchemaChangeError != nil {
+		return *res.SchemaChangeError
+	}
+	return nil
+}
+
+// NewRPC is New for rpc clients, describing refusals in the response. use
+// New, which calls NewRPC when connected over rpc
+func (r *DatasetRequests) NewRPC(p *SaveParams, res *SaveResponse) error {
+	if r.cli != nil {
+		return r.cli.Call("DatasetRequests.NewRPC", p, res)
+	}
+	return res.setErr(r.New(p, &res.Ref))
+}
+
+// SaveRPC is Save for rpc clients, describing refusals in the response. use
+// Save, which calls SaveRPC when connected over rpc
+func (r *DatasetRequests) SaveRPC(p *SaveParams, res *SaveResponse) error {
+	if r.cli != nil {
+		return r.cli.Call("DatasetRequests.SaveRPC", p, res)
+	}
+	return res.setErr(r.Save(p, &res.Ref))
+}
+
+// ValidationPolicy resolves the validation policy for a save, preferring an
+// explicit policy, then configured policies for the dataset & repo
+func ValidationPolicy(policy, peername, name string) (validation.Policy, error) {
+	if policy == "" && Config != nil && Config.Repo != nil {
+		policy = Config.Repo.ValidationPolicy(peername, name)
+	}
+	p, err := validation.ParsePolicy(policy)
+	if err != nil {
+		return p, NewError(ErrBadArgs, err.Error())
+	}
+	return p, nil
+}
+
+// BodyCompression resolves the body compression for a save, preferring an
+// explicit compression, then configured compression for the dataset & repo
+func BodyCompression(compression, peername, name string) (compress.Format, error) {
+	if compression == "" && Config != nil && Config.Repo != nil {
+		compression = Config.Repo.BodyCompression(peername, name)
+	}
+	f, err := compress.ParseFormat(compression)
+	if err != nil {
+		return f, NewError(ErrBadArgs, err.Error())
+	}
+	return f, nil
+}
+
+// BodyChunkSize resolves the body chunk size for a save, preferring an
+// explicit size, then configured sizes for the dataset & repo. A result of
+// zero stores the body whole
+func BodyChunkSize(size int, peername, name string) int {
+	if size == 0 && Config != nil && Config.Repo != nil {
+		size = Config.Repo.BodyChunkSize(peername, name)
+	}
+	if size < 0 {
+		return 0
+	}
+	return size
+}
+
+// importBody converts body files in formats qri can't store directly, like
+// xlsx or parquet, to a supported format. The structure of converted bodies is
+// returned, nil if no conversion was needed
+func importBody(f cafs.File, sheet string) (cafs.File, *dataset.Structure, error) {
+	if _, ok := ingest.Lookup(f.FileName()); !ok {
+		return f, nil, nil
+	}
+
+	file, st, err := ingest.Import(f, ingest.Options{Sheet: sheet})
+	if err != nil {
+		return nil, nil, err
+	}
+	if st != nil {
+		return file, st, nil
+	}
+
+	df, err := detect.ExtensionDataFormat(file.FileName())
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid data format: %s", err.Error())
+	}
+	// use a TeeReader that writes to a buffer to preserve data
+	buf := &bytes.Buffer{}
+	if st, _, err = detect.FromReader(df, io.TeeReader(file, buf)); err != nil {
+		return nil, nil, fmt.Errorf("determining dataset schema: %s", err.Error())
+	}
+	// glue whatever we just read back onto the reader
+	return cafs.NewMemfileReader(file.FileName(), io.MultiReader(buf, file)), st, nil
+}
+
+// checkSchemaChanges refuses breaking changes between the schemas of two
+// versions of a dataset when the repo is configured to block them
+func checkSchemaChanges(prev, next *dataset.Dataset, allowBreaking bool) error {
+	if allowBreaking || Config == nil || Config.Repo == nil || !Config.Repo.BlockBreakingSchemaChanges {
+		return nil
+	}
+	if prev.Structure == nil || next.Structure == nil {
+		return nil
+	}
+
+	changes, err := validation.CompareSchemas(prev.Structure.Schema, next.Structure.Schema)
+	if err != nil {
+		return fmt.Errorf("comparing schemas: %s", err.Error())
+	}
+	if breaking := validation.BreakingChanges(changes); len(breaking) > 0 {
+		return validation.SchemaChangeError{Changes: breaking}
+	}
+	return nil
 }
 
 // New creates a new qri dataset from a source of data
 func (r *DatasetRequests) New(p *SaveParams, res *repo.DatasetRef) (err error) {
 	if r.cli != nil {
-		return r.cli.Call("DatasetRequests.New", p, res)
+		sres := &SaveResponse{}
+		if err := r.NewRPC(p, sres); err != nil {
+			return err
+		}
+		*res = sres.Ref
+		return sres.Err()
 	}
 
 	var (
@@ -273,6 +452,14 @@ func (r *DatasetRequests) New(p *SaveParams, res *repo.DatasetRef) (err error) {
 			return fmt.Errorf("invalid name: %s", err.Error())
 		}
 
+		var st *dataset.Structure
+		if dataFile, st, err = importBody(dataFile, p.Sheet); err != nil {
+			return err
+		}
+		if ds.Structure == nil && st != nil {
+			ds.Structure = st
+		}
+
 		// read structure from InitParams, or detect from data
 		if ds.Structure == nil && ds.Transform == nil {
 			// use a TeeReader that writes to a buffer to preserve data
@@ -312,7 +499,29 @@ func (r *DatasetRequests) New(p *SaveParams, res *repo.DatasetRef) (err error) {
 		return err
 	}
 
-	*res, err = r.repo.CreateDataset(dsp.Name, ds, dataFile, secrets, true)
+	pro, err := r.repo.Profile()
+	if err != nil {
+		return err
+	}
+	policy, err := ValidationPolicy(p.ValidationPolicy, pro.Peername, dsp.Name)
+	if err != nil {
+		return err
+	}
+	compression, err := BodyCompression(p.Compression, pro.Peername, dsp.Name)
+	if err != nil {
+		return err
+	}
+
+	chunkSize := BodyChunkSize(p.ChunkSize, pro.Peername, dsp.Name)
+
+	*res, err = r.repo.CreateDataset(dsp.Name, ds, dataFile, actions.CreateDatasetOptions{
+		Secrets:          secrets,
+		Pin:              true,
+		Policy:           policy,
+		Compression:      compression,
+		ChunkSize:        chunkSize,
+		NoTransformCache: p.NoTransformCache,
+	})
 	if err != nil {
 		log.Debugf("error creating dataset: %s\n", err.Error())
 		return err
@@ -342,7 +551,12 @@ func (r *DatasetRequests) New(p *SaveParams, res *repo.DatasetRef) (err error) {
 // but still use the hash to add to dataset.BodyPath
 func (r *DatasetRequests) Save(p *SaveParams, res *repo.DatasetRef) (err error) {
 	if r.cli != nil {
-		return r.cli.Call("DatasetRequests.Save", p, res)
+		sres := &SaveResponse{}
+		if err := r.SaveRPC(p, sres); err != nil {
+			return err
+		}
+		*res = sres.Ref
+		return sres.Err()
 	}
 
 	if p.Private {
@@ -382,18 +596,39 @@ func (r *DatasetRequests) Save(p *SaveParams, res *repo.DatasetRef) (err error)
 		return fmt.Errorf("error getting previous dataset: %s", err.Error())
 	}
 
-	if dsp.BodyBytes != nil || dsp.BodyPath != "" {
+	var imported *dataset.Structure
+	if p.Refresh {
+		prevDs := &dataset.Dataset{}
+		if err := prevDs.Decode(prev.Dataset); err != nil {
+			return fmt.Errorf("error decoding previous dataset: %s", err)
+		}
+		if dsp.BodyPath == "" && prevDs.Meta != nil {
+			dsp.BodyPath = prevDs.Meta.DownloadPath
+		}
+		if !isURL(dsp.BodyPath) {
+			return NewError(ErrBadArgs, "refreshing requires a body url, or a dataset body that was downloaded from one")
+		}
+		if dataFile, err = repo.RefreshBodyFile(dsp, prevDs.Meta); err != nil {
+			return err
+		}
+		if dataFile, imported, err = importBody(dataFile, p.Sheet); err != nil {
+			return err
+		}
+	} else if dsp.BodyBytes != nil || dsp.BodyPath != "" {
 		dataFile, err = repo.DatasetPodBodyFile(dsp)
 		if err != nil {
 			return err
 		}
+		if dataFile, imported, err = importBody(dataFile, p.Sheet); err != nil {
+			return err
+		}
 	} else {
 		// load data cause we need something to compare the structure to
 		prevDs := &dataset.Dataset{}
 		if err := prevDs.Decode(prev.Dataset); err != nil {
 			return fmt.Errorf("error decoding previous dataset: %s", err)
 		}
-		dataFile, err = dsfs.LoadBody(r.Repo().Store(), prevDs)
+		dataFile, err = chunk.LoadBody(r.Repo().Store(), prevDs)
 		if err != nil {
 			return fmt.Errorf("error loading previous data from filestore: %s", err)
 		}
@@ -416,6 +651,35 @@ func (r *DatasetRequests) Save(p *SaveParams, res *repo.DatasetRef) (err error)
 	ds.Commit.Title = updates.Commit.Title
 	ds.Commit.Message = updates.Commit.Message
 
+	// fetching a body from a url records the response validators in dsp.Meta,
+	// which happens after updates are decoded
+	if isURL(dsp.BodyPath) && dsp.Meta != nil {
+		if ds.Meta == nil {
+			ds.Meta = &dataset.Meta{}
+		}
+		ds.Meta.DownloadPath = dsp.Meta.DownloadPath
+		for _, key := range []string{repo.MetaETag, repo.MetaLastModified} {
+			if v, ok := dsp.Meta.Meta()[key]; ok {
+				ds.Meta.Meta()[key] = v
+			} else {
+				delete(ds.Meta.Meta(), key)
+			}
+		}
+	}
+
+	// imported bodies are converted to a new format, keep the previous schema
+	// so changes to it can be checked
+	if imported != nil {
+		if ds.Structure == nil {
+			ds.Structure = &dataset.Structure{}
+		}
+		ds.Structure.Format = imported.Format
+		ds.Structure.FormatConfig = imported.FormatConfig
+		if ds.Structure.Schema == nil {
+			ds.Structure.Schema = imported.Schema
+		}
+	}
+
 	// Assign will assign any previous paths to the current paths
 	// the dsdiff (called in dsfs.CreateDataset), will compare the paths
 	// see that they are the same, and claim there are no differences
@@ -430,7 +694,29 @@ func (r *DatasetRequests) Save(p *SaveParams, res *repo.DatasetRef) (err error)
 	}
 	// ds.Viz.SetPath("")
 
-	ref, err := r.repo.CreateDataset(dsp.Name, ds, dataFile, secrets, true)
+	if err = checkSchemaChanges(prevds, ds, p.AllowBreakingSchemaChanges); err != nil {
+		return err
+	}
+
+	policy, err := ValidationPolicy(p.ValidationPolicy, prev.Peername, prev.Name)
+	if err != nil {
+		return err
+	}
+	compression, err := BodyCompression(p.Compression, prev.Peername, prev.Name)
+	if err != nil {
+		return err
+	}
+
+	chunkSize := BodyChunkSize(p.ChunkSize, prev.Peername, prev.Name)
+
+	ref, err := r.repo.CreateDataset(dsp.Name, ds, dataFile, actions.CreateDatasetOptions{
+		Secrets:          secrets,
+		Pin:              true,
+		Policy:           policy,
+		Compression:      compression,
+		ChunkSize:        chunkSize,
+		NoTransformCache: p.NoTransformCache,
+	})
 	if err != nil {
 		log.Debugf("create ds error: %s\n", err.Error())
 		return err
@@ -588,7 +874,12 @@ func (r *DatasetRequests) LookupBody(p *LookupParams, data *LookupResult) (err e
 		return err
 	}
 
-	file, err = dsfs.LoadBody(store, ds)
+	// chunked bodies can skip straight to the chunk holding the offset
+	offset := 0
+	if !p.All {
+		offset = p.Offset
+	}
+	file, skipped, err := chunk.LoadBodyAt(store, ds, offset)
 	if err != nil {
 		log.Debug(err.Error())
 		return err
@@ -614,7 +905,7 @@ func (r *DatasetRequests) LookupBody(p *LookupParams, data *LookupResult) (err e
 		rr = &dsio.PagedReader{
 			Reader: rr,
 			Limit:  p.Limit,
-			Offset: p.Offset,
+			Offset: p.Offset - skipped,
 		}
 	}
 	err = dsio.Copy(rr, buf)
@@ -654,6 +945,43 @@ func (r *DatasetRequests) Add(ref *repo.DatasetRef, res *repo.DatasetRef) (err e
 	return
 }
 
+// ImportParams defines parameters for importing a dataset archive
+type ImportParams struct {
+	// ArchivePath is the path to a zip archive written by qri export --zip
+	ArchivePath string
+}
+
+// Import adds a dataset & its full history from a zip archive. Every file is
+// re-hashed on the way in, so imported datasets have the same paths they had
+// in the repo they were exported from
+func (r *DatasetRequests) Import(p *ImportParams, res *repo.DatasetRef) (err error) {
+	if r.cli != nil {
+		return r.cli.Call("DatasetRequests.Import", p, res)
+	}
+
+	if p.ArchivePath == "" {
+		return NewError(ErrBadArgs, "please provide the path to a dataset archive")
+	}
+
+	f, err := os.Open(p.ArchivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	ref, err := r.repo.ImportArchive(f, fi.Size())
+	if err != nil {
+		return err
+	}
+	*res = ref
+	return nil
+}
+
 // ValidateDatasetParams defines paremeters for dataset
 // data validation
 type ValidateDatasetParams struct {
@@ -662,12 +990,21 @@ type ValidateDatasetParams struct {
 	DataFilename string
 	Data         io.Reader
 	Schema       io.Reader
+	// MaxErrors caps the number of errors reported, all errors are still
+	// counted in the report summary. zero uses validation.DefaultMaxErrors,
+	// a negative value reports all errors
+	MaxErrors int
 }
 
-// Validate gives a dataset of errors and issues for a given dataset
-func (r *DatasetRequests) Validate(p *ValidateDatasetParams, errors *[]jsonschema.ValError) (err error) {
+// detectSampleSize is the number of bytes read from the start of a body to
+// detect a schema when validating data without one
+const detectSampleSize = 64 * 1024
+
+// Validate gives a report of errors and issues for a given dataset. Bodies are
+// validated as a stream of entries, so bodies of any size can be validated
+func (r *DatasetRequests) Validate(p *ValidateDatasetParams, res *validation.Report) (err error) {
 	if r.cli != nil {
-		return r.cli.Call("DatasetRequests.Validate", p, errors)
+		return r.cli.Call("DatasetRequests.Validate", p, res)
 	}
 
 	if err = DefaultSelectedRef(r.repo.Repo, &p.Ref); err != nil {
@@ -693,7 +1030,7 @@ func (r *DatasetRequests) Validate(p *ValidateDatasetParams, errors *[]jsonschem
 	var (
 		st   = &dataset.Structure{}
 		ref  repo.DatasetRef
-		data []byte
+		body io.Reader
 	)
 
 	// if a dataset is specified, load it
@@ -716,24 +1053,32 @@ func (r *DatasetRequests) Validate(p *ValidateDatasetParams, errors *[]jsonschem
 	}
 
 	if p.Data != nil {
-		data, err = ioutil.ReadAll(p.Data)
-		if err != nil {
-			log.Debug(err.Error())
-			return fmt.Errorf("error reading data: %s", err.Error())
-		}
+		body = p.Data
 
-		// if no schema, detect one
+		// if no schema, detect one from the start of the body
 		if st.Schema == nil {
 			var df dataset.DataFormat
 			df, err = detect.ExtensionDataFormat(p.DataFilename)
 			if err != nil {
 				return fmt.Errorf("detecting data format: %s", err.Error())
 			}
-			str, _, e := detect.FromReader(df, bytes.NewBuffer(data))
+			buf := bufio.NewReaderSize(p.Data, detectSampleSize)
+			sample, e := buf.Peek(detectSampleSize)
+			if e != nil && e != io.EOF && e != bufio.ErrBufferFull {
+				return fmt.Errorf("error reading data: %s", e.Error())
+			}
+			// don't hand detection a partial row
+			if e == nil {
+				if i := bytes.LastIndexByte(sample, '\n'); i > 0 {
+					sample = sample[:i+1]
+				}
+			}
+			str, _, e := detect.FromReader(df, bytes.NewReader(sample))
 			if e != nil {
 				return fmt.Errorf("error detecting from reader: %s", e)
 			}
 			st = str
+			body = buf
 		}
 	}
 
@@ -751,34 +1096,41 @@ func (r *DatasetRequests) Validate(p *ValidateDatasetParams, errors *[]jsonschem
 		st.Schema = sch
 	}
 
-	if data == nil && ref.Dataset != nil {
+	if body == nil && ref.Dataset != nil {
 		ds, e := ref.DecodeDataset()
 		if e != nil {
 			log.Debug(e.Error())
 			return fmt.Errorf("error loading dataset data: %s", e.Error())
 		}
 
-		f, e := dsfs.LoadBody(r.repo.Store(), ds)
+		f, e := chunk.LoadBody(r.repo.Store(), ds)
 		if e != nil {
 			log.Debug(e.Error())
 			return fmt.Errorf("error loading dataset data: %s", e.Error())
 		}
-		data, err = ioutil.ReadAll(f)
-		if err != nil {
-			log.Debug(err.Error())
-			return fmt.Errorf("error loading dataset data: %s", err.Error())
-		}
+		defer f.Close()
+		body = f
 	}
 
-	er, err := dsio.NewEntryReader(st, bytes.NewBuffer(data))
+	er, err := dsio.NewEntryReader(st, body)
 	if err != nil {
 		log.Debug(err.Error())
 		return fmt.Errorf("error reading data: %s", err.Error())
 	}
 
-	*errors, err = validate.EntryReader(er)
+	maxErrors := p.MaxErrors
+	if maxErrors == 0 {
+		maxErrors = validation.DefaultMaxErrors
+	}
 
-	return
+	report, err := validation.EntryReader(er, st.Schema, maxErrors)
+	if err != nil {
+		log.Debug(err.Error())
+		return err
+	}
+
+	*res = *report
+	return nil
 }
 
 // DiffParams defines parameters for diffing two datasets with Diff
@@ -4,9 +4,14 @@ import (
 	"bytes"
 	"context"
 	"encoding/csv"
+	"encoding/gob"
 	"encoding/json"
+	"errors"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strconv"
 	"sync"
 	"testing"
@@ -18,11 +23,14 @@ import (
 	"github.com/qri-io/dataset/dsfs"
 	"github.com/qri-io/dataset/dstest"
 	"github.com/qri-io/dsdiff"
-	"github.com/qri-io/jsonschema"
+	"github.com/qri-io/qri/actions"
+	"github.com/qri-io/qri/chunk"
 	"github.com/qri-io/qri/p2p"
 	"github.com/qri-io/qri/p2p/test"
 	"github.com/qri-io/qri/repo"
+	"github.com/qri-io/qri/repo/profile"
 	testrepo "github.com/qri-io/qri/repo/test"
+	"github.com/qri-io/qri/validation"
 	regmock "github.com/qri-io/registry/regserver/mock"
 	"github.com/qri-io/skytf"
 )
@@ -83,6 +91,7 @@ func TestDatasetRequestsInit(t *testing.T) {
 		}, nil, "invalid dataset: structure: format is required"},
 		{&dataset.DatasetPod{BodyPath: jobsBodyPath, Commit: &dataset.CommitPod{}}, nil, ""},
 		{&dataset.DatasetPod{BodyPath: s.URL + "/data.json"}, nil, ""},
+		{&dataset.DatasetPod{Name: "cities_ndjson", BodyPath: "testdata/cities.ndjson"}, nil, ""},
 
 		// confirm input metadata overwrites transform metadata
 		{&dataset.DatasetPod{
@@ -228,6 +237,269 @@ sarnia,550000,55.65,false
 	}
 }
 
+func TestDatasetRequestsSaveSchemaChanges(t *testing.T) {
+	mr, err := testrepo.NewTestRepo(nil)
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err.Error())
+	}
+	req := NewDatasetRequests(mr, nil)
+
+	Config.Repo.BlockBreakingSchemaChanges = true
+	defer func() { Config.Repo.BlockBreakingSchemaChanges = false }()
+
+	// drop the avg_age & in_usa columns
+	dsp := &dataset.DatasetPod{
+		Peername: "me",
+		Name:     "cities",
+		Structure: &dataset.StructurePod{
+			Format:       "csv",
+			FormatConfig: map[string]interface{}{"headerRow": true},
+			Schema: map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "array",
+					"items": []interface{}{
+						map[string]interface{}{"title": "city", "type": "string"},
+						map[string]interface{}{"title": "pop", "type": "integer"},
+					},
+				},
+			},
+		},
+	}
+
+	err = req.Save(&SaveParams{Dataset: dsp}, &repo.DatasetRef{})
+	serr, ok := err.(validation.SchemaChangeError)
+	if !ok {
+		t.Fatalf("expected a SchemaChangeError, got: %v", err)
+	}
+	if len(serr.Changes) != 2 || serr.Changes[0].Column != "avg_age" || serr.Changes[1].Column != "in_usa" {
+		t.Errorf("unexpected breaking changes: %v", serr.Changes)
+	}
+
+	if err := req.Save(&SaveParams{Dataset: dsp, AllowBreakingSchemaChanges: true}, &repo.DatasetRef{}); err != nil {
+		t.Errorf("expected allowing breaking changes to save. got: %s", err)
+	}
+}
+
+func TestDatasetRequestsSaveCompressed(t *testing.T) {
+	mr, err := testrepo.NewTestRepo(nil)
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err.Error())
+	}
+	req := NewDatasetRequests(mr, nil)
+
+	prev := &repo.DatasetRef{}
+	if err := req.Get(&repo.DatasetRef{Peername: "peer", Name: "cities"}, prev); err != nil {
+		t.Fatal(err)
+	}
+	expect := &LookupResult{}
+	if err := req.LookupBody(&LookupParams{Path: prev.Path, Format: dataset.JSONDataFormat, All: true}, expect); err != nil {
+		t.Fatal(err)
+	}
+
+	dsp := &dataset.DatasetPod{
+		Peername: "me",
+		Name:     "cities",
+		Meta:     &dataset.Meta{Title: "compressed cities"},
+	}
+	err = req.Save(&SaveParams{Dataset: dsp, Compression: "lz4"}, &repo.DatasetRef{})
+	if libErr, ok := err.(Error); !ok || libErr.Message() != "invalid compression 'lz4', must be one of none, gzip, zstd" {
+		t.Errorf("expected invalid compression to error, got: %v", err)
+	}
+
+	res := &repo.DatasetRef{}
+	if err := req.Save(&SaveParams{Dataset: dsp, Compression: "gzip"}, res); err != nil {
+		t.Fatal(err)
+	}
+
+	// bodies are stored compressed
+	f, err := mr.Store().Get(datastore.NewKey(res.Dataset.BodyPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := ioutil.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(raw) < 2 || raw[0] != 0x1f || raw[1] != 0x8b {
+		t.Errorf("expected stored body to be gzipped")
+	}
+
+	// & read decompressed
+	got := &LookupResult{}
+	if err := req.LookupBody(&LookupParams{Path: res.Path, Format: dataset.JSONDataFormat, All: true}, got); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Data, expect.Data) {
+		t.Errorf("body mismatch. expected:\n%s\ngot:\n%s", string(expect.Data), string(got.Data))
+	}
+}
+
+func TestDatasetRequestsSaveChunked(t *testing.T) {
+	mr, err := testrepo.NewTestRepo(nil)
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err.Error())
+	}
+	req := NewDatasetRequests(mr, nil)
+
+	prev := &repo.DatasetRef{}
+	if err := req.Get(&repo.DatasetRef{Peername: "peer", Name: "movies"}, prev); err != nil {
+		t.Fatal(err)
+	}
+	expect := &LookupResult{}
+	if err := req.LookupBody(&LookupParams{Path: prev.Path, Format: dataset.JSONDataFormat, Limit: 10, Offset: 30}, expect); err != nil {
+		t.Fatal(err)
+	}
+
+	dsp := &dataset.DatasetPod{
+		Peername: "me",
+		Name:     "movies",
+		Meta:     &dataset.Meta{Title: "chunked movies"},
+	}
+	res := &repo.DatasetRef{}
+	if err := req.Save(&SaveParams{Dataset: dsp, ChunkSize: 256}, res); err != nil {
+		t.Fatal(err)
+	}
+
+	// bodies are stored as a manifest of chunks
+	ds, err := res.DecodeDataset()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := chunk.LoadManifest(mr.Store(), ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m == nil || len(m.Chunks) < 2 {
+		t.Fatalf("expected body to be stored in chunks")
+	}
+
+	// & paged reads skip to the chunk holding the offset
+	got := &LookupResult{}
+	if err := req.LookupBody(&LookupParams{Path: res.Path, Format: dataset.JSONDataFormat, Limit: 10, Offset: 30}, got); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Data, expect.Data) {
+		t.Errorf("body mismatch. expected:\n%s\ngot:\n%s", string(expect.Data), string(got.Data))
+	}
+}
+
+func TestDatasetRequestsSaveRefresh(t *testing.T) {
+	mr, err := testrepo.NewTestRepo(nil)
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err.Error())
+	}
+	req := NewDatasetRequests(mr, nil)
+
+	etag := `"v1"`
+	body := "city,pop,avg_age,in_usa\ntoronto,40000000,55.5,false\nnew york,8500000,44.4,true\n"
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Write([]byte(body))
+	}))
+	defer s.Close()
+
+	dsp := &dataset.DatasetPod{Peername: "me", Name: "cities", BodyPath: s.URL + "/cities.csv"}
+	res := &repo.DatasetRef{}
+	if err := req.Save(&SaveParams{Dataset: dsp}, res); err != nil {
+		t.Fatal(err)
+	}
+	ds, err := res.DecodeDataset()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ds.Meta == nil || ds.Meta.DownloadPath != s.URL+"/cities.csv" || ds.Meta.Meta()[repo.MetaETag] != etag {
+		t.Fatalf("expected source url & etag to be recorded in meta")
+	}
+
+	// unchanged bodies skip the commit
+	refreshed := &repo.DatasetRef{}
+	err = req.Save(&SaveParams{Dataset: &dataset.DatasetPod{Peername: "me", Name: "cities"}, Refresh: true}, refreshed)
+	if err != repo.ErrBodyNotModified {
+		t.Errorf("expected refreshing an unchanged body to return ErrBodyNotModified, got: %v", err)
+	}
+	current := &repo.DatasetRef{Peername: "me", Name: "cities"}
+	if err := repo.CanonicalizeDatasetRef(mr, current); err != nil {
+		t.Fatal(err)
+	}
+	if current.Path != res.Path {
+		t.Errorf("expected refresh of an unchanged body not to create a new version")
+	}
+
+	// changed bodies are saved
+	etag = `"v2"`
+	body += "chicago,300000,44.4,true\n"
+	if err := req.Save(&SaveParams{Dataset: &dataset.DatasetPod{Peername: "me", Name: "cities"}, Refresh: true}, refreshed); err != nil {
+		t.Fatal(err)
+	}
+	if refreshed.Path == res.Path {
+		t.Errorf("expected refresh of a changed body to create a new version")
+	}
+	if ds, err = refreshed.DecodeDataset(); err != nil {
+		t.Fatal(err)
+	}
+	if ds.Meta.Meta()[repo.MetaETag] != `"v2"` {
+		t.Errorf("expected new etag to be recorded, got: %v", ds.Meta.Meta()[repo.MetaETag])
+	}
+
+	err = req.Save(&SaveParams{Dataset: &dataset.DatasetPod{Peername: "peer", Name: "movies"}, Refresh: true}, &repo.DatasetRef{})
+	if libErr, ok := err.(Error); !ok || libErr.Message() != "refreshing requires a body url, or a dataset body that was downloaded from one" {
+		t.Errorf("expected refreshing a dataset without a body url to error, got: %v", err)
+	}
+}
+
+func TestSaveResponse(t *testing.T) {
+	res := &SaveResponse{}
+	if err := res.setErr(errors.New("oh noes")); err == nil {
+		t.Errorf("expected errors that aren't refusals to be returned")
+	}
+	if res.Err() != nil {
+		t.Errorf("expected response without a refusal to have no error")
+	}
+
+	perr := validation.PolicyError{
+		Policy: validation.PolicyStrict,
+		Report: &validation.Report{
+			Entries:  3,
+			ErrCount: 1,
+			Errors:   []validation.Error{{Row: 2, Value: []interface{}{"chatham"}, Rule: "minItems"}},
+		},
+	}
+	if err := res.setErr(perr); err != nil {
+		t.Fatalf("expected refusal to be recorded, got error: %s", err)
+	}
+
+	// responses cross rpc calls as gob
+	buf := &bytes.Buffer{}
+	if err := gob.NewEncoder(buf).Encode(res); err != nil {
+		t.Fatal(err.Error())
+	}
+	got := &SaveResponse{}
+	if err := gob.NewDecoder(buf).Decode(got); err != nil {
+		t.Fatal(err.Error())
+	}
+	gotErr, ok := got.Err().(validation.PolicyError)
+	if !ok {
+		t.Fatalf("expected response error to be a PolicyError, got: %v", got.Err())
+	}
+	if gotErr.Report.ErrCount != 1 || gotErr.Report.Errors[0].Rule != "minItems" {
+		t.Errorf("unexpected report: %v", gotErr.Report)
+	}
+
+	res = &SaveResponse{}
+	serr := validation.SchemaChangeError{Changes: []validation.SchemaChange{{Kind: validation.ChangeColumnRemoved, Column: "pop", Breaking: true}}}
+	if err := res.setErr(serr); err != nil {
+		t.Fatalf("expected refusal to be recorded, got error: %s", err)
+	}
+	if err, ok := res.Err().(validation.SchemaChangeError); !ok || len(err.Changes) != 1 {
+		t.Errorf("expected response error to be a SchemaChangeError, got: %v", res.Err())
+	}
+}
+
 func TestDatasetRequestsList(t *testing.T) {
 	var (
 		movies, counter, cities, craigslist, sitemap repo.DatasetRef
@@ -613,6 +885,66 @@ func TestDatasetRequestsAdd(t *testing.T) {
 	}
 }
 
+func TestDatasetRequestsImport(t *testing.T) {
+	mr, err := testrepo.NewTestRepo(nil)
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err.Error())
+	}
+	ref, err := mr.GetRef(repo.DatasetRef{Peername: "peer", Name: "movies"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "qri_test_import")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	archivePath := filepath.Join(dir, "movies.zip")
+	f, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := (actions.Dataset{mr}).WriteArchive(ref, f); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	dst, err := testrepo.NewTestRepoFromProfileID(profile.IDB58MustDecode("QmZePf5LeXow3RW5U1AgEiNbW46YnRGhZ7HPvm1UmPFPwt"), 1, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		p    *ImportParams
+		path string
+		err  string
+	}{
+		{&ImportParams{}, "", "please provide the path to a dataset archive"},
+		{&ImportParams{ArchivePath: filepath.Join(dir, "none.zip")}, "", "open " + filepath.Join(dir, "none.zip") + ": no such file or directory"},
+		{&ImportParams{ArchivePath: archivePath}, ref.Path, ""},
+	}
+
+	req := NewDatasetRequests(dst, nil)
+	for i, c := range cases {
+		got := &repo.DatasetRef{}
+		err := req.Import(c.p, got)
+
+		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
+			t.Errorf("case %d error mismatch: expected: %s, got: %s", i, c.err, err)
+			continue
+		}
+		if got.Path != c.path {
+			t.Errorf("case %d path mismatch. expected: '%s', got: '%s'", i, c.path, got.Path)
+		}
+	}
+
+	if _, err := dst.GetRef(repo.DatasetRef{Peername: "peer", Name: "movies"}); err != nil {
+		t.Errorf("expected imported dataset to be in repo: %s", err.Error())
+	}
+}
+
 func TestDatasetRequestsAddP2p(t *testing.T) {
 	// Matches what is used to generate the test peers.
 	datasets := []string{"movies", "cities", "counter", "craigslist", "sitemap"}
@@ -727,19 +1059,43 @@ Pirates of the Caribbean: At World's End ,foo
 
 	req := NewDatasetRequests(mr, nil)
 	for i, c := range cases {
-		got := []jsonschema.ValError{}
+		got := validation.Report{}
 		err := req.Validate(&c.p, &got)
 		if !(err == nil && c.err == "" || err != nil && err.Error() == c.err) {
 			t.Errorf("case %d error mismatch: expected: %s, got: %s", i, c.err, err.Error())
 			continue
 		}
 
-		if len(got) != c.numErrors {
-			t.Errorf("case %d error count mismatch. expected: %d, got: %d", i, c.numErrors, len(got))
-			t.Log(got)
+		if got.ErrCount != c.numErrors || len(got.Errors) != c.numErrors {
+			t.Errorf("case %d error count mismatch. expected: %d, got: %d", i, c.numErrors, got.ErrCount)
+			t.Log(got.Errors)
 			continue
 		}
 	}
+
+	dataf = cafs.NewMemfileBytes("data.csv", movieb)
+	schemaf = cafs.NewMemfileBytes("schema.json", schemaB)
+	got := validation.Report{}
+	if err := req.Validate(&ValidateDatasetParams{Schema: schemaf, DataFilename: "data.csv", Data: dataf}, &got); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(got.Errors) != 1 {
+		t.Fatalf("expected 1 error, got: %d", len(got.Errors))
+	}
+	if e := got.Errors[0]; e.Row != 2 || e.Column != "duration" || e.Value != "foo" || e.Rule != "type" {
+		t.Errorf("expected error to be located at row 2, column duration with value foo & rule type. got: %#v", e)
+	}
+	if got.Summary["type"] != 1 {
+		t.Errorf("expected summary to count 1 type error, got: %v", got.Summary)
+	}
+
+	got = validation.Report{}
+	if err := req.Validate(&ValidateDatasetParams{Ref: repo.DatasetRef{Peername: "me", Name: "movies"}, MaxErrors: 5}, &got); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(got.Errors) != 5 || got.ErrCount != 15 || !got.Truncated {
+		t.Errorf("expected errors to be capped at 5 of 15, got %d of %d", len(got.Errors), got.ErrCount)
+	}
 }
 
 func TestDatasetRequestsDiff(t *testing.T) {
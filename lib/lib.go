@@ -43,5 +43,8 @@ func Receivers(node *p2p.QriNode) []Requests {
 		NewSearchRequests(r, nil),
 		NewRenderRequests(r, nil),
 		NewSelectionRequests(r, nil),
+		NewScheduleRequests(r, nil),
+		NewTokenRequests(r, nil),
+		NewWebhookRequests(r, nil),
 	}
 }
@@ -0,0 +1,62 @@
+package lib
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/qri-io/qri/metrics"
+	"github.com/qri-io/qri/p2p"
+)
+
+// StoreSizeInterval is how long a measured store size is reused for.
+// measuring walks the store on disk, so it isn't done on every read
+var StoreSizeInterval = time.Minute
+
+// searchIndexSizer is implemented by repos that can report how many
+// documents are in their search index
+type searchIndexSizer interface {
+	SearchIndexSize() (uint64, error)
+}
+
+// RegisterMetrics records gauges describing the repo & store of node in the
+// default metrics registry
+func RegisterMetrics(node *p2p.QriNode) {
+	r := node.Repo
+
+	metrics.Default.NewGaugeFunc("qri_repo_refs", "number of dataset references in the repo", func() (float64, error) {
+		count, err := r.RefCount()
+		return float64(count), err
+	})
+
+	var (
+		lk       sync.Mutex
+		size     uint64
+		measured time.Time
+	)
+	metrics.Default.NewGaugeFunc("qri_store_size_bytes", "size of the IPFS store on disk", func() (float64, error) {
+		lk.Lock()
+		defer lk.Unlock()
+		if time.Since(measured) < StoreSizeInterval {
+			return float64(size), nil
+		}
+		ipfsn, err := node.IPFSNode()
+		if err != nil {
+			return 0, err
+		}
+		if size, err = ipfsn.Repo.GetStorageUsage(); err != nil {
+			return 0, err
+		}
+		measured = time.Now()
+		return float64(size), nil
+	})
+
+	metrics.Default.NewGaugeFunc("qri_search_index_docs", "number of documents in the repo search index", func() (float64, error) {
+		idx, ok := r.(searchIndexSizer)
+		if !ok {
+			return 0, fmt.Errorf("repo doesn't support search")
+		}
+		count, err := idx.SearchIndexSize()
+		return float64(count), err
+	})
+}
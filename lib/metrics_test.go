@@ -0,0 +1,45 @@
+package lib
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/metrics"
+	"github.com/qri-io/qri/p2p"
+	testrepo "github.com/qri-io/qri/repo/test"
+)
+
+func TestRegisterMetrics(t *testing.T) {
+	mr, err := testrepo.NewTestRepo(nil)
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err.Error())
+	}
+	node, err := p2p.NewQriNode(mr, func(c *config.P2P) { c.Enabled = false })
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	count, err := mr.RefCount()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	RegisterMetrics(node)
+	buf := &bytes.Buffer{}
+	if err := metrics.Default.WriteText(buf); err != nil {
+		t.Fatal(err.Error())
+	}
+	out := buf.String()
+
+	if expect := fmt.Sprintf("qri_repo_refs %d\n", count); !strings.Contains(out, expect) {
+		t.Errorf("expected output to contain '%s'. got:\n%s", expect, out)
+	}
+	// memory repos don't have an IPFS store or search index
+	for _, name := range []string{"qri_store_size_bytes", "qri_search_index_docs"} {
+		if strings.Contains(out, name) {
+			t.Errorf("expected %s to be left out of output", name)
+		}
+	}
+}
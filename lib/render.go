@@ -2,8 +2,8 @@ package lib
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
-	"html/template"
 	"io"
 	"io/ioutil"
 	"net/rpc"
@@ -13,6 +13,9 @@ import (
 	"github.com/qri-io/dataset"
 	"github.com/qri-io/dataset/dsfs"
 	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/qri/catalog"
+	"github.com/qri-io/qri/chunk"
+	"github.com/qri-io/qri/render"
 	"github.com/qri-io/qri/repo"
 )
 
@@ -41,16 +44,30 @@ func (RenderRequests) CoreRequestsName() string { return "render" }
 
 // RenderParams defines parameters for the Render method
 type RenderParams struct {
-	Ref            repo.DatasetRef
-	Template       []byte
+	Ref      repo.DatasetRef
+	Template []byte
+	// TemplateFormat selects the template engine to render with,
+	// see render.Formats for options. default is "html"
 	TemplateFormat string
 	All            bool
 	Limit, Offset  int
+	// JSONLD adds a schema.org description of the dataset to html output as
+	// JSON-LD
+	JSONLD bool
+}
+
+// VizFormat gives the template format a dataset renders with when no
+// template is supplied: the format of its viz template, or html for the
+// default template
+func VizFormat(ds *dataset.DatasetPod) string {
+	if ds != nil && ds.Viz != nil && ds.Viz.ScriptPath != "" {
+		return ds.Viz.Format
+	}
+	return render.FormatHTML
 }
 
 // Render executes a template against a template
 func (r *RenderRequests) Render(p *RenderParams, res *[]byte) error {
-	const tmplName = "template"
 	var rdr io.Reader
 
 	if r.cli != nil {
@@ -85,29 +102,35 @@ func (r *RenderRequests) Render(p *RenderParams, res *[]byte) error {
 
 	// TODO - hack for now. a subpackage of dataset should handle all of the below,
 	// and use a method to set the default template if one can be loaded from the web
+	format := p.TemplateFormat
 	if rdr == nil && ds.Viz != nil && ds.Viz.ScriptPath != "" {
 		f, err := store.Get(datastore.NewKey(ds.Viz.ScriptPath))
 		if err != nil {
 			return fmt.Errorf("loading template from store: %s", err.Error())
 		}
 		rdr = f
+		if format == "" {
+			format = ds.Viz.Format
+		}
 	}
 
 	if rdr == nil {
+		// the default template is always html
 		rdr = strings.NewReader(DefaultTemplate)
+		format = render.FormatHTML
 	}
 
-	tmplBytes, err := ioutil.ReadAll(rdr)
+	engine, err := render.Lookup(format)
 	if err != nil {
-		return fmt.Errorf("reading template data: %s", err.Error())
+		return NewError(ErrBadArgs, err.Error())
 	}
 
-	tmpl, err := template.New(tmplName).Parse(string(tmplBytes))
+	tmplBytes, err := ioutil.ReadAll(rdr)
 	if err != nil {
-		return fmt.Errorf("parsing template: %s", err.Error())
+		return fmt.Errorf("reading template data: %s", err.Error())
 	}
 
-	file, err := dsfs.LoadBody(store, ds)
+	file, err := chunk.LoadBody(store, ds)
 	if err != nil {
 		log.Debug(err.Error())
 		return err
@@ -168,12 +191,39 @@ func (r *RenderRequests) Render(p *RenderParams, res *[]byte) error {
 	}
 
 	tmplBuf := &bytes.Buffer{}
-	if err := tmpl.Execute(tmplBuf, enc); err != nil {
+	if err := engine.Render(tmplBuf, tmplBytes, enc); err != nil {
 		return err
 	}
 
 	*res = tmplBuf.Bytes()
-	return nil
+	if p.JSONLD && (format == render.FormatHTML || format == "") {
+		*res, err = withJSONLD(*res, enc)
+	}
+	return err
+}
+
+// withJSONLD adds a schema.org description of a dataset to the head of an
+// html page as JSON-LD, so search engines & catalogs can index it. Pages
+// without a head get the description prepended
+func withJSONLD(page []byte, ds *dataset.DatasetPod) ([]byte, error) {
+	pod := *ds
+	pod.Body = nil
+	doc, err := catalog.Encode(catalog.FormatSchemaOrg, &pod)
+	if err != nil {
+		return nil, err
+	}
+	// json.Marshal escapes <, > & &, so the document can't close the script
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	script := append(append([]byte(`<script type="application/ld+json">`), data...), []byte("</script>\n")...)
+
+	i := bytes.Index(bytes.ToLower(page), []byte("</head>"))
+	if i < 0 {
+		return append(script, page...), nil
+	}
+	return append(append(append([]byte{}, page[:i]...), script...), page[i:]...), nil
 }
 
 // DefaultTemplate is the template that render will fall back to should no
@@ -194,6 +244,10 @@ var DefaultTemplate = `<!DOCTYPE html>
     .stat { font-weight: bold; }
     .ref { margin-top: 5px; }}
     .path { color: #bebebe; }
+    .chart svg { width: 100%; height: auto; }
+    table { width: 100%; border-collapse: collapse; font-size: 14px; }
+    th, td { text-align: left; padding: 4px 8px; border-bottom: 1px solid #EBEBEB; }
+    nav.pages { margin: 10px 0; }
   </style>
 </head>
 <body class="viewport">
@@ -215,6 +269,15 @@ var DefaultTemplate = `<!DOCTYPE html>
       <p class="stat"><label>commit title:</label>{{ .Commit.Title }}</p>
     </div>
   </section>
+  {{ if .Body }}
+  <section>
+    <div class="content">
+      <div class="chart">{{ autoChart .Structure.Schema .Body }}</div>
+      {{ pagedTable .Structure.Schema .Body 25 }}
+      {{ if gt .Structure.Entries (len .Body) }}<p class="stat">showing {{ len .Body }} of {{ .Structure.Entries }} entries</p>{{ end }}
+    </div>
+  </section>
+  {{ end }}
   <footer>
     <div class="content">
       {{ if .Meta.License }}
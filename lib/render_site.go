@@ -0,0 +1,260 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/qri-io/dataset/dsfs"
+	"github.com/qri-io/qri/render"
+	"github.com/qri-io/qri/repo"
+)
+
+// SiteParams defines parameters for the RenderSite method
+type SiteParams struct {
+	// Dir is the directory to write the site to, it will be created if it
+	// doesn't exist
+	Dir string
+	// Template, if provided, replaces each dataset's viz template
+	Template       []byte
+	TemplateFormat string
+	// All, Limit & Offset set which body entries each page renders
+	All           bool
+	Limit, Offset int
+}
+
+// SiteEntry is an entry in a rendered site's search manifest
+type SiteEntry struct {
+	Peername    string   `json:"peername"`
+	Name        string   `json:"name"`
+	Path        string   `json:"path"`
+	Title       string   `json:"title,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Keywords    []string `json:"keywords,omitempty"`
+	// URL is the page for the dataset, relative to the site root
+	URL string `json:"url"`
+	// HistoryURL is the history page for the dataset, relative to the site root
+	HistoryURL string `json:"historyUrl"`
+}
+
+// SiteVersion is a single version listed on a dataset's history page
+type SiteVersion struct {
+	repo.DatasetRef
+	// URL is the page for this version, relative to the history page
+	URL string
+}
+
+// SiteFileSearch is the name of the search manifest file written to the root
+// of rendered sites
+const SiteFileSearch = "search.json"
+
+// RenderSite renders every dataset in the repo to a directory of static html
+// pages. Each dataset gets a page for its latest version, a history page
+// linking to a page for each version, and the site gets an index page
+// and a JSON search manifest. All links are relative, so sites can be hosted
+// from any path. res is set to the list of files written, relative to p.Dir
+func (r *RenderRequests) RenderSite(p *SiteParams, res *[]string) error {
+	if r.cli != nil {
+		return r.cli.Call("RenderRequests.RenderSite", p, res)
+	}
+
+	if p.Dir == "" {
+		return NewError(ErrBadArgs, "a directory to write the site to is required")
+	}
+
+	var (
+		written []string
+		entries []SiteEntry
+	)
+
+	write := func(path string, data []byte) error {
+		fullpath := filepath.Join(p.Dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(fullpath), os.ModePerm); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(fullpath, data, 0666); err != nil {
+			return err
+		}
+		written = append(written, path)
+		return nil
+	}
+
+	renderPage := func(ref repo.DatasetRef) ([]byte, error) {
+		page := []byte{}
+		err := r.Render(&RenderParams{
+			Ref:            ref,
+			Template:       p.Template,
+			TemplateFormat: p.TemplateFormat,
+			All:            p.All,
+			Limit:          p.Limit,
+			Offset:         p.Offset,
+		}, &page)
+		return page, err
+	}
+
+	refs, err := allReferences(r.repo)
+	if err != nil {
+		return err
+	}
+
+	history := NewHistoryRequests(r.repo, nil)
+
+	for _, ref := range refs {
+		dir := ref.Peername + "/" + ref.Name
+
+		// negative limits read the full history
+		versions := []repo.DatasetRef{}
+		if err := history.Log(&LogParams{Ref: ref, ListParams: ListParams{Limit: -1}}, &versions); err != nil {
+			return fmt.Errorf("loading history for %s: %s", ref.AliasString(), err.Error())
+		}
+
+		page, err := renderPage(ref)
+		if err != nil {
+			return fmt.Errorf("rendering %s: %s", ref.AliasString(), err.Error())
+		}
+		if err := write(dir+"/index.html", page); err != nil {
+			return err
+		}
+
+		hist := make([]SiteVersion, len(versions))
+		for i, v := range versions {
+			url := "versions/" + versionHash(v.Path) + ".html"
+			page, err := renderPage(v)
+			if err != nil {
+				return fmt.Errorf("rendering %s@%s: %s", ref.AliasString(), v.Path, err.Error())
+			}
+			if err := write(dir+"/"+url, page); err != nil {
+				return err
+			}
+			hist[i] = SiteVersion{DatasetRef: v, URL: url}
+		}
+
+		page, err = render.Render(render.FormatHTML, []byte(SiteHistoryTemplate), map[string]interface{}{
+			"Ref":      ref,
+			"Versions": hist,
+		})
+		if err != nil {
+			return fmt.Errorf("rendering history page for %s: %s", ref.AliasString(), err.Error())
+		}
+		if err := write(dir+"/history.html", page); err != nil {
+			return err
+		}
+
+		entry := SiteEntry{
+			Peername:   ref.Peername,
+			Name:       ref.Name,
+			Path:       ref.Path,
+			URL:        dir + "/index.html",
+			HistoryURL: dir + "/history.html",
+		}
+		if len(versions) > 0 && versions[0].Dataset != nil && versions[0].Dataset.Meta != nil {
+			md := versions[0].Dataset.Meta
+			entry.Title = md.Title
+			entry.Description = md.Description
+			entry.Keywords = md.Keywords
+		}
+		entries = append(entries, entry)
+	}
+
+	page, err := render.Render(render.FormatHTML, []byte(SiteIndexTemplate), entries)
+	if err != nil {
+		return fmt.Errorf("rendering index page: %s", err.Error())
+	}
+	if err := write("index.html", page); err != nil {
+		return err
+	}
+
+	manifest, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := write(SiteFileSearch, manifest); err != nil {
+		return err
+	}
+
+	*res = written
+	return nil
+}
+
+// allReferences pages through every reference in a repo
+func allReferences(r repo.Repo) ([]repo.DatasetRef, error) {
+	const pageSize = 100
+	var refs []repo.DatasetRef
+	for offset := 0; ; offset += pageSize {
+		page, err := r.References(pageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, page...)
+		if len(page) < pageSize {
+			return refs, nil
+		}
+	}
+}
+
+// versionHash extracts the hash from a dataset path for use as a filename
+func versionHash(path string) string {
+	return filepath.Base(strings.TrimSuffix(path, "/"+dsfs.PackageFileDataset.String()))
+}
+
+// SiteIndexTemplate is the template for the index page of rendered sites. It's
+// executed against a slice of SiteEntry
+var SiteIndexTemplate = `<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>datasets</title>
+  <style type="text/css">
+    body { margin: 0 auto; max-width: 600px; font-family: "avenir next", "avenir", sans-serif; font-size: 16px; }
+    li { margin: 20px 0; list-style: none; }
+    .path, .history { color: #999; font-size: 14px; }
+  </style>
+</head>
+<body>
+  <h1>datasets</h1>
+  <ul>
+  {{ range . }}
+    <li>
+      <a href="{{ .URL }}">{{ .Peername }}/{{ .Name }}</a>
+      {{ if .Title }}<h3>{{ .Title }}</h3>{{ end }}
+      {{ if .Description }}<p>{{ .Description }}</p>{{ end }}
+      <a class="history" href="{{ .HistoryURL }}">history</a>
+    </li>
+  {{ end }}
+  </ul>
+  <p>Created with <a href="https://qri.io">qri</a></p>
+</body>
+</html>`
+
+// SiteHistoryTemplate is the template for dataset history pages of rendered
+// sites. It's executed against a map with "Ref" & "Versions" keys
+var SiteHistoryTemplate = `<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>{{ .Ref.Peername }}/{{ .Ref.Name }} history</title>
+  <style type="text/css">
+    body { margin: 0 auto; max-width: 600px; font-family: "avenir next", "avenir", sans-serif; font-size: 16px; }
+    li { margin: 20px 0; list-style: none; }
+    .path { color: #999; font-size: 14px; }
+  </style>
+</head>
+<body>
+  <p><a href="../../index.html">datasets</a> / <a href="index.html">{{ .Ref.Peername }}/{{ .Ref.Name }}</a></p>
+  <h1>history</h1>
+  <ul>
+  {{ range .Versions }}
+    <li>
+      {{ if .Dataset }}{{ if .Dataset.Commit }}
+        <a href="{{ .URL }}">{{ .Dataset.Commit.Title }}</a>
+        <p>{{ .Dataset.Commit.Timestamp.Format "Mon, 02 Jan 2006 15:04" }}</p>
+      {{ end }}{{ end }}
+      <a class="path" href="{{ .URL }}">{{ .Path }}</a>
+    </li>
+  {{ end }}
+  </ul>
+</body>
+</html>`
@@ -0,0 +1,74 @@
+package lib
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	testrepo "github.com/qri-io/qri/repo/test"
+)
+
+func TestRenderRequestsRenderSite(t *testing.T) {
+	tr, err := testrepo.NewTestRepo(nil)
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err.Error())
+	}
+
+	dir, err := ioutil.TempDir("", "qri_render_site")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	reqs := NewRenderRequests(tr, nil)
+
+	res := []string{}
+	if err := reqs.RenderSite(&SiteParams{}, &res); err == nil {
+		t.Errorf("expected missing directory to error")
+	}
+
+	if err := reqs.RenderSite(&SiteParams{Dir: dir, Limit: 10}, &res); err != nil {
+		t.Fatalf("error rendering site: %s", err.Error())
+	}
+
+	for _, path := range []string{"index.html", SiteFileSearch, "peer/movies/index.html", "peer/movies/history.html"} {
+		if _, err := os.Stat(filepath.Join(dir, path)); err != nil {
+			t.Errorf("expected file %s to be written: %s", path, err.Error())
+		}
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, SiteFileSearch))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	entries := []SiteEntry{}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		t.Fatal(err.Error())
+	}
+	refs, err := tr.References(100, 0)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(entries) != len(refs) {
+		t.Errorf("expected search manifest to have %d entries, got: %d", len(refs), len(entries))
+	}
+
+	index, err := ioutil.ReadFile(filepath.Join(dir, "index.html"))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !strings.Contains(string(index), `href="peer/movies/index.html"`) {
+		t.Errorf("expected index to link to dataset pages relatively")
+	}
+
+	history, err := ioutil.ReadFile(filepath.Join(dir, "peer/movies/history.html"))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !strings.Contains(string(history), `href="versions/`) {
+		t.Errorf("expected history page to link to version pages")
+	}
+}
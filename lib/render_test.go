@@ -1,10 +1,12 @@
 package lib
 
 import (
+	"encoding/json"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/rpc"
+	"strings"
 	"testing"
 
 	"github.com/qri-io/qri/repo"
@@ -87,6 +89,30 @@ func TestRenderRequestsRender(t *testing.T) {
 				Name:     "movies",
 			},
 		}, []byte("<html><h1>peer/movies</h1></html>"), ""},
+		{&RenderParams{
+			Ref: repo.DatasetRef{
+				Peername: "me",
+				Name:     "movies",
+			},
+			Template:       []byte("# {{ .Meta.Title }}"),
+			TemplateFormat: "markdown",
+		}, []byte("<h1>example movie data</h1>\n"), ""},
+		{&RenderParams{
+			Ref: repo.DatasetRef{
+				Peername: "me",
+				Name:     "movies",
+			},
+			Template:       []byte("{{ meta.title }}"),
+			TemplateFormat: "mustache",
+		}, []byte("example movie data"), ""},
+		{&RenderParams{
+			Ref: repo.DatasetRef{
+				Peername: "me",
+				Name:     "movies",
+			},
+			Template:       []byte("{{ .Meta.Title }}"),
+			TemplateFormat: "pdf",
+		}, nil, "bad arguments provided"},
 		{&RenderParams{
 			Ref: repo.DatasetRef{
 				Peername: "me",
@@ -125,3 +151,44 @@ func TestRenderRequestsRender(t *testing.T) {
 		}
 	}
 }
+
+func TestRenderRequestsRenderJSONLD(t *testing.T) {
+	tr, err := testrepo.NewTestRepo(nil)
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err.Error())
+	}
+	reqs := NewRenderRequests(tr, nil)
+
+	got := []byte{}
+	p := &RenderParams{
+		Ref:      repo.DatasetRef{Peername: "me", Name: "movies"},
+		Template: []byte("<html><head><title>{{ .Name }}</title></head><body></body></html>"),
+		JSONLD:   true,
+	}
+	if err := reqs.Render(p, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	page := string(got)
+	start := strings.Index(page, `<script type="application/ld+json">`)
+	if start < 0 || start > strings.Index(page, "</head>") {
+		t.Fatalf("expected JSON-LD in the page head, got:\n%s", page)
+	}
+	data := page[start+len(`<script type="application/ld+json">`) : strings.Index(page, "</script>")]
+	doc := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(data), &doc); err != nil {
+		t.Fatalf("invalid JSON-LD: %s", err.Error())
+	}
+	if doc["@type"] != "Dataset" || doc["@context"] != "https://schema.org/" {
+		t.Errorf("expected a schema.org Dataset, got: %v", doc)
+	}
+
+	// other formats are left alone
+	p.TemplateFormat = "text"
+	if err := reqs.Render(p, &got); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(got), "application/ld+json") {
+		t.Errorf("expected JSON-LD only in html pages")
+	}
+}
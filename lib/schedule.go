@@ -0,0 +1,336 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/rpc"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/qri/actions"
+	"github.com/qri-io/qri/cron"
+	"github.com/qri-io/qri/p2p"
+	"github.com/qri-io/qri/repo"
+)
+
+// ScheduleRequests encapsulates business logic for managing scheduled
+// dataset updates
+type ScheduleRequests struct {
+	cli  *rpc.Client
+	repo repo.Repo
+}
+
+// NewScheduleRequests creates a ScheduleRequests pointer from either a repo
+// or an rpc.Client
+func NewScheduleRequests(r repo.Repo, cli *rpc.Client) *ScheduleRequests {
+	if r != nil && cli != nil {
+		panic(fmt.Errorf("both repo and client supplied to NewScheduleRequests"))
+	}
+	return &ScheduleRequests{
+		cli:  cli,
+		repo: r,
+	}
+}
+
+// CoreRequestsName implements the Requests interface
+func (ScheduleRequests) CoreRequestsName() string { return "schedule" }
+
+// ScheduleParams defines parameters for adding a schedule
+type ScheduleParams struct {
+	Ref repo.DatasetRef
+	// Spec is a cron-like specification, eg: "0 6 * * *" or "@daily"
+	Spec string
+	// BodyPath is an optional url to re-fetch the body from
+	BodyPath string
+}
+
+// Add schedules recurring updates for a dataset
+func (r *ScheduleRequests) Add(p *ScheduleParams, res *repo.Schedule) error {
+	if r.cli != nil {
+		return r.cli.Call("ScheduleRequests.Add", p, res)
+	}
+
+	ss, ok := r.repo.(repo.ScheduleStore)
+	if !ok {
+		return repo.ErrSchedulesNotSupported
+	}
+
+	if _, err := cron.Parse(p.Spec); err != nil {
+		return NewError(ErrBadArgs, fmt.Sprintf("invalid schedule spec: %s", err.Error()))
+	}
+
+	if err := repo.CanonicalizeDatasetRef(r.repo, &p.Ref); err != nil {
+		log.Debug(err.Error())
+		return err
+	}
+
+	pro, err := r.repo.Profile()
+	if err != nil {
+		return err
+	}
+	if p.Ref.Peername != pro.Peername {
+		return fmt.Errorf("can only schedule updates for your own datasets")
+	}
+
+	if p.BodyPath != "" && !isURL(p.BodyPath) {
+		return fmt.Errorf("scheduled body paths must be a url")
+	}
+
+	sch := &repo.Schedule{
+		Ref:      repo.DatasetRef{Peername: p.Ref.Peername, Name: p.Ref.Name},
+		Spec:     p.Spec,
+		BodyPath: p.BodyPath,
+		Created:  time.Now(),
+	}
+	if err := ss.PutSchedule(sch); err != nil {
+		return err
+	}
+
+	*res = *sch
+	return nil
+}
+
+// List shows all scheduled updates
+func (r *ScheduleRequests) List(p *ListParams, res *[]*repo.Schedule) error {
+	if r.cli != nil {
+		return r.cli.Call("ScheduleRequests.List", p, res)
+	}
+
+	ss, ok := r.repo.(repo.ScheduleStore)
+	if !ok {
+		return repo.ErrSchedulesNotSupported
+	}
+
+	schedules, err := ss.Schedules()
+	if err != nil {
+		return err
+	}
+
+	if p.Offset > len(schedules) {
+		p.Offset = len(schedules)
+	}
+	stop := len(schedules)
+	if p.Limit > 0 && p.Offset+p.Limit < stop {
+		stop = p.Offset + p.Limit
+	}
+
+	*res = schedules[p.Offset:stop]
+	return nil
+}
+
+// Remove stops scheduled updates for a dataset
+func (r *ScheduleRequests) Remove(ref *repo.DatasetRef, done *bool) error {
+	if r.cli != nil {
+		return r.cli.Call("ScheduleRequests.Remove", ref, done)
+	}
+
+	ss, ok := r.repo.(repo.ScheduleStore)
+	if !ok {
+		return repo.ErrSchedulesNotSupported
+	}
+
+	if err := repo.CanonicalizeDatasetRef(r.repo, ref); err != nil && err != repo.ErrNotFound {
+		log.Debug(err.Error())
+		return err
+	}
+
+	if err := ss.DeleteSchedule(*ref); err != nil {
+		if err == repo.ErrNotFound {
+			return fmt.Errorf("no schedule found for %s", ref.AliasString())
+		}
+		return err
+	}
+
+	*done = true
+	return nil
+}
+
+// DefaultScheduleInterval is how often a Scheduler checks for due updates
+const DefaultScheduleInterval = time.Minute
+
+// Scheduler executes scheduled dataset updates. Each due schedule re-fetches
+// the dataset body or re-runs the dataset transform, saving a new version
+// only if something changed
+type Scheduler struct {
+	// Clock provides the current time, defaults to cron.RealClock
+	Clock cron.Clock
+	// Interval is the time between checks for due schedules
+	Interval time.Duration
+
+	repo repo.Repo
+	node *p2p.QriNode
+}
+
+// NewScheduler allocates a Scheduler. node may be nil
+func NewScheduler(r repo.Repo, node *p2p.QriNode) *Scheduler {
+	return &Scheduler{
+		Clock:    cron.RealClock,
+		Interval: DefaultScheduleInterval,
+		repo:     r,
+		node:     node,
+	}
+}
+
+// Start checks for due schedules every Interval, blocking until ctx is cancelled
+func (s *Scheduler) Start(ctx context.Context) error {
+	if _, ok := s.repo.(repo.ScheduleStore); !ok {
+		return repo.ErrSchedulesNotSupported
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.Clock.After(s.Interval):
+			if err := s.RunDue(); err != nil {
+				log.Errorf("running scheduled updates: %s", err.Error())
+			}
+		}
+	}
+}
+
+// RunDue executes every schedule that is due as of the scheduler's current
+// time. A failing update is logged & doesn't prevent other updates from running
+func (s *Scheduler) RunDue() error {
+	ss, ok := s.repo.(repo.ScheduleStore)
+	if !ok {
+		return repo.ErrSchedulesNotSupported
+	}
+
+	schedules, err := ss.Schedules()
+	if err != nil {
+		return err
+	}
+
+	now := s.Clock.Now()
+	for _, sch := range schedules {
+		spec, err := cron.Parse(sch.Spec)
+		if err != nil {
+			log.Errorf("invalid schedule for %s: %s", sch.Ref.AliasString(), err.Error())
+			continue
+		}
+
+		last := sch.LastRun
+		if last.IsZero() {
+			last = sch.Created
+		}
+		if next := spec.Next(last); next.IsZero() || next.After(now) {
+			continue
+		}
+
+		if err := s.update(sch); err != nil {
+			log.Errorf("scheduled update for %s: %s", sch.Ref.AliasString(), err.Error())
+		}
+
+		sch.LastRun = now
+		if err := ss.PutSchedule(sch); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// update performs a single scheduled update, logging the outcome to the
+// repo event log
+func (s *Scheduler) update(sch *repo.Schedule) (err error) {
+	act := actions.Dataset{s.repo}
+	ref := repo.DatasetRef{Peername: sch.Ref.Peername, Name: sch.Ref.Name}
+
+	defer func() {
+		if err != nil {
+			if e := act.LogEvent(repo.ETScheduleFailed, ref); e != nil {
+				log.Error(e.Error())
+			}
+		}
+	}()
+
+	if err = repo.CanonicalizeDatasetRef(s.repo, &ref); err != nil {
+		return err
+	}
+	if err = act.ReadDataset(&ref); err != nil {
+		return err
+	}
+
+	dsp := &dataset.DatasetPod{
+		Peername: ref.Peername,
+		Name:     ref.Name,
+		Commit: &dataset.CommitPod{
+			Title: fmt.Sprintf("scheduled update (%s)", sch.Spec),
+		},
+	}
+
+	bodyPath := sch.BodyPath
+	if bodyPath == "" && ref.Dataset.Meta != nil && isURL(ref.Dataset.Meta.DownloadPath) {
+		bodyPath = ref.Dataset.Meta.DownloadPath
+	}
+
+	if bodyPath != "" {
+		dsp.BodyPath = bodyPath
+	} else if ref.Dataset.Transform != nil && ref.Dataset.Transform.ScriptPath != "" {
+		// transforms execute from the local filesystem, so copy the stored
+		// script out to a temp file
+		scriptPath, e := s.writeTempScript(ref.Dataset.Transform.ScriptPath)
+		if e != nil {
+			return e
+		}
+		defer os.Remove(scriptPath)
+
+		dsp.Transform = &dataset.TransformPod{
+			Syntax:     ref.Dataset.Transform.Syntax,
+			ScriptPath: scriptPath,
+		}
+	} else {
+		return fmt.Errorf("dataset has no body url or transform to update from")
+	}
+
+	res := &repo.DatasetRef{}
+	req := NewDatasetRequestsWithNode(s.repo, nil, s.node)
+	// bodies from urls are re-fetched conditionally, skipping unchanged bodies.
+	// scheduled transforms usually fetch remote data the transform cache can't
+	// see, so they always execute
+	if err = req.Save(&SaveParams{Dataset: dsp, Refresh: bodyPath != "", NoTransformCache: bodyPath == ""}, res); err != nil {
+		if isNoChangesErr(err) || err == repo.ErrBodyNotModified {
+			return act.LogEvent(repo.ETScheduleUnchanged, ref)
+		}
+		return err
+	}
+
+	return act.LogEvent(repo.ETScheduleRan, *res)
+}
+
+func (s *Scheduler) writeTempScript(path string) (string, error) {
+	f, err := s.repo.Store().Get(datastore.NewKey(path))
+	if err != nil {
+		return "", fmt.Errorf("loading transform script: %s", err.Error())
+	}
+	defer f.Close()
+
+	tmp, err := ioutil.TempFile("", "scheduled_transform")
+	if err != nil {
+		return "", err
+	}
+	defer tmp.Close()
+
+	if _, err = io.Copy(tmp, f); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
+
+// isNoChangesErr checks for the error dsfs.CreateDataset returns when a save
+// wouldn't alter a dataset
+func isNoChangesErr(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "no changes detected")
+}
+
+func isURL(path string) bool {
+	lowered := strings.ToLower(path)
+	return strings.HasPrefix(lowered, "http://") || strings.HasPrefix(lowered, "https://")
+}
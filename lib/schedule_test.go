@@ -0,0 +1,166 @@
+package lib
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/qri-io/qri/repo"
+	testrepo "github.com/qri-io/qri/repo/test"
+)
+
+func TestScheduleRequests(t *testing.T) {
+	mr, err := testrepo.NewTestRepo(nil)
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err.Error())
+	}
+
+	req := NewScheduleRequests(mr, nil)
+	if req.CoreRequestsName() != "schedule" {
+		t.Errorf("invalid requests name. expected: '%s', got: '%s'", "schedule", req.CoreRequestsName())
+	}
+
+	bad := []struct {
+		p   *ScheduleParams
+		err string
+	}{
+		{&ScheduleParams{Ref: repo.DatasetRef{Peername: "me", Name: "cities"}, Spec: "nope"}, "bad arguments provided"},
+		{&ScheduleParams{Ref: repo.DatasetRef{Peername: "me", Name: "not_a_dataset"}, Spec: "@daily"}, "repo: not found"},
+		{&ScheduleParams{Ref: repo.DatasetRef{Peername: "me", Name: "cities"}, Spec: "@daily", BodyPath: "cities.csv"}, "scheduled body paths must be a url"},
+	}
+
+	for i, c := range bad {
+		res := &repo.Schedule{}
+		err := req.Add(c.p, res)
+		if err == nil || err.Error() != c.err {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%v'", i, c.err, err)
+		}
+	}
+
+	res := &repo.Schedule{}
+	if err := req.Add(&ScheduleParams{Ref: repo.DatasetRef{Peername: "me", Name: "cities"}, Spec: "@daily"}, res); err != nil {
+		t.Fatalf("error adding schedule: %s", err.Error())
+	}
+	if res.Ref.AliasString() != "peer/cities" {
+		t.Errorf("expected schedule ref to be canonicalized. got: %s", res.Ref.AliasString())
+	}
+
+	list := []*repo.Schedule{}
+	if err := req.List(&ListParams{}, &list); err != nil {
+		t.Fatalf("error listing schedules: %s", err.Error())
+	}
+	if len(list) != 1 {
+		t.Errorf("expected 1 schedule, got: %d", len(list))
+	}
+
+	done := false
+	if err := req.Remove(&repo.DatasetRef{Peername: "me", Name: "cities"}, &done); err != nil {
+		t.Errorf("error removing schedule: %s", err.Error())
+	}
+	if err := req.Remove(&repo.DatasetRef{Peername: "me", Name: "cities"}, &done); err == nil {
+		t.Errorf("expected removing a missing schedule to error")
+	}
+}
+
+// testClock is a cron.Clock that only moves when told to
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time { return c.now }
+
+func (c *testClock) After(d time.Duration) <-chan time.Time {
+	c.now = c.now.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+func TestSchedulerRunDue(t *testing.T) {
+	mr, err := testrepo.NewTestRepo(nil)
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err.Error())
+	}
+
+	body := "city,pop,avg_age,in_usa\ntoronto,40000000,55.5,false\nnew york,8500000,44.4,true\n"
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer s.Close()
+
+	start := time.Date(2018, 7, 4, 12, 0, 0, 0, time.UTC)
+	clock := &testClock{now: start}
+
+	if err := mr.PutSchedule(&repo.Schedule{
+		Ref:      repo.DatasetRef{Peername: "peer", Name: "cities"},
+		Spec:     "@daily",
+		BodyPath: s.URL + "/cities.csv",
+		Created:  start,
+	}); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	sched := NewScheduler(mr, nil)
+	sched.Clock = clock
+
+	countEvents := func(et repo.EventType) (count int) {
+		events, err := mr.Events(100, 0)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		for _, e := range events {
+			if e.Type == et {
+				count++
+			}
+		}
+		return
+	}
+
+	// not yet due
+	if err := sched.RunDue(); err != nil {
+		t.Fatalf("error running schedules: %s", err.Error())
+	}
+	if count := countEvents(repo.ETScheduleRan); count != 0 {
+		t.Errorf("expected no scheduled runs before schedule is due, got: %d", count)
+	}
+
+	clock.now = start.Add(12 * time.Hour)
+	if err := sched.RunDue(); err != nil {
+		t.Fatalf("error running schedules: %s", err.Error())
+	}
+	if count := countEvents(repo.ETScheduleRan); count != 1 {
+		t.Errorf("expected 1 scheduled run, got: %d", count)
+	}
+
+	ref := repo.DatasetRef{Peername: "peer", Name: "cities"}
+	if err := repo.CanonicalizeDatasetRef(mr, &ref); err != nil {
+		t.Fatal(err.Error())
+	}
+	updatedPath := ref.Path
+
+	// same body the next day should skip the commit
+	clock.now = start.Add(36 * time.Hour)
+	if err := sched.RunDue(); err != nil {
+		t.Fatalf("error running schedules: %s", err.Error())
+	}
+	if count := countEvents(repo.ETScheduleUnchanged); count != 1 {
+		t.Errorf("expected 1 unchanged scheduled run, got: %d", count)
+	}
+
+	ref = repo.DatasetRef{Peername: "peer", Name: "cities"}
+	if err := repo.CanonicalizeDatasetRef(mr, &ref); err != nil {
+		t.Fatal(err.Error())
+	}
+	if ref.Path != updatedPath {
+		t.Errorf("expected unchanged scheduled run not to create a new version")
+	}
+
+	schedules, err := mr.Schedules()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !schedules[0].LastRun.Equal(clock.now) {
+		t.Errorf("expected LastRun to be updated to %s, got: %s", clock.now, schedules[0].LastRun)
+	}
+}
@@ -57,6 +57,16 @@ func Setup(p SetupParams) error {
 		return fmt.Errorf("error creating qri repo directory: %s, path: %s", err.Error(), p.QriRepoPath)
 	}
 
+	if cfg.API != nil && cfg.API.TLSCertFile == "" && cfg.API.TLSKeyFile == "" {
+		certPath := filepath.Join(p.QriRepoPath, "tls", "cert.pem")
+		keyPath := filepath.Join(p.QriRepoPath, "tls", "key.pem")
+		if err := GenerateSelfSignedCert(certPath, keyPath, SelfSignedCertHosts()); err != nil {
+			return fmt.Errorf("error generating self-signed certificate: %s", err.Error())
+		}
+		cfg.API.TLSCertFile = certPath
+		cfg.API.TLSKeyFile = keyPath
+	}
+
 	if p.SetupIPFS {
 		tmpIPFSConfigPath := ""
 		if p.SetupIPFSConfigData != nil {
@@ -33,6 +33,9 @@ func TestSetupTeardown(t *testing.T) {
 	if err := Setup(params); err != nil {
 		t.Error(err.Error())
 	}
+	if !CertValid(params.Config.API.TLSCertFile, params.Config.API.TLSKeyFile) {
+		t.Errorf("expected setup to generate a self-signed certificate. cert: '%s', key: '%s'", params.Config.API.TLSCertFile, params.Config.API.TLSKeyFile)
+	}
 
 	err := Teardown(TeardownParams{
 		Config:         params.Config,
@@ -0,0 +1,106 @@
+package lib
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SelfSignedCertLifetime is how long generated self-signed certificates are
+// valid for
+var SelfSignedCertLifetime = time.Hour * 24 * 365
+
+// DefaultSelfSignedCertHosts are the hostnames & IP addresses generated
+// certificates are valid for, in addition to the local hostname
+var DefaultSelfSignedCertHosts = []string{"localhost", "127.0.0.1", "::1"}
+
+// GenerateSelfSignedCert writes a PEM-encoded self-signed certificate & its
+// private key to certPath & keyPath, valid for the given hosts. hosts can be
+// hostnames or IP addresses. The key file is only readable by the current user
+func GenerateSelfSignedCert(certPath, keyPath string, hosts []string) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return fmt.Errorf("error generating private key: %s", err.Error())
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return fmt.Errorf("error generating serial number: %s", err.Error())
+	}
+
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			Organization: []string{"qri"},
+		},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(SelfSignedCertLifetime),
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		} else if h != "" {
+			tmpl.DNSNames = append(tmpl.DNSNames, h)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return fmt.Errorf("error creating certificate: %s", err.Error())
+	}
+	keyData, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("error encoding private key: %s", err.Error())
+	}
+
+	for _, path := range []string{certPath, keyPath} {
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			return err
+		}
+	}
+	if err := ioutil.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+		return fmt.Errorf("error writing certificate: %s", err.Error())
+	}
+	if err := ioutil.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyData}), 0600); err != nil {
+		return fmt.Errorf("error writing private key: %s", err.Error())
+	}
+	return nil
+}
+
+// SelfSignedCertHosts returns the hosts generated certificates are valid for
+func SelfSignedCertHosts() []string {
+	hosts := append([]string{}, DefaultSelfSignedCertHosts...)
+	if name, err := os.Hostname(); err == nil && name != "" && name != "localhost" {
+		hosts = append(hosts, name)
+	}
+	return hosts
+}
+
+// CertValid reports whether the certificate & key at certPath & keyPath can
+// be loaded, and the certificate hasn't expired
+func CertValid(certPath, keyPath string) bool {
+	pair, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil || len(pair.Certificate) == 0 {
+		return false
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return false
+	}
+	return time.Now().Before(cert.NotAfter)
+}
@@ -0,0 +1,64 @@
+package lib
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGenerateSelfSignedCert(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test_lib_self_signed_cert")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	certPath := filepath.Join(dir, "tls", "cert.pem")
+	keyPath := filepath.Join(dir, "tls", "key.pem")
+	if CertValid(certPath, keyPath) {
+		t.Errorf("expected missing certificate to be invalid")
+	}
+
+	if err := GenerateSelfSignedCert(certPath, keyPath, []string{"localhost", "127.0.0.1", "qri.local"}); err != nil {
+		t.Fatalf("error generating certificate: %s", err.Error())
+	}
+	if !CertValid(certPath, keyPath) {
+		t.Errorf("expected generated certificate to be valid")
+	}
+
+	fi, err := os.Stat(keyPath)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if fi.Mode().Perm() != 0600 {
+		t.Errorf("key file permissions mismatch. expected: %o, got: %o", 0600, fi.Mode().Perm())
+	}
+
+	pair, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	for _, host := range []string{"localhost", "127.0.0.1", "qri.local"} {
+		if err := cert.VerifyHostname(host); err != nil {
+			t.Errorf("expected certificate to be valid for %s: %s", host, err.Error())
+		}
+	}
+
+	prev := SelfSignedCertLifetime
+	defer func() { SelfSignedCertLifetime = prev }()
+	SelfSignedCertLifetime = -time.Minute
+	if err := GenerateSelfSignedCert(certPath, keyPath, []string{"localhost"}); err != nil {
+		t.Fatalf("error generating certificate: %s", err.Error())
+	}
+	if CertValid(certPath, keyPath) {
+		t.Errorf("expected expired certificate to be invalid")
+	}
+}
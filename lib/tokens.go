@@ -0,0 +1,172 @@
+package lib
+
+import (
+	"fmt"
+	"net/rpc"
+	"strings"
+
+	"github.com/qri-io/qri/repo"
+	"github.com/qri-io/qri/repo/profile"
+)
+
+// TokenRequests encapsulates business logic for managing api tokens
+type TokenRequests struct {
+	cli  *rpc.Client
+	repo repo.Repo
+}
+
+// NewTokenRequests creates a TokenRequests pointer from either a repo
+// or an rpc.Client
+func NewTokenRequests(r repo.Repo, cli *rpc.Client) *TokenRequests {
+	if r != nil && cli != nil {
+		panic(fmt.Errorf("both repo and client supplied to NewTokenRequests"))
+	}
+	return &TokenRequests{
+		cli:  cli,
+		repo: r,
+	}
+}
+
+// CoreRequestsName implements the Requests interface
+func (TokenRequests) CoreRequestsName() string { return "token" }
+
+// TokenParams defines parameters for creating a token
+type TokenParams struct {
+	// Scope is one of "read", "write" or "admin"
+	Scope string
+	// Datasets optionally limits the token to datasets matching a list of
+	// patterns, eg: "me/*"
+	Datasets []string
+	// Peer optionally grants access to a peer by peername or profile ID
+	// instead of creating a secret. peers authenticate by signing requests
+	// with their profile key
+	Peer string
+}
+
+// TokenResult is a newly created token & its secret
+type TokenResult struct {
+	Token *repo.Token
+	// Secret is the value clients present to authenticate. it isn't stored,
+	// and can't be recovered after creation. peer grants have no secret
+	Secret string
+}
+
+// Create makes a new api token
+func (r *TokenRequests) Create(p *TokenParams, res *TokenResult) error {
+	if r.cli != nil {
+		return r.cli.Call("TokenRequests.Create", p, res)
+	}
+
+	ts, ok := r.repo.(repo.TokenStore)
+	if !ok {
+		return repo.ErrTokensNotSupported
+	}
+
+	scope, err := repo.ParseTokenScope(p.Scope)
+	if err != nil {
+		return NewError(ErrBadArgs, err.Error())
+	}
+
+	var datasets []string
+	for _, pattern := range p.Datasets {
+		if strings.HasPrefix(pattern, "me/") {
+			pro, err := r.repo.Profile()
+			if err != nil {
+				return err
+			}
+			pattern = pro.Peername + strings.TrimPrefix(pattern, "me")
+		}
+		datasets = append(datasets, pattern)
+	}
+
+	if p.Peer != "" {
+		return r.grant(ts, p.Peer, scope, datasets, res)
+	}
+
+	tok, secret, err := repo.NewToken(scope, datasets)
+	if err != nil {
+		return NewError(ErrBadArgs, err.Error())
+	}
+	if err := ts.PutToken(tok); err != nil {
+		return err
+	}
+
+	*res = TokenResult{Token: tok, Secret: secret}
+	return nil
+}
+
+// grant gives a peer access, replacing any access the peer already has
+func (r *TokenRequests) grant(ts repo.TokenStore, peer string, scope repo.TokenScope, datasets []string, res *TokenResult) error {
+	pid, err := profile.IDB58Decode(peer)
+	if err != nil {
+		if pid, err = r.repo.Profiles().PeernameID(peer); err != nil {
+			return fmt.Errorf("unknown peer: %s", peer)
+		}
+	} else if _, err = r.repo.Profiles().GetProfile(pid); err != nil {
+		return fmt.Errorf("unknown peer: %s", peer)
+	}
+
+	tok, err := repo.NewPeerGrant(pid.String(), scope, datasets)
+	if err != nil {
+		return NewError(ErrBadArgs, err.Error())
+	}
+	if prev, err := repo.PeerGrant(ts, tok.ProfileID); err == nil {
+		tok.ID = prev.ID
+	}
+	if err := ts.PutToken(tok); err != nil {
+		return err
+	}
+
+	*res = TokenResult{Token: tok}
+	return nil
+}
+
+// List shows all api tokens
+func (r *TokenRequests) List(p *ListParams, res *[]*repo.Token) error {
+	if r.cli != nil {
+		return r.cli.Call("TokenRequests.List", p, res)
+	}
+
+	ts, ok := r.repo.(repo.TokenStore)
+	if !ok {
+		return repo.ErrTokensNotSupported
+	}
+
+	tokens, err := ts.Tokens()
+	if err != nil {
+		return err
+	}
+
+	if p.Offset > len(tokens) {
+		p.Offset = len(tokens)
+	}
+	stop := len(tokens)
+	if p.Limit > 0 && p.Offset+p.Limit < stop {
+		stop = p.Offset + p.Limit
+	}
+
+	*res = tokens[p.Offset:stop]
+	return nil
+}
+
+// Delete revokes an api token
+func (r *TokenRequests) Delete(id *string, done *bool) error {
+	if r.cli != nil {
+		return r.cli.Call("TokenRequests.Delete", id, done)
+	}
+
+	ts, ok := r.repo.(repo.TokenStore)
+	if !ok {
+		return repo.ErrTokensNotSupported
+	}
+
+	if err := ts.DeleteToken(*id); err != nil {
+		if err == repo.ErrNotFound {
+			return fmt.Errorf("no token found with id %s", *id)
+		}
+		return err
+	}
+
+	*done = true
+	return nil
+}
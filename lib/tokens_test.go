@@ -0,0 +1,84 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/qri-io/qri/repo"
+	"github.com/qri-io/qri/repo/profile"
+	testrepo "github.com/qri-io/qri/repo/test"
+)
+
+func TestTokenRequests(t *testing.T) {
+	mr, err := testrepo.NewTestRepo(nil)
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err.Error())
+	}
+
+	req := NewTokenRequests(mr, nil)
+	if req.CoreRequestsName() != "token" {
+		t.Errorf("invalid requests name. expected: '%s', got: '%s'", "token", req.CoreRequestsName())
+	}
+
+	bad := []*TokenParams{
+		{Scope: "root"},
+		{Scope: "read", Datasets: []string{"cities"}},
+	}
+	for i, p := range bad {
+		err := req.Create(p, &TokenResult{})
+		if err == nil || err.Error() != "bad arguments provided" {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%v'", i, "bad arguments provided", err)
+		}
+	}
+
+	res := &TokenResult{}
+	if err := req.Create(&TokenParams{Scope: "write", Datasets: []string{"me/*"}}, res); err != nil {
+		t.Fatalf("error creating token: %s", err.Error())
+	}
+	if len(res.Token.Datasets) != 1 || res.Token.Datasets[0] != "peer/*" {
+		t.Errorf("expected 'me' to resolve to the profile peername, got: %v", res.Token.Datasets)
+	}
+	if tok, err := repo.VerifyToken(mr, res.Secret); err != nil || tok.ID != res.Token.ID {
+		t.Errorf("expected created token secret to verify, got: %v", err)
+	}
+
+	list := []*repo.Token{}
+	if err := req.List(&ListParams{}, &list); err != nil {
+		t.Fatalf("error listing tokens: %s", err.Error())
+	}
+	if len(list) != 1 {
+		t.Errorf("expected 1 token, got: %d", len(list))
+	}
+
+	if err := req.Create(&TokenParams{Scope: "read", Peer: "nobody"}, &TokenResult{}); err == nil || err.Error() != "unknown peer: nobody" {
+		t.Errorf("expected granting access to an unknown peer to error, got: %v", err)
+	}
+	pid := profile.IDB58MustDecode("QmUiF6GyKcNt3fbc9pCN72KF5qgneLt3eufVT3tGEBiR9h")
+	if err := mr.Profiles().PutProfile(&profile.Profile{ID: pid, Peername: "other"}); err != nil {
+		t.Fatal(err.Error())
+	}
+	grant := &TokenResult{}
+	if err := req.Create(&TokenParams{Scope: "read", Peer: "other"}, grant); err != nil {
+		t.Fatalf("error granting peer access: %s", err.Error())
+	}
+	if grant.Secret != "" || grant.Token.ProfileID != pid.String() {
+		t.Errorf("expected a peer grant without a secret, got: %#v", grant)
+	}
+	regrant := &TokenResult{}
+	if err := req.Create(&TokenParams{Scope: "write", Peer: pid.String()}, regrant); err != nil {
+		t.Fatalf("error granting peer access: %s", err.Error())
+	}
+	if regrant.Token.ID != grant.Token.ID {
+		t.Errorf("expected granting access again to replace the existing grant")
+	}
+	if err := req.Delete(&grant.Token.ID, new(bool)); err != nil {
+		t.Errorf("error deleting grant: %s", err.Error())
+	}
+
+	done := false
+	if err := req.Delete(&res.Token.ID, &done); err != nil {
+		t.Errorf("error deleting token: %s", err.Error())
+	}
+	if err := req.Delete(&res.Token.ID, &done); err == nil {
+		t.Errorf("expected deleting a missing token to error")
+	}
+}
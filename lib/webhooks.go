@@ -0,0 +1,442 @@
+package lib
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/rpc"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/cron"
+	"github.com/qri-io/qri/repo"
+)
+
+// WebhookRequests encapsulates business logic for inspecting webhooks
+type WebhookRequests struct {
+	cli  *rpc.Client
+	repo repo.Repo
+}
+
+// NewWebhookRequests creates a WebhookRequests pointer from either a repo
+// or an rpc.Client
+func NewWebhookRequests(r repo.Repo, cli *rpc.Client) *WebhookRequests {
+	if r != nil && cli != nil {
+		panic(fmt.Errorf("both repo and client supplied to NewWebhookRequests"))
+	}
+	return &WebhookRequests{
+		cli:  cli,
+		repo: r,
+	}
+}
+
+// CoreRequestsName implements the Requests interface
+func (WebhookRequests) CoreRequestsName() string { return "webhooks" }
+
+// Log lists webhook deliveries, most recent first
+func (r *WebhookRequests) Log(p *ListParams, res *[]*repo.WebhookDelivery) error {
+	if r.cli != nil {
+		return r.cli.Call("WebhookRequests.Log", p, res)
+	}
+
+	wl, ok := r.repo.(repo.WebhookLog)
+	if !ok {
+		return repo.ErrWebhooksNotSupported
+	}
+
+	deliveries, err := wl.Deliveries(p.Limit, p.Offset)
+	if err != nil {
+		return err
+	}
+	*res = deliveries
+	return nil
+}
+
+const (
+	// DefaultWebhookAttempts is the number of times a webhook delivery is
+	// attempted before giving up
+	DefaultWebhookAttempts = 5
+	// DefaultWebhookBackoff is the delay before retrying a failed delivery.
+	// the delay doubles after each failed attempt
+	DefaultWebhookBackoff = 2 * time.Second
+)
+
+const (
+	// WebhookEventHeader carries the type of event a webhook request is for
+	WebhookEventHeader = "X-Qri-Event"
+	// WebhookDeliveryHeader carries the delivery ID of a webhook request.
+	// retries of the same delivery share an ID
+	WebhookDeliveryHeader = "X-Qri-Delivery"
+	// WebhookSignatureHeader carries the signature of a webhook payload for
+	// webhooks that have a secret. see SignWebhookPayload
+	WebhookSignatureHeader = "X-Qri-Signature"
+)
+
+// WebhookPayload is the JSON body POSTed to webhooks
+type WebhookPayload struct {
+	// ID is the delivery ID
+	ID     string           `json:"id"`
+	Event  repo.EventType   `json:"event"`
+	Time   time.Time        `json:"time"`
+	Ref    *repo.DatasetRef `json:"ref,omitempty"`
+	PeerID string           `json:"peerID,omitempty"`
+}
+
+// SignWebhookPayload gives the signature of a webhook payload, in the form
+// "sha256=<hex-encoded HMAC-SHA256 of the body keyed with the secret>".
+// receivers compute the same value to confirm requests came from qri
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Webhooks sends repo events to configured webhooks. Failed deliveries are
+// retried with exponential backoff, and the outcome of each delivery is
+// recorded in the repo's delivery log
+type Webhooks struct {
+	// Client makes webhook requests
+	Client *http.Client
+	// Clock provides the current time, defaults to cron.RealClock
+	Clock cron.Clock
+	// MaxAttempts is the number of times a delivery is attempted
+	MaxAttempts int
+	// Backoff is the delay before the first retry of a failed delivery
+	Backoff time.Duration
+
+	repo  repo.Repo
+	hooks []*config.Webhook
+	// lk serializes writes to the delivery log
+	lk sync.Mutex
+}
+
+// NewWebhooks allocates a Webhooks from configuration
+func NewWebhooks(r repo.Repo, cfg *config.Webhooks) *Webhooks {
+	wh := &Webhooks{
+		Client:      &http.Client{Timeout: 10 * time.Second},
+		Clock:       cron.RealClock,
+		MaxAttempts: DefaultWebhookAttempts,
+		Backoff:     DefaultWebhookBackoff,
+		repo:        r,
+	}
+	if cfg != nil {
+		wh.hooks = cfg.Copy().Hooks
+	}
+	return wh
+}
+
+// Start sends published repo events to matching webhooks, blocking until
+// ctx is cancelled. For repos that implement repo.WebhookCursor, logged
+// events that haven't been sent yet are sent first, so events logged while
+// webhooks weren't running aren't missed. Events that were being sent when
+// ctx is cancelled are sent again the next time webhooks start
+func (wh *Webhooks) Start(ctx context.Context) error {
+	pub, ok := wh.repo.(repo.EventPublisher)
+	if !ok {
+		return fmt.Errorf("repo doesn't publish events")
+	}
+
+	// subscribe before reading the backlog so events logged in between
+	// aren't missed. events in both are only sent once
+	events := make(chan *repo.Event, 64)
+	unsubscribe := pub.Subscribe(events)
+	defer unsubscribe()
+
+	cursor, backlog, err := wh.backlog()
+	if err != nil {
+		return err
+	}
+	through := time.Time{}
+	if len(backlog) > 0 {
+		through = backlog[len(backlog)-1].Time
+	}
+
+	wg := &sync.WaitGroup{}
+	for _, e := range backlog {
+		wh.send(ctx, wg, cursor, e)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return ctx.Err()
+		case e := <-events:
+			if !e.Time.After(through) {
+				continue
+			}
+			wh.send(ctx, wg, cursor, e)
+		}
+	}
+}
+
+// backlog reads logged events that haven't been sent to webhooks. The
+// first time webhooks run there's no backlog, only new events are sent
+func (wh *Webhooks) backlog() (*webhookCursor, []*repo.Event, error) {
+	c := &webhookCursor{sending: map[*repo.Event]bool{}}
+	store, ok := wh.repo.(repo.WebhookCursor)
+	if !ok {
+		return c, nil, nil
+	}
+	c.store = store
+
+	at, err := store.WebhookCursor()
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading webhook cursor: %s", err.Error())
+	}
+	if at.IsZero() {
+		c.at = time.Now()
+		return c, nil, store.SetWebhookCursor(c.at)
+	}
+	c.at = at
+
+	backlog, err := wh.repo.EventsSince(at)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading webhook backlog: %s", err.Error())
+	}
+	return c, backlog, nil
+}
+
+// send delivers an event to matching webhooks in the background, marking
+// the event sent once all deliveries finish. Deliveries cut short by ctx
+// leave the event unsent
+func (wh *Webhooks) send(ctx context.Context, wg *sync.WaitGroup, c *webhookCursor, e *repo.Event) {
+	c.start(e)
+
+	cancelled := false
+	lk := sync.Mutex{}
+	sent := sync.WaitGroup{}
+	for _, hook := range wh.hooks {
+		if !wh.matches(hook, e) {
+			continue
+		}
+		sent.Add(1)
+		go func(hook *config.Webhook) {
+			defer sent.Done()
+			d, err := wh.Deliver(ctx, hook, e)
+			if err != nil {
+				log.Errorf("logging webhook delivery: %s", err.Error())
+			}
+			if ctx.Err() != nil && (d == nil || !d.Delivered) {
+				lk.Lock()
+				cancelled = true
+				lk.Unlock()
+			}
+		}(hook)
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		sent.Wait()
+		if !cancelled {
+			c.done(e)
+		}
+	}()
+}
+
+// webhookCursor tracks events being sent to webhooks, advancing the repo's
+// webhook cursor past events once they've been sent. The cursor never
+// passes an event that's still being sent
+type webhookCursor struct {
+	sync.Mutex
+	// store persists the cursor, nil if the repo can't
+	store repo.WebhookCursor
+	// at is the stored cursor time
+	at time.Time
+	// latest is the time of the latest event started
+	latest  time.Time
+	sending map[*repo.Event]bool
+}
+
+// start marks an event as being sent
+func (c *webhookCursor) start(e *repo.Event) {
+	c.Lock()
+	defer c.Unlock()
+	c.sending[e] = true
+	if e.Time.After(c.latest) {
+		c.latest = e.Time
+	}
+}
+
+// done marks an event as sent, advancing the cursor as far as it can go
+func (c *webhookCursor) done(e *repo.Event) {
+	c.Lock()
+	defer c.Unlock()
+	delete(c.sending, e)
+	if c.store == nil {
+		return
+	}
+
+	t := c.latest
+	for s := range c.sending {
+		if before := s.Time.Add(-time.Nanosecond); before.Before(t) {
+			t = before
+		}
+	}
+	if !t.After(c.at) {
+		return
+	}
+	if err := c.store.SetWebhookCursor(t); err != nil {
+		log.Errorf("saving webhook cursor: %s", err.Error())
+		return
+	}
+	c.at = t
+}
+
+// matches checks if an event should be sent to a webhook
+func (wh *Webhooks) matches(hook *config.Webhook, e *repo.Event) bool {
+	if len(hook.Events) > 0 {
+		found := false
+		for _, t := range hook.Events {
+			if repo.EventType(t) == e.Type {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if hook.Datasets == "" {
+		return true
+	}
+	if e.Ref.Peername == "" || e.Ref.Name == "" {
+		return false
+	}
+	pattern := hook.Datasets
+	if strings.HasPrefix(pattern, "me/") {
+		pro, err := wh.repo.Profile()
+		if err != nil {
+			return false
+		}
+		pattern = pro.Peername + strings.TrimPrefix(pattern, "me")
+	}
+	ok, _ := path.Match(pattern, e.Ref.Peername+"/"+e.Ref.Name)
+	return ok
+}
+
+// Deliver sends an event to a webhook, retrying failed attempts until the
+// webhook accepts the event, MaxAttempts is reached, or ctx is cancelled.
+// The delivery is added to the repo's delivery log. Deliver only errors if
+// the delivery can't be logged, check the returned delivery for the outcome
+func (wh *Webhooks) Deliver(ctx context.Context, hook *config.Webhook, e *repo.Event) (*repo.WebhookDelivery, error) {
+	id, err := newDeliveryID()
+	if err != nil {
+		return nil, err
+	}
+	d := &repo.WebhookDelivery{
+		ID:    id,
+		URL:   hook.URL,
+		Event: e.Type,
+		Ref:   e.Ref,
+		Time:  e.Time,
+	}
+
+	payload := WebhookPayload{ID: id, Event: e.Type, Time: e.Time}
+	if !e.Ref.IsEmpty() {
+		ref := e.Ref
+		payload.Ref = &ref
+	}
+	if e.PeerID != "" {
+		payload.PeerID = e.PeerID.Pretty()
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	backoff := wh.Backoff
+	for d.Attempts < wh.MaxAttempts {
+		if d.Attempts > 0 {
+			select {
+			case <-ctx.Done():
+			case <-wh.Clock.After(backoff):
+			}
+			if ctx.Err() != nil {
+				d.Error = fmt.Sprintf("delivery cancelled: %s", d.Error)
+				break
+			}
+			backoff *= 2
+		}
+
+		d.Attempts++
+		d.Status, err = wh.post(ctx, hook, d, body)
+		if err == nil {
+			d.Delivered = true
+			d.Error = ""
+			break
+		}
+		d.Error = err.Error()
+		if !retryable(d.Status) {
+			break
+		}
+	}
+	d.Completed = wh.Clock.Now()
+
+	if !d.Delivered {
+		log.Infof("webhook delivery to %s failed after %d attempts: %s", hook.URL, d.Attempts, d.Error)
+	}
+
+	wl, ok := wh.repo.(repo.WebhookLog)
+	if !ok {
+		return d, nil
+	}
+	wh.lk.Lock()
+	defer wh.lk.Unlock()
+	return d, wl.LogDelivery(d)
+}
+
+// post makes a single delivery attempt, returning the response status code
+func (wh *Webhooks) post(ctx context.Context, hook *config.Webhook, d *repo.WebhookDelivery, body []byte) (int, error) {
+	req, err := http.NewRequest("POST", hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "qri/"+VersionNumber)
+	req.Header.Set(WebhookEventHeader, string(d.Event))
+	req.Header.Set(WebhookDeliveryHeader, d.ID)
+	if hook.Secret != "" {
+		req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(hook.Secret, body))
+	}
+
+	res, err := wh.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	io.Copy(ioutil.Discard, io.LimitReader(res.Body, 1<<16))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("webhook responded with status %d", res.StatusCode)
+	}
+	return res.StatusCode, nil
+}
+
+// retryable checks if a failed attempt is worth retrying. requests that
+// didn't get a response, server errors, timeouts & rate limits are retried,
+// other client errors won't succeed on retry
+func retryable(status int) bool {
+	return status == 0 || status >= 500 || status == http.StatusRequestTimeout || status == http.StatusTooManyRequests
+}
+
+func newDeliveryID() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
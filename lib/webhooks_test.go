@@ -0,0 +1,217 @@
+package lib
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/repo"
+	testrepo "github.com/qri-io/qri/repo/test"
+)
+
+func TestWebhooksDeliver(t *testing.T) {
+	mr, err := testrepo.NewTestRepo(nil)
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err.Error())
+	}
+
+	failures := 2
+	var got *http.Request
+	var body []byte
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		got = r
+		body, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer s.Close()
+
+	start := time.Date(2018, 7, 4, 12, 0, 0, 0, time.UTC)
+	wh := NewWebhooks(mr, nil)
+	wh.Clock = &testClock{now: start}
+
+	hook := &config.Webhook{URL: s.URL, Secret: "secret"}
+	e := &repo.Event{Type: repo.ETDsCreated, Time: start, Ref: repo.DatasetRef{Peername: "peer", Name: "cities"}}
+	d, err := wh.Deliver(context.Background(), hook, e)
+	if err != nil {
+		t.Fatalf("error delivering webhook: %s", err.Error())
+	}
+	if !d.Delivered || d.Attempts != 3 || d.Status != http.StatusOK {
+		t.Errorf("expected delivery to succeed on the 3rd attempt, got: delivered: %t attempts: %d status: %d", d.Delivered, d.Attempts, d.Status)
+	}
+	// retries back off 2s, then 4s
+	if expect := start.Add(6 * time.Second); !d.Completed.Equal(expect) {
+		t.Errorf("completed time mismatch. expected: %s, got: %s", expect, d.Completed)
+	}
+
+	if got.Header.Get(WebhookEventHeader) != "ds_created" {
+		t.Errorf("event header mismatch. expected: %s, got: %s", "ds_created", got.Header.Get(WebhookEventHeader))
+	}
+	if got.Header.Get(WebhookDeliveryHeader) != d.ID {
+		t.Errorf("delivery header mismatch. expected: %s, got: %s", d.ID, got.Header.Get(WebhookDeliveryHeader))
+	}
+	if sig := SignWebhookPayload("secret", body); got.Header.Get(WebhookSignatureHeader) != sig {
+		t.Errorf("signature mismatch. expected: %s, got: %s", sig, got.Header.Get(WebhookSignatureHeader))
+	}
+
+	payload := WebhookPayload{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatal(err.Error())
+	}
+	if payload.ID != d.ID || payload.Event != repo.ETDsCreated || payload.Ref == nil || payload.Ref.AliasString() != "peer/cities" {
+		t.Errorf("unexpected payload: %s", string(body))
+	}
+
+	// client errors aren't retried
+	f := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer f.Close()
+
+	d, err = wh.Deliver(context.Background(), &config.Webhook{URL: f.URL}, e)
+	if err != nil {
+		t.Fatalf("error delivering webhook: %s", err.Error())
+	}
+	if d.Delivered || d.Attempts != 1 || d.Status != http.StatusUnauthorized {
+		t.Errorf("expected a single failed attempt, got: delivered: %t attempts: %d status: %d", d.Delivered, d.Attempts, d.Status)
+	}
+
+	deliveries := []*repo.WebhookDelivery{}
+	if err := NewWebhookRequests(mr, nil).Log(&ListParams{Limit: 10}, &deliveries); err != nil {
+		t.Fatalf("error listing deliveries: %s", err.Error())
+	}
+	if len(deliveries) != 2 {
+		t.Fatalf("expected 2 logged deliveries, got: %d", len(deliveries))
+	}
+	if deliveries[0].Delivered || !deliveries[1].Delivered {
+		t.Errorf("expected deliveries to be listed most recent first")
+	}
+}
+
+func TestWebhooksStart(t *testing.T) {
+	mr, err := testrepo.NewTestRepo(nil)
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err.Error())
+	}
+
+	lk := sync.Mutex{}
+	received := []string{}
+	delivered := make(chan bool, 10)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload := WebhookPayload{}
+		json.NewDecoder(r.Body).Decode(&payload)
+		lk.Lock()
+		received = append(received, string(payload.Event)+" "+payload.Ref.AliasString())
+		lk.Unlock()
+		delivered <- true
+	}))
+	defer s.Close()
+
+	wh := NewWebhooks(mr, &config.Webhooks{Hooks: []*config.Webhook{
+		{URL: s.URL, Events: []string{"ds_created"}, Datasets: "me/*"},
+	}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- wh.Start(ctx) }()
+	// give Start a moment to subscribe
+	time.Sleep(50 * time.Millisecond)
+
+	mr.LogEvent(repo.ETDsRenamed, repo.DatasetRef{Peername: "peer", Name: "movies"})
+	mr.LogEvent(repo.ETDsCreated, repo.DatasetRef{Peername: "other", Name: "movies"})
+	mr.LogEvent(repo.ETDsCreated, repo.DatasetRef{Peername: "peer", Name: "cities"})
+
+	select {
+	case <-delivered:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for webhook delivery")
+	}
+	cancel()
+	<-done
+
+	if len(received) != 1 || received[0] != "ds_created peer/cities" {
+		t.Errorf("expected only matching events to be delivered, got: %v", received)
+	}
+}
+
+func TestWebhooksStartBacklog(t *testing.T) {
+	mr, err := testrepo.NewTestRepo(nil)
+	if err != nil {
+		t.Fatalf("error allocating test repo: %s", err.Error())
+	}
+
+	received := make(chan string, 10)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload := WebhookPayload{}
+		json.NewDecoder(r.Body).Decode(&payload)
+		received <- payload.Ref.AliasString()
+	}))
+	defer s.Close()
+
+	wh := NewWebhooks(mr, &config.Webhooks{Hooks: []*config.Webhook{
+		{URL: s.URL, Events: []string{"ds_created"}},
+	}})
+	start := func() (stop func()) {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- wh.Start(ctx) }()
+		// give Start a moment to subscribe
+		time.Sleep(50 * time.Millisecond)
+		return func() {
+			cancel()
+			<-done
+		}
+	}
+
+	// the first run only records where webhooks started
+	start()()
+
+	// events logged while webhooks aren't running are sent on the next start
+	mr.LogEvent(repo.ETDsCreated, repo.DatasetRef{Peername: "peer", Name: "cities"})
+	mr.LogEvent(repo.ETDsCreated, repo.DatasetRef{Peername: "peer", Name: "movies"})
+	stop := start()
+	got := []string{}
+	for len(got) < 2 {
+		select {
+		case ref := <-received:
+			got = append(got, ref)
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for backlog delivery, got: %v", got)
+		}
+	}
+	stop()
+	sort.Strings(got)
+	if got[0] != "peer/cities" || got[1] != "peer/movies" {
+		t.Errorf("expected backlog events to be delivered, got: %v", got)
+	}
+
+	cursor, err := mr.WebhookCursor()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	latest, err := mr.Events(1, 0)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !cursor.Equal(latest[0].Time) {
+		t.Errorf("expected webhook cursor to advance to the last sent event. expected: %s, got: %s", latest[0].Time, cursor)
+	}
+
+	// sent events aren't sent again
+	start()()
+	select {
+	case ref := <-received:
+		t.Errorf("expected no deliveries after the backlog was sent, got: %s", ref)
+	default:
+	}
+}
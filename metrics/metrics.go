@@ -0,0 +1,312 @@
+// Package metrics is a small registry of counters, gauges & histograms that
+// describe how a qri node is doing. Packages record into the Default
+// registry, which is exposed in the prometheus text exposition format, see
+// https://prometheus.io/docs/instrumenting/exposition_formats
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Default is the registry qri packages record metrics into
+var Default = NewRegistry()
+
+// DefaultBuckets are histogram upper bounds suited to timing operations in
+// seconds
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60}
+
+// Registry holds a set of named metrics
+type Registry struct {
+	lk      sync.Mutex
+	metrics map[string]metric
+}
+
+// metric is the interface all metric types implement
+type metric interface {
+	// writeText writes the metric's samples in the text exposition format
+	writeText(w io.Writer) error
+}
+
+// NewRegistry allocates an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{metrics: map[string]metric{}}
+}
+
+// NewCounter creates a counter, or returns the counter already registered
+// with the same name. labels names the dimensions values are recorded in
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	r.lk.Lock()
+	defer r.lk.Unlock()
+	if m, ok := r.metrics[name]; ok {
+		return m.(*Counter)
+	}
+	c := &Counter{desc: desc{name: name, help: help, labels: labels}, series: map[string]*counterSeries{}}
+	r.metrics[name] = c
+	return c
+}
+
+// NewHistogram creates a histogram with the given bucket upper bounds, or
+// returns the histogram already registered with the same name
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	r.lk.Lock()
+	defer r.lk.Unlock()
+	if m, ok := r.metrics[name]; ok {
+		return m.(*Histogram)
+	}
+	bounds := append([]float64{}, buckets...)
+	sort.Float64s(bounds)
+	h := &Histogram{desc: desc{name: name, help: help, labels: labels}, buckets: bounds, series: map[string]*histogramSeries{}}
+	r.metrics[name] = h
+	return h
+}
+
+// NewGaugeFunc registers a gauge that's read by calling fn each time metrics
+// are written, replacing any gauge already registered with the same name.
+// the gauge is left out of output when fn returns an error
+func (r *Registry) NewGaugeFunc(name, help string, fn func() (float64, error)) {
+	r.lk.Lock()
+	defer r.lk.Unlock()
+	r.metrics[name] = &gaugeFunc{desc: desc{name: name, help: help}, fn: fn}
+}
+
+// WriteText writes all metrics in the prometheus text exposition format,
+// sorted by name
+func (r *Registry) WriteText(w io.Writer) error {
+	r.lk.Lock()
+	names := make([]string, 0, len(r.metrics))
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+	metrics := make([]metric, len(names))
+	sort.Strings(names)
+	for i, name := range names {
+		metrics[i] = r.metrics[name]
+	}
+	r.lk.Unlock()
+
+	for _, m := range metrics {
+		if err := m.writeText(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// desc describes a metric
+type desc struct {
+	name   string
+	help   string
+	labels []string
+}
+
+// header writes the HELP & TYPE lines of a metric
+func (d desc) header(w io.Writer, typ string) error {
+	_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", d.name, strings.Replace(d.help, "\n", " ", -1), d.name, typ)
+	return err
+}
+
+// labelValues normalizes values to the number of labels. missing values are
+// recorded as empty strings & extra values are dropped, so a mistake when
+// recording can't break the caller
+func (d desc) labelValues(values []string) []string {
+	res := make([]string, len(d.labels))
+	copy(res, values)
+	return res
+}
+
+// labelString formats label pairs, eg: {method="GET",status="200"}
+func (d desc) labelString(values []string, extra ...string) string {
+	pairs := []string{}
+	for i, l := range d.labels {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, l, escapeLabelValue(values[i])))
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, extra[i], escapeLabelValue(extra[i+1])))
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// Counter is a value that only goes up, like a count of requests served
+type Counter struct {
+	desc
+	lk     sync.Mutex
+	series map[string]*counterSeries
+}
+
+type counterSeries struct {
+	labels []string
+	value  float64
+}
+
+// Inc adds one to the counter for the given label values
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds v to the counter for the given label values. negative values are
+// ignored
+func (c *Counter) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		return
+	}
+	values := c.labelValues(labelValues)
+	key := strings.Join(values, "\xff")
+
+	c.lk.Lock()
+	defer c.lk.Unlock()
+	s, ok := c.series[key]
+	if !ok {
+		s = &counterSeries{labels: values}
+		c.series[key] = s
+	}
+	s.value += v
+}
+
+// Value returns the current count for the given label values
+func (c *Counter) Value(labelValues ...string) float64 {
+	key := strings.Join(c.labelValues(labelValues), "\xff")
+	c.lk.Lock()
+	defer c.lk.Unlock()
+	if s, ok := c.series[key]; ok {
+		return s.value
+	}
+	return 0
+}
+
+func (c *Counter) writeText(w io.Writer) error {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+	if err := c.header(w, "counter"); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(c.series))
+	for key := range c.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		s := c.series[key]
+		if _, err := fmt.Fprintf(w, "%s%s %s\n", c.name, c.labelString(s.labels), formatFloat(s.value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Histogram samples observations like request durations into buckets
+type Histogram struct {
+	desc
+	buckets []float64
+	lk      sync.Mutex
+	series  map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	labels []string
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// Observe records a value for the given label values
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	values := h.labelValues(labelValues)
+	key := strings.Join(values, "\xff")
+
+	h.lk.Lock()
+	defer h.lk.Unlock()
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{labels: values, counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+	for i, bound := range h.buckets {
+		if v <= bound {
+			s.counts[i]++
+		}
+	}
+	s.count++
+	s.sum += v
+}
+
+// Count returns the number of observations for the given label values
+func (h *Histogram) Count(labelValues ...string) uint64 {
+	key := strings.Join(h.labelValues(labelValues), "\xff")
+	h.lk.Lock()
+	defer h.lk.Unlock()
+	if s, ok := h.series[key]; ok {
+		return s.count
+	}
+	return 0
+}
+
+func (h *Histogram) writeText(w io.Writer) error {
+	h.lk.Lock()
+	defer h.lk.Unlock()
+	if err := h.header(w, "histogram"); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(h.series))
+	for key := range h.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		s := h.series[key]
+		for i, bound := range h.buckets {
+			if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labelString(s.labels, "le", formatFloat(bound)), s.counts[i]); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n%s_sum%s %s\n%s_count%s %d\n",
+			h.name, h.labelString(s.labels, "le", "+Inf"), s.count,
+			h.name, h.labelString(s.labels), formatFloat(s.sum),
+			h.name, h.labelString(s.labels), s.count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gaugeFunc is a gauge that's read when metrics are written
+type gaugeFunc struct {
+	desc
+	fn func() (float64, error)
+}
+
+func (g *gaugeFunc) writeText(w io.Writer) error {
+	v, err := g.fn()
+	if err != nil {
+		return nil
+	}
+	if err := g.header(w, "gauge"); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s %s\n", g.name, formatFloat(v))
+	return err
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func escapeLabelValue(v string) string {
+	v = strings.Replace(v, `\`, `\\`, -1)
+	v = strings.Replace(v, "\n", `\n`, -1)
+	return strings.Replace(v, `"`, `\"`, -1)
+}
@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestRegistryWriteText(t *testing.T) {
+	r := NewRegistry()
+
+	c := r.NewCounter("requests_total", "requests served", "method", "status")
+	c.Inc("GET", "200")
+	c.Inc("GET", "200")
+	c.Add(3, "POST", "500")
+	c.Add(-1, "POST", "500")
+	// extra values are dropped
+	c.Inc("GET", "404", "extra")
+	if r.NewCounter("requests_total", "requests served", "method", "status") != c {
+		t.Errorf("expected registering a counter twice to return the same counter")
+	}
+	if c.Value("GET", "200") != 2 {
+		t.Errorf("counter value mismatch. expected: %d, got: %f", 2, c.Value("GET", "200"))
+	}
+
+	h := r.NewHistogram("duration_seconds", "how long things took", []float64{1, 0.1}, "op")
+	h.Observe(0.05, "save")
+	h.Observe(0.5, "save")
+	h.Observe(2, `say "hi"`)
+	if h.Count("save") != 2 {
+		t.Errorf("histogram count mismatch. expected: %d, got: %d", 2, h.Count("save"))
+	}
+
+	r.NewGaugeFunc("peers", "connected peers", func() (float64, error) { return 4, nil })
+	r.NewGaugeFunc("broken", "can't be read", func() (float64, error) { return 0, fmt.Errorf("oh noes") })
+
+	buf := &bytes.Buffer{}
+	if err := r.WriteText(buf); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expect := `# HELP duration_seconds how long things took
+# TYPE duration_seconds histogram
+duration_seconds_bucket{op="save",le="0.1"} 1
+duration_seconds_bucket{op="save",le="1"} 2
+duration_seconds_bucket{op="save",le="+Inf"} 2
+duration_seconds_sum{op="save"} 0.55
+duration_seconds_count{op="save"} 2
+duration_seconds_bucket{op="say \"hi\"",le="0.1"} 0
+duration_seconds_bucket{op="say \"hi\"",le="1"} 0
+duration_seconds_bucket{op="say \"hi\"",le="+Inf"} 1
+duration_seconds_sum{op="say \"hi\""} 2
+duration_seconds_count{op="say \"hi\""} 1
+# HELP peers connected peers
+# TYPE peers gauge
+peers 4
+# HELP requests_total requests served
+# TYPE requests_total counter
+requests_total{method="GET",status="200"} 2
+requests_total{method="GET",status="404"} 1
+requests_total{method="POST",status="500"} 3
+`
+	if buf.String() != expect {
+		t.Errorf("output mismatch.\nexpected:\n%s\ngot:\n%s", expect, buf.String())
+	}
+}
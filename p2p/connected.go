@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"time"
 
+	"github.com/qri-io/qri/repo"
+
 	ma "gx/ipfs/QmWWQ2Txc2c6tqjsBpzg5Ar652cHPGNsQQp2SejkNmkUMb/go-multiaddr"
 	pstore "gx/ipfs/QmXauCuJzmzapetmC6W4TuDJLL1yFFrVzSHoWv8YdbmnxH/go-libp2p-peerstore"
 	peer "gx/ipfs/QmZoWKhxUmZ2seW4BzX6fJkNR8hh9PsGModr7q171yq2SS/go-libp2p-peer"
@@ -86,6 +88,7 @@ func (n *QriNode) handleConnected(ws *WrappedStream, msg Message) (hangup bool)
 		return
 	}
 	n.Host.Peerstore().AddAddrs(pinfo.ID, pinfo.Addrs, pstore.TempAddrTTL)
+	n.publishEvent(repo.ETPeerConnected, pinfo.ID, repo.DatasetRef{})
 
 	// request this peer's profile to connect two node's knowledge of each other
 	if _, err := n.RequestProfile(pinfo.ID); err != nil {
@@ -0,0 +1,21 @@
+package p2p
+
+import (
+	"fmt"
+
+	"github.com/qri-io/qri/metrics"
+)
+
+// messageCount tracks p2p messages by MsgType & direction, which is either
+// "sent" or "received"
+var messageCount = metrics.Default.NewCounter("qri_p2p_messages_total", "p2p messages sent & received by message type", "type", "direction")
+
+// registerMetrics records gauges describing this node's connections
+func (n *QriNode) registerMetrics() {
+	metrics.Default.NewGaugeFunc("qri_p2p_connected_peers", "number of qri peers this node is connected to", func() (float64, error) {
+		if !n.Online || n.Host == nil {
+			return 0, fmt.Errorf("node is offline")
+		}
+		return float64(len(n.ConnectedQriPeerIDs())), nil
+	})
+}
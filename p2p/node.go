@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/qri-io/cafs/ipfs"
 	"github.com/qri-io/qri/config"
@@ -179,6 +180,8 @@ func (n *QriNode) StartOnlineServices(bootstrapped func(string)) error {
 	// to start a node with *no* qri peers specified.
 	defer bootstrapped("")
 
+	n.registerMetrics()
+
 	go func() {
 		pInfo := <-bsPeers
 		bootstrapped(pInfo.ID.Pretty())
@@ -198,6 +201,14 @@ func (n *QriNode) ReceiveMessages() chan Message {
 	return r
 }
 
+// publishEvent notifies subscribers of the repo's event publisher, if it has
+// one, of something that happened on the network
+func (n *QriNode) publishEvent(t repo.EventType, pid peer.ID, ref repo.DatasetRef) {
+	if pub, ok := n.Repo.(repo.EventPublisher); ok {
+		pub.Publish(&repo.Event{Time: time.Now(), Type: t, PeerID: pid, Ref: ref})
+	}
+}
+
 func (n *QriNode) echoMessages() {
 	for {
 		msg := <-n.msgChan
@@ -352,10 +363,14 @@ func (n *QriNode) handleStream(ws *WrappedStream, replies chan Message) {
 
 		handler, ok := n.handlers[msg.Type]
 		if !ok {
+			// message types come from peers, count unrecognized types together
+			messageCount.Inc("unrecognized", "received")
 			log.Infof("peer %s sent unrecognized message type '%s', hanging up", n.ID, msg.Type)
 			break
 		}
 
+		messageCount.Inc(msg.Type.String(), "received")
+
 		if hangup := handler(ws, msg); hangup {
 			break
 		}
@@ -24,6 +24,7 @@ func TestPing(t *testing.T) {
 		t.Errorf("error connecting peers: %s", err.Error())
 	}
 
+	sent := messageCount.Value(MtPing.String(), "sent")
 	for i, p1 := range peers {
 		for _, p2 := range peers[i+1:] {
 			lat, err := p1.Ping(p2.ID)
@@ -34,4 +35,8 @@ func TestPing(t *testing.T) {
 			t.Logf("%s Ping: %s: %s", p1.ID, p2.ID, lat)
 		}
 	}
+
+	if got := messageCount.Value(MtPing.String(), "sent") - sent; got < 3 {
+		t.Errorf("expected at least 3 sent ping messages to be counted, got: %f", got)
+	}
 }
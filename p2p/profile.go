@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/qri-io/qri/config"
+	"github.com/qri-io/qri/repo"
 	"github.com/qri-io/qri/repo/profile"
 
 	peer "gx/ipfs/QmZoWKhxUmZ2seW4BzX6fJkNR8hh9PsGModr7q171yq2SS/go-libp2p-peer"
@@ -59,6 +60,7 @@ func (n *QriNode) RequestProfile(pid peer.ID) (*profile.Profile, error) {
 		log.Debug(err.Error())
 		return nil, err
 	}
+	n.publishEvent(repo.ETProfileReceived, pid, repo.DatasetRef{Peername: pro.Peername, ProfileID: pro.ID})
 
 	return pro, nil
 }
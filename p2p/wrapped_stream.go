@@ -58,6 +58,9 @@ func (ws *WrappedStream) sendMessage(msg Message) error {
 	err := ws.enc.Encode(&msg)
 	// Because output is buffered with bufio, we need to flush!
 	ws.w.Flush()
+	if err == nil {
+		messageCount.Inc(msg.Type.String(), "sent")
+	}
 	log.Debugf("%s '%s' -> %s", ws.stream.Conn().LocalPeer(), msg.Type, ws.stream.Conn().RemotePeer())
 	return err
 }
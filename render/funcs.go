@@ -0,0 +1,639 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	htmltemplate "html/template"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FuncMap is a map of helper function names to functions, compatible with
+// both html/template.FuncMap & text/template.FuncMap
+type FuncMap map[string]interface{}
+
+// HTMLFuncs returns helpers for HTML templates. tables and charts are
+// returned as trusted HTML
+func HTMLFuncs() FuncMap {
+	return FuncMap{
+		"formatNumber": FormatNumber,
+		"table": func(body interface{}) htmltemplate.HTML {
+			return htmltemplate.HTML(HTMLTable(body))
+		},
+		"chart": func(kind string, body interface{}, column interface{}) (htmltemplate.HTML, error) {
+			svg, err := Chart(kind, body, column)
+			return htmltemplate.HTML(svg), err
+		},
+		"autoChart": func(schema, body interface{}) (htmltemplate.HTML, error) {
+			svg, err := AutoChart(schema, body)
+			return htmltemplate.HTML(svg), err
+		},
+		"pagedTable": func(schema, body interface{}, pageSize int) htmltemplate.HTML {
+			return htmltemplate.HTML(PagedTable(schema, body, pageSize))
+		},
+	}
+}
+
+// TextFuncs returns helpers for plain text templates. tables are written as
+// markdown-style pipe tables, charts as SVG
+func TextFuncs() FuncMap {
+	return FuncMap{
+		"formatNumber": FormatNumber,
+		"table":        TextTable,
+		"chart":        Chart,
+		"autoChart":    AutoChart,
+		"pagedTable":   PagedTable,
+	}
+}
+
+// MarkdownFuncs returns helpers for markdown templates. markdown is
+// converted to HTML, so table cells are HTML-escaped
+func MarkdownFuncs() FuncMap {
+	funcs := TextFuncs()
+	funcs["table"] = MarkdownTable
+	return funcs
+}
+
+// FormatNumber writes a number with comma thousands separators, rounded to
+// an optional number of decimal places. Non-numeric values are returned
+// as-is
+func FormatNumber(v interface{}, decimals ...int) string {
+	f, ok := toFloat(v)
+	if !ok {
+		return valueString(v)
+	}
+
+	prec := -1
+	if len(decimals) > 0 {
+		prec = decimals[0]
+	}
+	str := strconv.FormatFloat(math.Abs(f), 'f', prec, 64)
+	intPart, fracPart := str, ""
+	if i := strings.IndexByte(str, '.'); i >= 0 {
+		intPart, fracPart = str[:i], str[i:]
+	}
+
+	buf := &bytes.Buffer{}
+	if f < 0 {
+		buf.WriteByte('-')
+	}
+	for i, r := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteRune(r)
+	}
+	buf.WriteString(fracPart)
+	return buf.String()
+}
+
+// HTMLTable writes a dataset body as an HTML table
+func HTMLTable(body interface{}) string {
+	header, rows := tableRows(body)
+	buf := &bytes.Buffer{}
+	buf.WriteString("<table>\n")
+	if len(header) > 0 {
+		buf.WriteString("  <thead><tr>")
+		for _, h := range header {
+			fmt.Fprintf(buf, "<th>%s</th>", html.EscapeString(h))
+		}
+		buf.WriteString("</tr></thead>\n")
+	}
+	buf.WriteString("  <tbody>\n")
+	for _, row := range rows {
+		buf.WriteString("    <tr>")
+		for _, cell := range row {
+			fmt.Fprintf(buf, "<td>%s</td>", html.EscapeString(valueString(cell)))
+		}
+		buf.WriteString("</tr>\n")
+	}
+	buf.WriteString("  </tbody>\n</table>")
+	return buf.String()
+}
+
+// MarkdownTable writes a dataset body as a markdown pipe table. Markdown
+// passes HTML through when it's converted, so cells are HTML-escaped
+func MarkdownTable(body interface{}) string {
+	return pipeTable(body, html.EscapeString)
+}
+
+// TextTable writes a dataset body as a plain text pipe table
+func TextTable(body interface{}) string {
+	return pipeTable(body, nil)
+}
+
+// pipeTable writes a dataset body as a pipe table, passing each cell through
+// escape if it isn't nil
+func pipeTable(body interface{}, escape func(string) string) string {
+	header, rows := tableRows(body)
+	if len(header) == 0 {
+		return ""
+	}
+
+	cell := func(v interface{}) string {
+		str := valueString(v)
+		if escape != nil {
+			str = escape(str)
+		}
+		return strings.Replace(strings.Replace(str, "|", "\\|", -1), "\n", " ", -1)
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("|")
+	for _, h := range header {
+		buf.WriteString(" " + cell(h) + " |")
+	}
+	buf.WriteString("\n|")
+	for range header {
+		buf.WriteString(" --- |")
+	}
+	for _, row := range rows {
+		buf.WriteString("\n|")
+		for i := range header {
+			var v interface{}
+			if i < len(row) {
+				v = row[i]
+			}
+			buf.WriteString(" " + cell(v) + " |")
+		}
+	}
+	buf.WriteString("\n")
+	return buf.String()
+}
+
+// tableRows normalizes a dataset body into a header & rows of cells. Array
+// bodies of array rows use column indexes for the header, array bodies of
+// object rows use the sorted keys of the first row, object bodies become
+// key, value pairs
+func tableRows(body interface{}) (header []string, rows [][]interface{}) {
+	switch b := body.(type) {
+	case []interface{}:
+		if len(b) == 0 {
+			return nil, nil
+		}
+		if first, ok := b[0].(map[string]interface{}); ok {
+			header = sortedKeys(first)
+			for _, r := range b {
+				obj, _ := r.(map[string]interface{})
+				row := make([]interface{}, len(header))
+				for i, key := range header {
+					row[i] = obj[key]
+				}
+				rows = append(rows, row)
+			}
+			return
+		}
+
+		width := 0
+		for _, r := range b {
+			row, ok := r.([]interface{})
+			if !ok {
+				row = []interface{}{r}
+			}
+			if len(row) > width {
+				width = len(row)
+			}
+			rows = append(rows, row)
+		}
+		for i := 0; i < width; i++ {
+			header = append(header, strconv.Itoa(i))
+		}
+		return
+	case map[string]interface{}:
+		header = []string{"key", "value"}
+		for _, key := range sortedKeys(b) {
+			rows = append(rows, []interface{}{key, b[key]})
+		}
+		return
+	}
+	return nil, nil
+}
+
+// Chart draws an inline SVG chart of a single column of a dataset body.
+// kind is one of "bar", "line" or "histogram". column is either an integer
+// index for bodies of array rows, or a key for bodies of object rows
+func Chart(kind string, body interface{}, column interface{}) (string, error) {
+	labels, values, err := columnValues(body, column)
+	if err != nil {
+		return "", err
+	}
+
+	switch kind {
+	case "bar":
+		return barChart(labels, values), nil
+	case "line":
+		return lineChart(values), nil
+	case "histogram":
+		return histogram(values), nil
+	default:
+		return "", fmt.Errorf("unknown chart type: '%s'. supported types are bar, line, histogram", kind)
+	}
+}
+
+// maxBarChartRows is the most rows AutoChart will draw as a bar chart before
+// switching to a histogram
+const maxBarChartRows = 30
+
+// AutoChart picks a chart for a body based on schema column types, charting
+// the first numeric column. Bodies with a string column are drawn as bar
+// charts labelled by that column, or histograms when there are too many rows
+// for bars. Bodies without a string column are drawn as line charts. Bodies
+// without numeric columns produce no chart
+func AutoChart(schema, body interface{}) (string, error) {
+	rows, ok := body.([]interface{})
+	if !ok || len(rows) == 0 {
+		return "", nil
+	}
+
+	var numeric, label *Column
+	for _, col := range SchemaColumns(schema, body) {
+		c := col
+		switch col.Type {
+		case "number", "integer":
+			if numeric == nil {
+				numeric = &c
+			}
+		case "string":
+			if label == nil {
+				label = &c
+			}
+		}
+	}
+
+	switch {
+	case numeric == nil:
+		return "", nil
+	case label == nil:
+		return Chart("line", body, numeric.key())
+	case len(rows) > maxBarChartRows:
+		return Chart("histogram", body, numeric.key())
+	default:
+		return Chart("bar", body, numeric.key())
+	}
+}
+
+// Column describes a column of a dataset body
+type Column struct {
+	Title string
+	Type  string
+	// Index is the position of the column in array rows
+	Index int
+	// Object is true when rows are objects, & the column is accessed by title
+	Object bool
+}
+
+// key returns the value used to look up this column in a row
+func (c Column) key() interface{} {
+	if c.Object {
+		return c.Title
+	}
+	return c.Index
+}
+
+// SchemaColumns lists the columns of a body, reading titles & types from
+// the items of a jsonschema when possible, falling back to inspecting the
+// first row of the body
+func SchemaColumns(schema, body interface{}) (cols []Column) {
+	sch := jsonMap(schema)
+	items, _ := sch["items"].(map[string]interface{})
+
+	switch its := items["items"].(type) {
+	case []interface{}:
+		for i, it := range its {
+			col, _ := it.(map[string]interface{})
+			title, _ := col["title"].(string)
+			typ, _ := col["type"].(string)
+			if title == "" {
+				title = strconv.Itoa(i)
+			}
+			cols = append(cols, Column{Title: title, Type: typ, Index: i})
+		}
+		return
+	}
+
+	if props, ok := items["properties"].(map[string]interface{}); ok {
+		for i, key := range sortedKeys(props) {
+			col, _ := props[key].(map[string]interface{})
+			typ, _ := col["type"].(string)
+			cols = append(cols, Column{Title: key, Type: typ, Index: i, Object: true})
+		}
+		return
+	}
+
+	// no usable schema, infer from the first row
+	rows, _ := body.([]interface{})
+	if len(rows) == 0 {
+		return nil
+	}
+	switch row := rows[0].(type) {
+	case []interface{}:
+		for i, v := range row {
+			cols = append(cols, Column{Title: strconv.Itoa(i), Type: valueType(v), Index: i})
+		}
+	case map[string]interface{}:
+		for i, key := range sortedKeys(row) {
+			cols = append(cols, Column{Title: key, Type: valueType(row[key]), Index: i, Object: true})
+		}
+	}
+	return
+}
+
+// PagedTable writes an HTML table of a body split into pages of pageSize
+// rows. Pages are switched with anchor links & CSS :target selectors, so
+// paging works without javascript. Column titles are read from schema
+func PagedTable(schema, body interface{}, pageSize int) string {
+	_, rows := tableRows(body)
+	cols := SchemaColumns(schema, body)
+	if pageSize <= 0 {
+		pageSize = len(rows)
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString(`<div class="paged-table">`)
+	buf.WriteString(`<style type="text/css">.paged-table .page { display: none; } .paged-table .page:target, .paged-table .page:last-child { display: block; } .paged-table .page:target ~ .page:last-child { display: none; }</style>`)
+
+	var pages []string
+	for start := 0; start < len(rows) || start == 0; start += pageSize {
+		end := start + pageSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		page := &bytes.Buffer{}
+		page.WriteString("<table><thead><tr>")
+		for _, col := range cols {
+			fmt.Fprintf(page, "<th>%s</th>", html.EscapeString(col.Title))
+		}
+		page.WriteString("</tr></thead><tbody>")
+		for _, row := range rows[start:end] {
+			page.WriteString("<tr>")
+			for _, cell := range row {
+				fmt.Fprintf(page, "<td>%s</td>", html.EscapeString(valueString(cell)))
+			}
+			page.WriteString("</tr>")
+		}
+		page.WriteString("</tbody></table>")
+		pages = append(pages, page.String())
+		if end == len(rows) {
+			break
+		}
+	}
+
+	nav := &bytes.Buffer{}
+	if len(pages) > 1 {
+		nav.WriteString(`<nav class="pages">`)
+		for i := range pages {
+			fmt.Fprintf(nav, `<a href="#page-%d">%d</a> `, i+1, i+1)
+		}
+		nav.WriteString("</nav>")
+	}
+
+	// the first page is written last so it shows by default, see styles above
+	for i := len(pages) - 1; i > 0; i-- {
+		fmt.Fprintf(buf, `<div class="page" id="page-%d">%s%s</div>`, i+1, pages[i], nav.String())
+	}
+	fmt.Fprintf(buf, `<div class="page" id="page-1">%s%s</div>`, pages[0], nav.String())
+	buf.WriteString("</div>")
+	return buf.String()
+}
+
+const (
+	chartWidth  = 600
+	chartHeight = 300
+	chartColor  = "#0061A6"
+)
+
+func barChart(labels []string, values []float64) string {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, `<svg class="chart bar" xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`, chartWidth, chartHeight, chartWidth, chartHeight)
+	if len(values) > 0 {
+		max := 0.0
+		for _, v := range values {
+			max = math.Max(max, v)
+		}
+		if max == 0 {
+			max = 1
+		}
+		slot := float64(chartWidth) / float64(len(values))
+		for i, v := range values {
+			h := math.Max(v, 0) / max * chartHeight
+			fmt.Fprintf(buf, `<rect x="%s" y="%s" width="%s" height="%s" fill="%s"><title>%s: %s</title></rect>`,
+				svgNum(float64(i)*slot+slot*0.1), svgNum(chartHeight-h), svgNum(slot*0.8), svgNum(h), chartColor,
+				html.EscapeString(labels[i]), FormatNumber(v))
+		}
+	}
+	buf.WriteString("</svg>")
+	return buf.String()
+}
+
+func lineChart(values []float64) string {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, `<svg class="chart line" xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`, chartWidth, chartHeight, chartWidth, chartHeight)
+	if len(values) > 0 {
+		min, max := values[0], values[0]
+		for _, v := range values {
+			min, max = math.Min(min, v), math.Max(max, v)
+		}
+		span := max - min
+		if span == 0 {
+			span = 1
+		}
+		step := 0.0
+		if len(values) > 1 {
+			step = float64(chartWidth) / float64(len(values)-1)
+		}
+		points := make([]string, len(values))
+		for i, v := range values {
+			points[i] = svgNum(float64(i)*step) + "," + svgNum(chartHeight-(v-min)/span*chartHeight)
+		}
+		fmt.Fprintf(buf, `<polyline fill="none" stroke="%s" stroke-width="2" points="%s"/>`, chartColor, strings.Join(points, " "))
+	}
+	buf.WriteString("</svg>")
+	return buf.String()
+}
+
+// histogramBins is the max number of bins histograms divide values into
+const histogramBins = 20
+
+func histogram(values []float64) string {
+	if len(values) == 0 {
+		return barChart(nil, nil)
+	}
+
+	min, max := values[0], values[0]
+	for _, v := range values {
+		min, max = math.Min(min, v), math.Max(max, v)
+	}
+
+	bins := int(math.Ceil(math.Sqrt(float64(len(values)))))
+	if bins > histogramBins {
+		bins = histogramBins
+	}
+	width := (max - min) / float64(bins)
+	if width == 0 {
+		bins, width = 1, 1
+	}
+
+	counts := make([]float64, bins)
+	labels := make([]string, bins)
+	for i := range labels {
+		lo := min + float64(i)*width
+		labels[i] = FormatNumber(lo, 2) + " - " + FormatNumber(lo+width, 2)
+	}
+	for _, v := range values {
+		i := int((v - min) / width)
+		if i >= bins {
+			i = bins - 1
+		}
+		counts[i]++
+	}
+
+	return strings.Replace(barChart(labels, counts), `class="chart bar"`, `class="chart histogram"`, 1)
+}
+
+// columnValues pulls numeric values for a column out of a body, along with
+// a label for each value. labels are the first string cell in array rows,
+// or the row index
+func columnValues(body interface{}, column interface{}) (labels []string, values []float64, err error) {
+	rows, ok := body.([]interface{})
+	if !ok {
+		return nil, nil, fmt.Errorf("charts require an array body")
+	}
+
+	for i, r := range rows {
+		var cell interface{}
+		label := strconv.Itoa(i)
+		switch row := r.(type) {
+		case []interface{}:
+			idx, ok := toFloat(column)
+			if !ok {
+				return nil, nil, fmt.Errorf("column for array rows must be a number, got: %v", column)
+			}
+			if int(idx) < len(row) {
+				cell = row[int(idx)]
+			}
+			for _, c := range row {
+				if s, ok := c.(string); ok {
+					label = s
+					break
+				}
+			}
+		case map[string]interface{}:
+			cell = row[valueString(column)]
+		default:
+			cell = row
+		}
+
+		v, ok := toFloat(cell)
+		if !ok {
+			continue
+		}
+		labels = append(labels, label)
+		values = append(values, v)
+	}
+	return
+}
+
+// jsonMap converts a value to a generic JSON object by round-tripping
+// through encoding/json, returning nil if v isn't an object
+func jsonMap(v interface{}) map[string]interface{} {
+	if m, ok := v.(map[string]interface{}); ok {
+		return m
+	}
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	m := map[string]interface{}{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil
+	}
+	return m
+}
+
+// valueType returns the jsonschema type name of a body value
+func valueType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	if _, ok := toFloat(v); ok {
+		return "number"
+	}
+	return ""
+}
+
+func svgNum(f float64) string {
+	return strconv.FormatFloat(f, 'f', 2, 64)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// valueString writes a body value as a string
+func valueString(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case bool, int, int64, json.Number:
+		return fmt.Sprint(x)
+	case map[string]interface{}, []interface{}:
+		data, err := json.Marshal(x)
+		if err != nil {
+			return fmt.Sprint(x)
+		}
+		return string(data)
+	}
+	return fmt.Sprint(v)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,284 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// MustacheEngine renders Mustache-compatible templates. It supports
+// variables, unescaped variables ({{{name}}} and {{& name}}), sections,
+// inverted sections & comments. Partials and delimiter changes are not
+// supported. Data is converted to JSON before rendering, so fields are
+// accessed by their JSON names, eg: {{meta.title}}.
+//
+// As an extension helper functions can be called by adding arguments to a
+// tag: {{formatNumber structure.entries}}, {{chart "bar" body 1}}.
+// helper output is not escaped
+type MustacheEngine struct{}
+
+// ContentType implements the ContentTyper interface
+func (MustacheEngine) ContentType() string { return contentTypeHTML }
+
+// Render implements the Engine interface
+func (MustacheEngine) Render(w io.Writer, tmpl []byte, data interface{}) error {
+	nodes, err := parseMustache(string(tmpl))
+	if err != nil {
+		return fmt.Errorf("parsing template: %s", err.Error())
+	}
+
+	ctx, err := jsonContext(data)
+	if err != nil {
+		return err
+	}
+
+	return renderMustache(w, nodes, []interface{}{ctx}, HTMLFuncs())
+}
+
+type mustacheNodeType int
+
+const (
+	mnText mustacheNodeType = iota
+	mnVar
+	mnRawVar
+	mnSection
+	mnInverted
+)
+
+type mustacheNode struct {
+	typ      mustacheNodeType
+	text     string
+	children []*mustacheNode
+}
+
+func parseMustache(tmpl string) ([]*mustacheNode, error) {
+	root := &mustacheNode{typ: mnSection}
+	stack := []*mustacheNode{root}
+	for len(tmpl) > 0 {
+		parent := stack[len(stack)-1]
+		start := strings.Index(tmpl, "{{")
+		if start < 0 {
+			parent.children = append(parent.children, &mustacheNode{typ: mnText, text: tmpl})
+			break
+		}
+		if start > 0 {
+			parent.children = append(parent.children, &mustacheNode{typ: mnText, text: tmpl[:start]})
+		}
+		tmpl = tmpl[start+2:]
+
+		closer := "}}"
+		if strings.HasPrefix(tmpl, "{") {
+			closer = "}}}"
+		}
+		end := strings.Index(tmpl, closer)
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed tag: {{%s", tmpl)
+		}
+		tag := tmpl[:end]
+		tmpl = tmpl[end+len(closer):]
+
+		if closer == "}}}" {
+			parent.children = append(parent.children, &mustacheNode{typ: mnRawVar, text: strings.TrimSpace(tag[1:])})
+			continue
+		}
+		if len(tag) == 0 {
+			return nil, fmt.Errorf("empty tag")
+		}
+
+		name := strings.TrimSpace(tag[1:])
+		switch tag[0] {
+		case '!':
+			// comment
+		case '&':
+			parent.children = append(parent.children, &mustacheNode{typ: mnRawVar, text: name})
+		case '#', '^':
+			typ := mnSection
+			if tag[0] == '^' {
+				typ = mnInverted
+			}
+			n := &mustacheNode{typ: typ, text: name}
+			parent.children = append(parent.children, n)
+			stack = append(stack, n)
+		case '/':
+			if len(stack) == 1 || parent.text != name {
+				return nil, fmt.Errorf("unexpected closing tag: {{/%s}}", name)
+			}
+			stack = stack[:len(stack)-1]
+		case '>', '=':
+			return nil, fmt.Errorf("partials & delimiter changes are not supported: {{%s}}", tag)
+		default:
+			parent.children = append(parent.children, &mustacheNode{typ: mnVar, text: strings.TrimSpace(tag)})
+		}
+	}
+
+	if len(stack) > 1 {
+		return nil, fmt.Errorf("unclosed section: {{#%s}}", stack[len(stack)-1].text)
+	}
+	return root.children, nil
+}
+
+func renderMustache(w io.Writer, nodes []*mustacheNode, stack []interface{}, funcs FuncMap) (err error) {
+	for _, n := range nodes {
+		switch n.typ {
+		case mnText:
+			_, err = io.WriteString(w, n.text)
+		case mnVar, mnRawVar:
+			if fields := strings.Fields(n.text); len(fields) > 1 {
+				var out string
+				if out, err = callMustacheHelper(fields, stack, funcs); err != nil {
+					return err
+				}
+				_, err = io.WriteString(w, out)
+				break
+			}
+			str := valueString(mustacheLookup(n.text, stack))
+			if n.typ == mnVar {
+				str = html.EscapeString(str)
+			}
+			_, err = io.WriteString(w, str)
+		case mnSection:
+			val := mustacheLookup(n.text, stack)
+			if !mustacheTruthy(val) {
+				break
+			}
+			if list, ok := val.([]interface{}); ok {
+				for _, item := range list {
+					if err = renderMustache(w, n.children, append(stack, item), funcs); err != nil {
+						return err
+					}
+				}
+				break
+			}
+			err = renderMustache(w, n.children, append(stack, val), funcs)
+		case mnInverted:
+			if !mustacheTruthy(mustacheLookup(n.text, stack)) {
+				err = renderMustache(w, n.children, stack, funcs)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mustacheLookup resolves a possibly-dotted name against a context stack,
+// searching from the top of the stack down for the first name segment
+func mustacheLookup(name string, stack []interface{}) interface{} {
+	if name == "." {
+		return stack[len(stack)-1]
+	}
+
+	parts := strings.Split(name, ".")
+	for i := len(stack) - 1; i >= 0; i-- {
+		val, ok := mustacheChild(stack[i], parts[0])
+		if !ok {
+			continue
+		}
+		for _, part := range parts[1:] {
+			if val, ok = mustacheChild(val, part); !ok {
+				return nil
+			}
+		}
+		return val
+	}
+	return nil
+}
+
+func mustacheChild(ctx interface{}, name string) (interface{}, bool) {
+	switch c := ctx.(type) {
+	case map[string]interface{}:
+		val, ok := c[name]
+		return val, ok
+	case []interface{}:
+		i, err := strconv.Atoi(name)
+		if err != nil || i < 0 || i >= len(c) {
+			return nil, false
+		}
+		return c[i], true
+	}
+	return nil, false
+}
+
+func mustacheTruthy(val interface{}) bool {
+	switch v := val.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case []interface{}:
+		return len(v) > 0
+	}
+	return true
+}
+
+// callMustacheHelper calls a helper function named by the first field of a
+// tag. remaining fields are arguments: quoted strings, numbers, or names to
+// look up in the context stack
+func callMustacheHelper(fields []string, stack []interface{}, funcs FuncMap) (string, error) {
+	fn, ok := funcs[fields[0]]
+	if !ok {
+		return "", fmt.Errorf("unknown helper function: %s", fields[0])
+	}
+	fv := reflect.ValueOf(fn)
+	ft := fv.Type()
+
+	args := make([]reflect.Value, len(fields)-1)
+	for i, field := range fields[1:] {
+		var val interface{}
+		if s, err := strconv.Unquote(field); err == nil {
+			val = s
+		} else if f, err := strconv.ParseFloat(field, 64); err == nil {
+			val = f
+		} else {
+			val = mustacheLookup(field, stack)
+		}
+
+		var at reflect.Type
+		switch {
+		case ft.IsVariadic() && i >= ft.NumIn()-1:
+			at = ft.In(ft.NumIn() - 1).Elem()
+		case i < ft.NumIn():
+			at = ft.In(i)
+		default:
+			return "", fmt.Errorf("too many arguments to %s", fields[0])
+		}
+
+		if val == nil {
+			args[i] = reflect.Zero(at)
+			continue
+		}
+		av := reflect.ValueOf(val)
+		if !av.Type().ConvertibleTo(at) {
+			return "", fmt.Errorf("invalid argument to %s: can't use %v as %s", fields[0], val, at)
+		}
+		args[i] = av.Convert(at)
+	}
+	if n := ft.NumIn(); ft.IsVariadic() && len(args) < n-1 || !ft.IsVariadic() && len(args) != n {
+		return "", fmt.Errorf("wrong number of arguments to %s", fields[0])
+	}
+
+	out := fv.Call(args)
+	if len(out) == 2 && !out[1].IsNil() {
+		return "", out[1].Interface().(error)
+	}
+	return fmt.Sprint(out[0].Interface()), nil
+}
+
+// jsonContext converts data to generic JSON values, so templates address
+// fields the same way they appear in JSON
+func jsonContext(data interface{}) (interface{}, error) {
+	enc, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("encoding template data: %s", err.Error())
+	}
+	var ctx interface{}
+	err = json.Unmarshal(enc, &ctx)
+	return ctx, err
+}
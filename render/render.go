@@ -0,0 +1,185 @@
+// Package render executes templates against datasets. Templates are written
+// for one of a set of registered engines, selected by format name:
+// Go html/template ("html"), Go text/template ("text"), Markdown rendered to
+// HTML ("markdown"), and a simple Mustache-compatible engine ("mustache").
+// All engines get a common set of helper functions for formatting numbers &
+// drawing tables and charts over a dataset body
+package render
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"sort"
+	"sync"
+	texttemplate "text/template"
+
+	"github.com/russross/blackfriday"
+)
+
+// TemplateName is the name given to templates parsed by go template engines
+const TemplateName = "template"
+
+const (
+	// FormatHTML uses go's html/template package
+	FormatHTML = "html"
+	// FormatText uses go's text/template package
+	FormatText = "text"
+	// FormatMarkdown executes a text/template, rendering the result from
+	// markdown to HTML
+	FormatMarkdown = "markdown"
+	// FormatMustache uses a Mustache-compatible engine
+	FormatMustache = "mustache"
+)
+
+// Engine executes a template against data, writing the result to w
+type Engine interface {
+	Render(w io.Writer, tmpl []byte, data interface{}) error
+}
+
+// ContentTyper is implemented by engines that know the media type of the
+// output they render
+type ContentTyper interface {
+	ContentType() string
+}
+
+// contentTypeText is assumed for output of engines that don't implement
+// ContentTyper, so unknown output is never interpreted as HTML
+const contentTypeText = "text/plain; charset=utf-8"
+
+// contentTypeHTML is the media type of engines that render HTML
+const contentTypeHTML = "text/html; charset=utf-8"
+
+var (
+	enginesLk sync.RWMutex
+	engines   = map[string]Engine{
+		FormatHTML:     HTMLEngine{},
+		FormatText:     TextEngine{},
+		FormatMarkdown: MarkdownEngine{},
+		FormatMustache: MustacheEngine{},
+	}
+	// aliases maps alternate format names to registered engines
+	aliases = map[string]string{
+		"":          FormatHTML,
+		"md":        FormatMarkdown,
+		"txt":       FormatText,
+		"mst":       FormatMustache,
+		"moustache": FormatMustache,
+	}
+)
+
+// Register adds an engine for a format name, replacing any existing engine
+func Register(format string, e Engine) {
+	enginesLk.Lock()
+	defer enginesLk.Unlock()
+	engines[format] = e
+}
+
+// Lookup gets the engine for a format name. An empty format selects
+// the html engine
+func Lookup(format string) (Engine, error) {
+	enginesLk.RLock()
+	defer enginesLk.RUnlock()
+	if name, ok := aliases[format]; ok {
+		format = name
+	}
+	e, ok := engines[format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported template format: '%s'. supported formats are: %v", format, formats())
+	}
+	return e, nil
+}
+
+// ContentType gets the media type of output rendered in a format, for
+// example to set as the Content-Type header of an HTTP response
+func ContentType(format string) string {
+	e, err := Lookup(format)
+	if err != nil {
+		return contentTypeText
+	}
+	if ct, ok := e.(ContentTyper); ok {
+		return ct.ContentType()
+	}
+	return contentTypeText
+}
+
+// Formats lists the names of all registered engines
+func Formats() []string {
+	enginesLk.RLock()
+	defer enginesLk.RUnlock()
+	return formats()
+}
+
+func formats() []string {
+	names := make([]string, 0, len(engines))
+	for name := range engines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Render executes a template of the given format against data
+func Render(format string, tmpl []byte, data interface{}) ([]byte, error) {
+	e, err := Lookup(format)
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	if err := e.Render(buf, tmpl, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// HTMLEngine renders go html/template templates
+type HTMLEngine struct{}
+
+// ContentType implements the ContentTyper interface
+func (HTMLEngine) ContentType() string { return contentTypeHTML }
+
+// Render implements the Engine interface
+func (HTMLEngine) Render(w io.Writer, tmpl []byte, data interface{}) error {
+	t, err := htmltemplate.New(TemplateName).Funcs(htmltemplate.FuncMap(HTMLFuncs())).Parse(string(tmpl))
+	if err != nil {
+		return fmt.Errorf("parsing template: %s", err.Error())
+	}
+	return t.Execute(w, data)
+}
+
+// TextEngine renders go text/template templates
+type TextEngine struct{}
+
+// ContentType implements the ContentTyper interface
+func (TextEngine) ContentType() string { return contentTypeText }
+
+// Render implements the Engine interface
+func (TextEngine) Render(w io.Writer, tmpl []byte, data interface{}) error {
+	t, err := texttemplate.New(TemplateName).Funcs(texttemplate.FuncMap(TextFuncs())).Parse(string(tmpl))
+	if err != nil {
+		return fmt.Errorf("parsing template: %s", err.Error())
+	}
+	return t.Execute(w, data)
+}
+
+// MarkdownEngine executes a go text/template that produces markdown,
+// converting the result to HTML
+type MarkdownEngine struct{}
+
+// ContentType implements the ContentTyper interface
+func (MarkdownEngine) ContentType() string { return contentTypeHTML }
+
+// Render implements the Engine interface
+func (MarkdownEngine) Render(w io.Writer, tmpl []byte, data interface{}) error {
+	t, err := texttemplate.New(TemplateName).Funcs(texttemplate.FuncMap(MarkdownFuncs())).Parse(string(tmpl))
+	if err != nil {
+		return fmt.Errorf("parsing template: %s", err.Error())
+	}
+	md := &bytes.Buffer{}
+	if err := t.Execute(md, data); err != nil {
+		return err
+	}
+	_, err = w.Write(blackfriday.MarkdownCommon(md.Bytes()))
+	return err
+}
@@ -0,0 +1,202 @@
+package render
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	for _, format := range []string{"", "html", "text", "markdown", "md", "mustache"} {
+		if _, err := Lookup(format); err != nil {
+			t.Errorf("format '%s' error: %s", format, err)
+		}
+	}
+	if _, err := Lookup("pdf"); err == nil {
+		t.Errorf("expected unsupported format to error")
+	}
+
+	Register("shout", TextEngine{})
+	if _, err := Lookup("shout"); err != nil {
+		t.Errorf("expected registered engine to be found. got: %s", err)
+	}
+}
+
+func TestContentType(t *testing.T) {
+	cases := []struct {
+		format, expect string
+	}{
+		{"", "text/html; charset=utf-8"},
+		{"html", "text/html; charset=utf-8"},
+		{"markdown", "text/html; charset=utf-8"},
+		{"mustache", "text/html; charset=utf-8"},
+		{"text", "text/plain; charset=utf-8"},
+		{"pdf", "text/plain; charset=utf-8"},
+	}
+	for i, c := range cases {
+		if got := ContentType(c.format); got != c.expect {
+			t.Errorf("case %d mismatch. expected: '%s', got: '%s'", i, c.expect, got)
+		}
+	}
+}
+
+func TestMarkdownTable(t *testing.T) {
+	body := []interface{}{[]interface{}{"<script>alert('hi')</script>", "a|b"}}
+	expect := "| 0 | 1 |\n| --- | --- |\n| &lt;script&gt;alert(&#39;hi&#39;)&lt;/script&gt; | a\\|b |\n"
+	if got := MarkdownTable(body); got != expect {
+		t.Errorf("result mismatch. expected: '%s', got: '%s'", expect, got)
+	}
+}
+
+type testData struct {
+	Name string        `json:"name"`
+	Body []interface{} `json:"body"`
+}
+
+func TestRender(t *testing.T) {
+	data := testData{
+		Name: "<cities>",
+		Body: []interface{}{
+			[]interface{}{"toronto", 40000000.0},
+			[]interface{}{"new york", 8500000.0},
+		},
+	}
+
+	cases := []struct {
+		format, tmpl, expect, err string
+	}{
+		{"html", "<h1>{{ .Name }}</h1>", "<h1>&lt;cities&gt;</h1>", ""},
+		{"text", "{{ .Name }}", "<cities>", ""},
+		{"text", "{{ formatNumber 1234567.891 2 }}", "1,234,567.89", ""},
+		{"text", "{{ table .Body }}", "| 0 | 1 |\n| --- | --- |\n| toronto | 40000000 |\n| new york | 8500000 |\n", ""},
+		{"markdown", "# {{ formatNumber 1000 }}", "<h1>1,000</h1>\n", ""},
+		{"mustache", "{{ name }} {{{ name }}}", "&lt;cities&gt; <cities>", ""},
+		{"mustache", "{{#body}}{{0}};{{/body}}", "toronto;new york;", ""},
+		{"mustache", "{{^missing}}none{{/missing}}", "none", ""},
+		{"mustache", "{{ formatNumber body.0.1 }}", "40,000,000", ""},
+		{"mustache", "{{#body}}", "", "parsing template: unclosed section: {{#body}}"},
+		{"html", "{{ chart \"pie\" .Body 1 }}", "", "unknown chart type: 'pie'. supported types are bar, line"},
+	}
+
+	for i, c := range cases {
+		got, err := Render(c.format, []byte(c.tmpl), data)
+		if !(err == nil && c.err == "" || err != nil && c.err != "" && strings.Contains(err.Error(), c.err)) {
+			t.Errorf("case %d error mismatch. expected: '%s', got: '%v'", i, c.err, err)
+			continue
+		}
+		if c.err == "" && string(got) != c.expect {
+			t.Errorf("case %d result mismatch. expected: '%s', got: '%s'", i, c.expect, string(got))
+		}
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+	cases := []struct {
+		v        interface{}
+		decimals []int
+		expect   string
+	}{
+		{0, nil, "0"},
+		{999, nil, "999"},
+		{1000, nil, "1,000"},
+		{-1234567, nil, "-1,234,567"},
+		{1234.5, []int{2}, "1,234.50"},
+		{"12345", nil, "12,345"},
+		{"nope", nil, "nope"},
+	}
+
+	for i, c := range cases {
+		if got := FormatNumber(c.v, c.decimals...); got != c.expect {
+			t.Errorf("case %d mismatch. expected: '%s', got: '%s'", i, c.expect, got)
+		}
+	}
+}
+
+func TestChart(t *testing.T) {
+	body := []interface{}{
+		map[string]interface{}{"city": "toronto", "pop": 40000000.0},
+		map[string]interface{}{"city": "new york", "pop": 8500000.0},
+	}
+
+	bar, err := Chart("bar", body, "pop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(bar, "<rect") != 2 {
+		t.Errorf("expected bar chart to have 2 bars. got: %s", bar)
+	}
+
+	line, err := Chart("line", body, "pop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(line, "<polyline") {
+		t.Errorf("expected line chart to have a polyline. got: %s", line)
+	}
+
+	if _, err := Chart("bar", map[string]interface{}{}, 0); err == nil {
+		t.Errorf("expected charting an object body to error")
+	}
+}
+
+func TestAutoChart(t *testing.T) {
+	schema := map[string]interface{}{
+		"type": "array",
+		"items": map[string]interface{}{
+			"type": "array",
+			"items": []interface{}{
+				map[string]interface{}{"title": "city", "type": "string"},
+				map[string]interface{}{"title": "pop", "type": "integer"},
+			},
+		},
+	}
+	body := []interface{}{
+		[]interface{}{"toronto", 40000000.0},
+		[]interface{}{"new york", 8500000.0},
+	}
+
+	cols := SchemaColumns(schema, body)
+	if len(cols) != 2 || cols[0].Title != "city" || cols[1].Type != "integer" {
+		t.Errorf("unexpected schema columns: %v", cols)
+	}
+
+	svg, err := AutoChart(schema, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(svg, `class="chart bar"`) {
+		t.Errorf("expected labelled numeric column to produce a bar chart, got: %s", svg)
+	}
+
+	long := []interface{}{}
+	for i := 0; i < maxBarChartRows+1; i++ {
+		long = append(long, []interface{}{"city", float64(i)})
+	}
+	if svg, _ = AutoChart(schema, long); !strings.Contains(svg, `class="chart histogram"`) {
+		t.Errorf("expected many rows to produce a histogram, got: %s", svg)
+	}
+
+	// no schema, infer from values
+	if svg, _ = AutoChart(nil, []interface{}{[]interface{}{1.0}, []interface{}{2.0}}); !strings.Contains(svg, `class="chart line"`) {
+		t.Errorf("expected unlabelled numeric column to produce a line chart, got: %s", svg)
+	}
+	if svg, _ = AutoChart(nil, []interface{}{[]interface{}{"a"}}); svg != "" {
+		t.Errorf("expected no chart without numeric columns, got: %s", svg)
+	}
+}
+
+func TestPagedTable(t *testing.T) {
+	body := []interface{}{}
+	for i := 0; i < 5; i++ {
+		body = append(body, []interface{}{float64(i)})
+	}
+	got := PagedTable(nil, body, 2)
+	if count := strings.Count(got, `class="page"`); count != 3 {
+		t.Errorf("expected 3 pages, got: %d", count)
+	}
+	if !strings.Contains(got, `href="#page-3"`) {
+		t.Errorf("expected page navigation links")
+	}
+	if !strings.HasSuffix(got, "</div></div>") || strings.Index(got, `id="page-1"`) < strings.Index(got, `id="page-2"`) {
+		t.Errorf("expected first page to be written last")
+	}
+}
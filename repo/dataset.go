@@ -1,6 +1,7 @@
 package repo
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -13,6 +14,19 @@ import (
 	"github.com/qri-io/dataset"
 )
 
+const (
+	// MetaETag is the meta key that records the ETag of a body downloaded
+	// from a url
+	MetaETag = "downloadETag"
+	// MetaLastModified is the meta key that records the Last-Modified time of
+	// a body downloaded from a url
+	MetaLastModified = "downloadLastModified"
+)
+
+// ErrBodyNotModified is returned when re-fetching a body from a url finds it
+// hasn't changed since it was last downloaded
+var ErrBodyNotModified = errors.New("body not modified")
+
 // DatasetPodBodyFile creates a streaming data file from a DatasetPod using the following precedence:
 // * dsp.BodyBytes not being nil (requires dsp.Structure.Format be set to know data format)
 // * dsp.BodyPath being a url
@@ -32,33 +46,7 @@ func DatasetPodBodyFile(dsp *dataset.DatasetPod) (cafs.File, error) {
 
 	// if opening protocol is http/s, we're dealing with a web request
 	if strings.HasPrefix(loweredPath, "http://") || strings.HasPrefix(loweredPath, "https://") {
-		// TODO - attempt to determine file format based on response headers
-		filename := filepath.Base(dsp.BodyPath)
-
-		res, err := http.Get(dsp.BodyPath)
-		if err != nil {
-			return nil, fmt.Errorf("fetching body url: %s", err.Error())
-		}
-		if res.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("invalid status code fetching body url: %d", res.StatusCode)
-		}
-
-		// TODO - should this happen here? probs not.
-		// consider moving to actions.CreateDataset
-		if dsp.Meta == nil {
-			dsp.Meta = &dataset.Meta{}
-		}
-		if dsp.Meta.DownloadPath == "" {
-			dsp.Meta.DownloadPath = dsp.BodyPath
-		}
-		// if we're adding from a dataset url, set a default accrual periodicity of once a week
-		// this'll set us up to re-check urls over time
-		// TODO - make this configurable via a param?
-		if dsp.Meta.AccrualPeriodicity == "" {
-			dsp.Meta.AccrualPeriodicity = "R/P1W"
-		}
-
-		return cafs.NewMemfileReader(filename, res.Body), nil
+		return fetchBody(dsp, "", "")
 	} else if dsp.BodyPath != "" {
 		// convert yaml input to json as a hack to support yaml input for now
 		ext := strings.ToLower(filepath.Ext(dsp.BodyPath))
@@ -87,3 +75,72 @@ func DatasetPodBodyFile(dsp *dataset.DatasetPod) (cafs.File, error) {
 	// TODO - standardize this error:
 	return nil, fmt.Errorf("not found")
 }
+
+// RefreshBodyFile re-fetches a body from the url at dsp.BodyPath. If prev
+// records a download from the same url, the request is conditional on the
+// ETag & Last-Modified time recorded in prev, returning ErrBodyNotModified
+// when the body hasn't changed
+func RefreshBodyFile(dsp *dataset.DatasetPod, prev *dataset.Meta) (cafs.File, error) {
+	var etag, lastModified string
+	if prev != nil && prev.DownloadPath == dsp.BodyPath {
+		etag, _ = prev.Meta()[MetaETag].(string)
+		lastModified, _ = prev.Meta()[MetaLastModified].(string)
+	}
+	return fetchBody(dsp, etag, lastModified)
+}
+
+// fetchBody GETs a body from dsp.BodyPath, recording the url & response
+// validators in dsp.Meta. A non-empty etag or lastModified makes the request
+// conditional
+func fetchBody(dsp *dataset.DatasetPod, etag, lastModified string) (cafs.File, error) {
+	// TODO - attempt to determine file format based on response headers
+	filename := filepath.Base(dsp.BodyPath)
+
+	req, err := http.NewRequest("GET", dsp.BodyPath, nil)
+	if err != nil {
+		return nil, fmt.Errorf("fetching body url: %s", err.Error())
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching body url: %s", err.Error())
+	}
+	if res.StatusCode == http.StatusNotModified && (etag != "" || lastModified != "") {
+		res.Body.Close()
+		return nil, ErrBodyNotModified
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		return nil, fmt.Errorf("invalid status code fetching body url: %d", res.StatusCode)
+	}
+
+	// TODO - should this happen here? probs not.
+	// consider moving to actions.CreateDataset
+	if dsp.Meta == nil {
+		dsp.Meta = &dataset.Meta{}
+	}
+	if dsp.Meta.DownloadPath == "" {
+		dsp.Meta.DownloadPath = dsp.BodyPath
+	}
+	// if we're adding from a dataset url, set a default accrual periodicity of once a week
+	// this'll set us up to re-check urls over time
+	// TODO - make this configurable via a param?
+	if dsp.Meta.AccrualPeriodicity == "" {
+		dsp.Meta.AccrualPeriodicity = "R/P1W"
+	}
+	// record validators so the next refresh can skip an unchanged body
+	if v := res.Header.Get("ETag"); v != "" {
+		dsp.Meta.Meta()[MetaETag] = v
+	}
+	if v := res.Header.Get("Last-Modified"); v != "" {
+		dsp.Meta.Meta()[MetaLastModified] = v
+	}
+
+	return cafs.NewMemfileReader(filename, res.Body), nil
+}
@@ -75,3 +75,56 @@ func TestDatasetPodBodyFile(t *testing.T) {
 		}
 	}
 }
+
+func TestRefreshBodyFile(t *testing.T) {
+	const etag = `"v1"`
+	requests := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", "Wed, 21 Oct 2015 07:28:00 GMT")
+		w.Write([]byte(`{"json":"data"}`))
+	}))
+	defer s.Close()
+
+	url := fmt.Sprintf("%s/foobar.json", s.URL)
+	dsp := &dataset.DatasetPod{BodyPath: url}
+	file, err := DatasetPodBodyFile(dsp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	if dsp.Meta == nil || dsp.Meta.DownloadPath != url {
+		t.Fatalf("expected download path to be recorded")
+	}
+	if got := dsp.Meta.Meta()[MetaETag]; got != etag {
+		t.Errorf("expected etag to be recorded. got: %v", got)
+	}
+	if got := dsp.Meta.Meta()[MetaLastModified]; got != "Wed, 21 Oct 2015 07:28:00 GMT" {
+		t.Errorf("expected last-modified to be recorded. got: %v", got)
+	}
+
+	if _, err := RefreshBodyFile(&dataset.DatasetPod{BodyPath: url}, dsp.Meta); err != ErrBodyNotModified {
+		t.Errorf("expected unchanged body to return ErrBodyNotModified, got: %v", err)
+	}
+
+	// validators for a different url aren't sent
+	if file, err = RefreshBodyFile(&dataset.DatasetPod{BodyPath: url + "?v=2"}, dsp.Meta); err != nil {
+		t.Fatalf("expected body from a new url to be fetched, got: %s", err.Error())
+	}
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"json":"data"}` {
+		t.Errorf("body mismatch. got: %s", string(data))
+	}
+	if requests != 3 {
+		t.Errorf("expected 3 requests, got: %d", requests)
+	}
+}
@@ -0,0 +1,53 @@
+package repo
+
+import (
+	"sync"
+)
+
+// EventPublisher is an opt-in interface for repos that publish events to
+// subscribers as they happen. Repos that implement EventPublisher publish
+// every event they log
+type EventPublisher interface {
+	// Publish sends an event to all subscribers without logging it
+	Publish(e *Event)
+	// Subscribe registers a channel to receive events, returning a function
+	// that unsubscribes the channel
+	Subscribe(ch chan *Event) (unsubscribe func())
+}
+
+// EventBus is an implementation of the EventPublisher interface. Publishing
+// never blocks: subscribers that aren't ready to receive miss events
+type EventBus struct {
+	lk   sync.Mutex
+	subs map[chan *Event]struct{}
+}
+
+// NewEventBus allocates an EventBus
+func NewEventBus() *EventBus {
+	return &EventBus{subs: map[chan *Event]struct{}{}}
+}
+
+// Publish sends an event to all subscribers
+func (b *EventBus) Publish(e *Event) {
+	b.lk.Lock()
+	defer b.lk.Unlock()
+	for ch := range b.subs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// Subscribe registers a channel to receive events
+func (b *EventBus) Subscribe(ch chan *Event) func() {
+	b.lk.Lock()
+	b.subs[ch] = struct{}{}
+	b.lk.Unlock()
+
+	return func() {
+		b.lk.Lock()
+		delete(b.subs, ch)
+		b.lk.Unlock()
+	}
+}
@@ -0,0 +1,49 @@
+package repo
+
+import (
+	"testing"
+)
+
+func TestEventBus(t *testing.T) {
+	r, err := NewMemRepo(nil, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := make(chan *Event, 1)
+	unsubscribe := r.Subscribe(events)
+
+	if err := r.LogEvent(ETDsCreated, DatasetRef{Peername: "peer", Name: "cities"}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case e := <-events:
+		if e.Type != ETDsCreated || e.Ref.Name != "cities" {
+			t.Errorf("published event mismatch. got: %s %s", e.Type, e.Ref.AliasString())
+		}
+	default:
+		t.Fatal("expected logging an event to publish it")
+	}
+
+	// publishing doesn't block on subscribers that aren't receiving
+	r.Publish(&Event{Type: ETPeerConnected})
+	r.Publish(&Event{Type: ETPeerConnected})
+	if len(events) != 1 {
+		t.Errorf("expected full subscriber to miss events, got %d buffered", len(events))
+	}
+	<-events
+
+	unsubscribe()
+	r.Publish(&Event{Type: ETPeerConnected})
+	if len(events) != 0 {
+		t.Errorf("expected unsubscribed channel not to receive events")
+	}
+
+	logged, err := r.Events(10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logged) != 1 {
+		t.Errorf("expected published events not to be logged, got %d logged events", len(logged))
+	}
+}
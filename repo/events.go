@@ -40,6 +40,21 @@ const (
 	ETDsAdded = EventType("ds_added")
 	// ETTransformExecuted represents running a transformation
 	ETTransformExecuted = EventType("tf_executed")
+	// ETTransformCacheHit represents reusing a cached transform result instead of executing a transform
+	ETTransformCacheHit = EventType("tf_cache_hit")
+	// ETTransformCacheMiss represents finding no cached result for a transform, requiring execution
+	ETTransformCacheMiss = EventType("tf_cache_miss")
+	// ETScheduleRan represents a scheduled update that saved a new dataset version
+	ETScheduleRan = EventType("schedule_ran")
+	// ETScheduleUnchanged represents a scheduled update that found no changes to commit
+	ETScheduleUnchanged = EventType("schedule_unchanged")
+	// ETScheduleFailed represents a scheduled update that errored
+	ETScheduleFailed = EventType("schedule_failed")
+	// ETPeerConnected represents a peer announcing it's connected to the network.
+	// peer events are published but not logged
+	ETPeerConnected = EventType("peer_connected")
+	// ETProfileReceived represents receiving the profile of another peer
+	ETProfileReceived = EventType("profile_received")
 )
 
 // MemEventLog is an in-memory implementation of the
@@ -48,15 +63,19 @@ type MemEventLog []*Event
 
 // LogEvent adds a query entry to the store
 func (log *MemEventLog) LogEvent(t EventType, ref DatasetRef) error {
-	e := &Event{
+	log.logEvent(&Event{
 		Time: time.Now(),
 		Type: t,
 		Ref:  ref,
-	}
+	})
+	return nil
+}
+
+// logEvent adds an event to the log as-is
+func (log *MemEventLog) logEvent(e *Event) {
 	logs := append([]*Event{e}, *log...)
 	sort.Slice(logs, func(i, j int) bool { return logs[i].Time.After(logs[j].Time) })
 	*log = logs
-	return nil
 }
 
 // LogEventDetails adds an entry to the log
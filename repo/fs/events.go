@@ -26,21 +26,36 @@ func NewEventLog(base string, file File, store cafs.Filestore) EventLog {
 
 // LogEvent adds a Event to the store
 func (ql EventLog) LogEvent(t repo.EventType, ref repo.DatasetRef) error {
-	log, err := ql.logs()
-	if err != nil {
-		return err
-	}
-
-	e := &repo.Event{
+	return ql.logEvent(&repo.Event{
 		Time: time.Now(),
 		Type: t,
 		Ref:  ref,
+	})
+}
+
+// logEvent adds an event to the store as-is
+func (ql EventLog) logEvent(e *repo.Event) error {
+	log, err := ql.logs()
+	if err != nil {
+		return err
 	}
 	log = append([]*repo.Event{e}, log...)
 	sort.Slice(log, func(i, j int) bool { return log[i].Time.After(log[j].Time) })
 	return ql.saveFile(log, ql.file)
 }
 
+// LogEvent adds an event to the log, publishing it to subscribers. The
+// published event matches the logged one, so subscribers can tell which
+// logged events they've seen
+func (r *Repo) LogEvent(t repo.EventType, ref repo.DatasetRef) error {
+	e := &repo.Event{Time: time.Now(), Type: t, Ref: ref}
+	if err := r.EventLog.logEvent(e); err != nil {
+		return err
+	}
+	r.Publish(e)
+	return nil
+}
+
 // Events fetches a set of Events from the store
 func (ql EventLog) Events(limit, offset int) ([]*repo.Event, error) {
 	logs, err := ql.logs()
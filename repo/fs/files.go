@@ -57,21 +57,37 @@ const (
 	FileSelectedRefs
 	// FileChangeRequests is a file of change requests
 	FileChangeRequests
+	// FileSchedules is a file of dataset update schedules
+	FileSchedules
+	// FileTransformCache maps transform inputs to transform results
+	FileTransformCache
+	// FileTokens is a file of hashed api tokens
+	FileTokens
+	// FileWebhookDeliveries is a log of webhook deliveries
+	FileWebhookDeliveries
+	// FileWebhookCursor records how far through the event log webhooks have
+	// been sent
+	FileWebhookCursor
 )
 
 var paths = map[File]string{
-	FileUnknown:        "",
-	FileLockfile:       "/repo.lock",
-	FileInfo:           "/info.json",
-	FileConfig:         "/config.json",
-	FileDatasets:       "/datasets.json",
-	FileEventLogs:      "/events.json",
-	FileRefstore:       "/ds_refs.json",
-	FilePeers:          "/peers.json",
-	FileAnalytics:      "/analytics.json",
-	FileSearchIndex:    "/index.bleve",
-	FileSelectedRefs:   "/selected_refs.json",
-	FileChangeRequests: "/change_requests.json",
+	FileUnknown:           "",
+	FileLockfile:          "/repo.lock",
+	FileInfo:              "/info.json",
+	FileConfig:            "/config.json",
+	FileDatasets:          "/datasets.json",
+	FileEventLogs:         "/events.json",
+	FileRefstore:          "/ds_refs.json",
+	FilePeers:             "/peers.json",
+	FileAnalytics:         "/analytics.json",
+	FileSearchIndex:       "/index.bleve",
+	FileSelectedRefs:      "/selected_refs.json",
+	FileChangeRequests:    "/change_requests.json",
+	FileSchedules:         "/schedules.json",
+	FileTransformCache:    "/transform_cache.json",
+	FileTokens:            "/tokens.json",
+	FileWebhookDeliveries: "/webhook_deliveries.json",
+	FileWebhookCursor:     "/webhook_cursor.json",
 }
 
 // Filepath gives the relative filepath to a repofile
@@ -28,6 +28,7 @@ type Repo struct {
 
 	Refstore
 	EventLog
+	*repo.EventBus
 
 	profile *profile.Profile
 
@@ -60,6 +61,7 @@ func NewRepo(store cafs.Filestore, pro *profile.Profile, rc *regclient.Client, b
 
 		Refstore: Refstore{basepath: bp, store: store, file: FileRefstore},
 		EventLog: NewEventLog(base, FileEventLogs, store),
+		EventBus: repo.NewEventBus(),
 
 		profiles: NewProfileStore(bp),
 
@@ -141,6 +143,14 @@ func (r *Repo) Search(p repo.SearchParams) ([]repo.DatasetRef, error) {
 	return refs, nil
 }
 
+// SearchIndexSize returns the number of documents in this repo's search index
+func (r *Repo) SearchIndexSize() (uint64, error) {
+	if r.index == nil {
+		return 0, fmt.Errorf("search not supported")
+	}
+	return r.index.DocCount()
+}
+
 // UpdateSearchIndex refreshes this repos search index
 func (r *Repo) UpdateSearchIndex(store cafs.Filestore) error {
 	return search.IndexRepo(r, r.index)
@@ -13,6 +13,8 @@ import (
 )
 
 var _ repo.RefSelector = (*Repo)(nil)
+var _ repo.ScheduleStore = (*Repo)(nil)
+var _ repo.TransformCache = (*Repo)(nil)
 
 func TestRepo(t *testing.T) {
 	path := filepath.Join(os.TempDir(), "qri_repo_test")
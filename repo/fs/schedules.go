@@ -0,0 +1,56 @@
+package fsrepo
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/qri-io/qri/repo"
+)
+
+// PutSchedule adds a dataset update schedule, replacing any existing schedule
+// for the same dataset
+func (r *Repo) PutSchedule(s *repo.Schedule) error {
+	schedules, err := r.schedules()
+	if err != nil {
+		return err
+	}
+	if err := schedules.PutSchedule(s); err != nil {
+		return err
+	}
+	return r.saveFile(schedules, FileSchedules)
+}
+
+// DeleteSchedule removes the schedule for a dataset
+func (r *Repo) DeleteSchedule(ref repo.DatasetRef) error {
+	schedules, err := r.schedules()
+	if err != nil {
+		return err
+	}
+	if err := schedules.DeleteSchedule(ref); err != nil {
+		return err
+	}
+	return r.saveFile(schedules, FileSchedules)
+}
+
+// Schedules lists all dataset update schedules
+func (r *Repo) Schedules() ([]*repo.Schedule, error) {
+	schedules, err := r.schedules()
+	return []*repo.Schedule(*schedules), err
+}
+
+func (r *Repo) schedules() (*repo.MemScheduleStore, error) {
+	schedules := &repo.MemScheduleStore{}
+	data, err := r.readBytes(FileSchedules)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return schedules, nil
+		}
+		log.Debug(err.Error())
+		return schedules, err
+	}
+	if err := json.Unmarshal(data, schedules); err != nil {
+		log.Debug(err.Error())
+		return schedules, err
+	}
+	return schedules, nil
+}
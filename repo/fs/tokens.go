@@ -0,0 +1,55 @@
+package fsrepo
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/qri-io/qri/repo"
+)
+
+// PutToken adds an api token, replacing any existing token with the same ID
+func (r *Repo) PutToken(t *repo.Token) error {
+	tokens, err := r.tokens()
+	if err != nil {
+		return err
+	}
+	if err := tokens.PutToken(t); err != nil {
+		return err
+	}
+	return r.saveFile(tokens, FileTokens)
+}
+
+// DeleteToken removes an api token
+func (r *Repo) DeleteToken(id string) error {
+	tokens, err := r.tokens()
+	if err != nil {
+		return err
+	}
+	if err := tokens.DeleteToken(id); err != nil {
+		return err
+	}
+	return r.saveFile(tokens, FileTokens)
+}
+
+// Tokens lists all api tokens
+func (r *Repo) Tokens() ([]*repo.Token, error) {
+	tokens, err := r.tokens()
+	return []*repo.Token(*tokens), err
+}
+
+func (r *Repo) tokens() (*repo.MemTokenStore, error) {
+	tokens := &repo.MemTokenStore{}
+	data, err := r.readBytes(FileTokens)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return tokens, nil
+		}
+		log.Debug(err.Error())
+		return tokens, err
+	}
+	if err := json.Unmarshal(data, tokens); err != nil {
+		log.Debug(err.Error())
+		return tokens, err
+	}
+	return tokens, nil
+}
@@ -0,0 +1,46 @@
+package fsrepo
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/qri-io/qri/repo"
+)
+
+// PutTransformResult records the dataset path a transform run produced
+func (r *Repo) PutTransformResult(key, path string) error {
+	cache, err := r.transformCache()
+	if err != nil {
+		return err
+	}
+	if err := cache.PutTransformResult(key, path); err != nil {
+		return err
+	}
+	return r.saveFile(cache, FileTransformCache)
+}
+
+// TransformResult gets a cached transform result
+func (r *Repo) TransformResult(key string) (string, error) {
+	cache, err := r.transformCache()
+	if err != nil {
+		return "", err
+	}
+	return cache.TransformResult(key)
+}
+
+func (r *Repo) transformCache() (repo.MemTransformCache, error) {
+	cache := repo.MemTransformCache{}
+	data, err := r.readBytes(FileTransformCache)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cache, nil
+		}
+		log.Debug(err.Error())
+		return cache, err
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		log.Debug(err.Error())
+		return cache, err
+	}
+	return cache, nil
+}
@@ -0,0 +1,70 @@
+package fsrepo
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/qri-io/qri/repo"
+)
+
+// LogDelivery adds a webhook delivery to the log
+func (r *Repo) LogDelivery(d *repo.WebhookDelivery) error {
+	deliveries, err := r.deliveries()
+	if err != nil {
+		return err
+	}
+	if err := deliveries.LogDelivery(d); err != nil {
+		return err
+	}
+	return r.saveFile(deliveries, FileWebhookDeliveries)
+}
+
+// Deliveries lists webhook deliveries, most recent first
+func (r *Repo) Deliveries(limit, offset int) ([]*repo.WebhookDelivery, error) {
+	deliveries, err := r.deliveries()
+	if err != nil {
+		return nil, err
+	}
+	return deliveries.Deliveries(limit, offset)
+}
+
+func (r *Repo) deliveries() (*repo.MemWebhookLog, error) {
+	deliveries := &repo.MemWebhookLog{}
+	data, err := r.readBytes(FileWebhookDeliveries)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return deliveries, nil
+		}
+		log.Debug(err.Error())
+		return deliveries, err
+	}
+	if err := json.Unmarshal(data, deliveries); err != nil {
+		log.Debug(err.Error())
+		return deliveries, err
+	}
+	return deliveries, nil
+}
+
+// WebhookCursor gives the time of the last logged event sent to webhooks
+func (r *Repo) WebhookCursor() (time.Time, error) {
+	t := time.Time{}
+	data, err := r.readBytes(FileWebhookCursor)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return t, nil
+		}
+		log.Debug(err.Error())
+		return t, err
+	}
+	if err := json.Unmarshal(data, &t); err != nil {
+		log.Debug(err.Error())
+		return t, err
+	}
+	return t, nil
+}
+
+// SetWebhookCursor records the time of the last logged event sent to webhooks
+func (r *Repo) SetWebhookCursor(t time.Time) error {
+	return r.saveFile(t, FileWebhookCursor)
+}
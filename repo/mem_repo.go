@@ -1,6 +1,8 @@
 package repo
 
 import (
+	"time"
+
 	"github.com/libp2p/go-libp2p-crypto"
 	"github.com/qri-io/cafs"
 	"github.com/qri-io/dataset/dsgraph"
@@ -12,6 +14,12 @@ import (
 type MemRepo struct {
 	*MemRefstore
 	*MemEventLog
+	*MemScheduleStore
+	*MemTokenStore
+	*MemWebhookLog
+	*MemWebhookCursor
+	*EventBus
+	MemTransformCache
 
 	store        cafs.Filestore
 	graph        map[string]*dsgraph.Node
@@ -26,13 +34,19 @@ type MemRepo struct {
 // NewMemRepo creates a new in-memory repository
 func NewMemRepo(p *profile.Profile, store cafs.Filestore, ps profile.Store, rc *regclient.Client) (*MemRepo, error) {
 	return &MemRepo{
-		store:       store,
-		MemRefstore: &MemRefstore{},
-		MemEventLog: &MemEventLog{},
-		refCache:    &MemRefstore{},
-		profile:     p,
-		profiles:    ps,
-		registry:    rc,
+		store:             store,
+		MemRefstore:       &MemRefstore{},
+		MemEventLog:       &MemEventLog{},
+		MemScheduleStore:  &MemScheduleStore{},
+		MemTokenStore:     &MemTokenStore{},
+		MemWebhookLog:     &MemWebhookLog{},
+		MemWebhookCursor:  &MemWebhookCursor{},
+		EventBus:          NewEventBus(),
+		MemTransformCache: MemTransformCache{},
+		refCache:          &MemRefstore{},
+		profile:           p,
+		profiles:          ps,
+		registry:          rc,
 	}, nil
 }
 
@@ -41,6 +55,14 @@ func (r *MemRepo) Store() cafs.Filestore {
 	return r.store
 }
 
+// LogEvent adds an event to the log, publishing it to subscribers
+func (r *MemRepo) LogEvent(t EventType, ref DatasetRef) error {
+	e := &Event{Time: time.Now(), Type: t, Ref: ref}
+	r.MemEventLog.logEvent(e)
+	r.Publish(e)
+	return nil
+}
+
 // PrivateKey returns this repo's private key
 func (r *MemRepo) PrivateKey() crypto.PrivKey {
 	if r.profile == nil {
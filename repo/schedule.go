@@ -0,0 +1,71 @@
+package repo
+
+import (
+	"fmt"
+	"time"
+)
+
+// ErrSchedulesNotSupported is the expected error for when the ScheduleStore
+// interface is *not* implemented
+var ErrSchedulesNotSupported = fmt.Errorf("repo: scheduled updates not supported")
+
+// Schedule describes a recurring update for a dataset
+type Schedule struct {
+	// Ref is the dataset to update. only Peername & Name are used
+	Ref DatasetRef `json:"ref"`
+	// Spec is a cron-like specification for when to update. see the cron package
+	// for details
+	Spec string `json:"spec"`
+	// BodyPath is an optional url to re-fetch the dataset body from. if empty,
+	// the dataset's meta.downloadPath is used, falling back to the dataset's transform
+	BodyPath string `json:"bodyPath,omitempty"`
+	// Created is when this schedule was added
+	Created time.Time `json:"created"`
+	// LastRun is the last time this schedule was executed
+	LastRun time.Time `json:"lastRun,omitempty"`
+}
+
+// ScheduleStore is an opt-in interface for repos that can persist
+// dataset update schedules. Schedules are unique by dataset alias
+type ScheduleStore interface {
+	// PutSchedule adds a schedule, replacing any existing schedule for the same dataset
+	PutSchedule(s *Schedule) error
+	// DeleteSchedule removes the schedule for a dataset
+	DeleteSchedule(ref DatasetRef) error
+	// Schedules lists all schedules
+	Schedules() ([]*Schedule, error)
+}
+
+// MemScheduleStore is an in-memory implementation of the ScheduleStore interface
+type MemScheduleStore []*Schedule
+
+// PutSchedule adds a schedule to the store
+func (ms *MemScheduleStore) PutSchedule(s *Schedule) error {
+	if s.Ref.Peername == "" || s.Ref.Name == "" {
+		return ErrNameRequired
+	}
+	for i, sch := range *ms {
+		if sch.Ref.AliasString() == s.Ref.AliasString() {
+			(*ms)[i] = s
+			return nil
+		}
+	}
+	*ms = append(*ms, s)
+	return nil
+}
+
+// DeleteSchedule removes a schedule from the store
+func (ms *MemScheduleStore) DeleteSchedule(ref DatasetRef) error {
+	for i, sch := range *ms {
+		if sch.Ref.AliasString() == ref.AliasString() {
+			*ms = append((*ms)[:i], (*ms)[i+1:]...)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+// Schedules lists all schedules in the store
+func (ms MemScheduleStore) Schedules() ([]*Schedule, error) {
+	return ms, nil
+}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/qri-io/qri/repo"
 )
@@ -24,6 +25,11 @@ func RunRepoTests(t *testing.T, rmf RepoMakerFunc) {
 	tests := []repoTestFunc{
 		testProfile,
 		testRefSelector,
+		testScheduleStore,
+		testTokenStore,
+		testWebhookLog,
+		testWebhookCursor,
+		testTransformCache,
 	}
 
 	for _, test := range tests {
@@ -72,3 +78,179 @@ func testRefSelector(t *testing.T, rmf RepoMakerFunc) {
 		}
 	}
 }
+
+func testScheduleStore(t *testing.T, rmf RepoMakerFunc) {
+	r := rmf(t)
+	ss, ok := r.(repo.ScheduleStore)
+	if !ok {
+		return
+	}
+
+	a := &repo.Schedule{Ref: repo.DatasetRef{Peername: "foo", Name: "bar"}, Spec: "@daily"}
+	if err := ss.PutSchedule(a); err != nil {
+		t.Errorf("error putting schedule: %s", err)
+		return
+	}
+	if err := ss.PutSchedule(&repo.Schedule{Spec: "@daily"}); err != repo.ErrNameRequired {
+		t.Errorf("expected putting a schedule without a name to error with: '%s', got: '%v'", repo.ErrNameRequired, err)
+	}
+
+	b := &repo.Schedule{Ref: repo.DatasetRef{Peername: "foo", Name: "bar"}, Spec: "@hourly"}
+	if err := ss.PutSchedule(b); err != nil {
+		t.Errorf("error replacing schedule: %s", err)
+		return
+	}
+
+	got, err := ss.Schedules()
+	if err != nil {
+		t.Errorf("error listing schedules: %s", err)
+		return
+	}
+	if len(got) != 1 {
+		t.Errorf("expected putting a schedule for the same dataset to replace, got %d schedules", len(got))
+		return
+	}
+	if got[0].Spec != "@hourly" {
+		t.Errorf("spec mismatch. expected: '%s', got: '%s'", "@hourly", got[0].Spec)
+	}
+
+	if err := ss.DeleteSchedule(repo.DatasetRef{Peername: "foo", Name: "bar"}); err != nil {
+		t.Errorf("error deleting schedule: %s", err)
+	}
+	if err := ss.DeleteSchedule(repo.DatasetRef{Peername: "foo", Name: "bar"}); err != repo.ErrNotFound {
+		t.Errorf("expected deleting a missing schedule to error with: '%s', got: '%v'", repo.ErrNotFound, err)
+	}
+}
+
+func testTokenStore(t *testing.T, rmf RepoMakerFunc) {
+	r := rmf(t)
+	ts, ok := r.(repo.TokenStore)
+	if !ok {
+		return
+	}
+
+	tok, secret, err := repo.NewToken(repo.TSWrite, []string{"foo/*"})
+	if err != nil {
+		t.Errorf("error creating token: %s", err)
+		return
+	}
+	if err := ts.PutToken(tok); err != nil {
+		t.Errorf("error putting token: %s", err)
+		return
+	}
+
+	got, err := repo.VerifyToken(ts, secret)
+	if err != nil {
+		t.Errorf("error verifying token: %s", err)
+		return
+	}
+	if got.ID != tok.ID || got.Scope != repo.TSWrite {
+		t.Errorf("verified token mismatch. expected: %s (%s), got: %s (%s)", tok.ID, tok.Scope, got.ID, got.Scope)
+	}
+	if _, err := repo.VerifyToken(ts, tok.ID+".nope"); err != repo.ErrInvalidToken {
+		t.Errorf("expected verifying a bad secret to error with: '%s', got: '%v'", repo.ErrInvalidToken, err)
+	}
+
+	if err := ts.DeleteToken(tok.ID); err != nil {
+		t.Errorf("error deleting token: %s", err)
+	}
+	if _, err := repo.VerifyToken(ts, secret); err != repo.ErrInvalidToken {
+		t.Errorf("expected verifying a deleted token to error with: '%s', got: '%v'", repo.ErrInvalidToken, err)
+	}
+	if err := ts.DeleteToken(tok.ID); err != repo.ErrNotFound {
+		t.Errorf("expected deleting a missing token to error with: '%s', got: '%v'", repo.ErrNotFound, err)
+	}
+}
+
+func testWebhookLog(t *testing.T, rmf RepoMakerFunc) {
+	r := rmf(t)
+	wl, ok := r.(repo.WebhookLog)
+	if !ok {
+		return
+	}
+
+	for _, id := range []string{"a", "b", "c"} {
+		d := &repo.WebhookDelivery{ID: id, URL: "http://example.com", Event: repo.ETDsCreated, Attempts: 1, Delivered: true}
+		if err := wl.LogDelivery(d); err != nil {
+			t.Errorf("error logging delivery: %s", err)
+			return
+		}
+	}
+
+	got, err := wl.Deliveries(2, 0)
+	if err != nil {
+		t.Errorf("error listing deliveries: %s", err)
+		return
+	}
+	if len(got) != 2 {
+		t.Errorf("expected 2 deliveries, got: %d", len(got))
+		return
+	}
+	if got[0].ID != "c" || got[1].ID != "b" {
+		t.Errorf("expected most recent deliveries first, got: %s, %s", got[0].ID, got[1].ID)
+	}
+
+	got, err = wl.Deliveries(10, 2)
+	if err != nil {
+		t.Errorf("error listing deliveries: %s", err)
+		return
+	}
+	if len(got) != 1 || got[0].ID != "a" {
+		t.Errorf("expected offset listing to return delivery a, got: %v", got)
+	}
+}
+
+func testWebhookCursor(t *testing.T, rmf RepoMakerFunc) {
+	r := rmf(t)
+	wc, ok := r.(repo.WebhookCursor)
+	if !ok {
+		return
+	}
+
+	got, err := wc.WebhookCursor()
+	if err != nil {
+		t.Errorf("error reading webhook cursor: %s", err)
+		return
+	}
+	if !got.IsZero() {
+		t.Errorf("expected new repo to have a zero webhook cursor, got: %s", got)
+	}
+
+	// the cursor is compared with event times, so it must keep full precision
+	set := time.Date(2018, 10, 1, 12, 0, 0, 123456789, time.UTC)
+	if err := wc.SetWebhookCursor(set); err != nil {
+		t.Errorf("error setting webhook cursor: %s", err)
+		return
+	}
+	if got, err = wc.WebhookCursor(); err != nil {
+		t.Errorf("error reading webhook cursor: %s", err)
+		return
+	}
+	if !got.Equal(set) {
+		t.Errorf("webhook cursor mismatch. expected: %s, got: %s", set, got)
+	}
+}
+
+func testTransformCache(t *testing.T, rmf RepoMakerFunc) {
+	r := rmf(t)
+	tc, ok := r.(repo.TransformCache)
+	if !ok {
+		return
+	}
+
+	if _, err := tc.TransformResult("key"); err != repo.ErrNotFound {
+		t.Errorf("expected missing transform result to error with: '%s', got: '%v'", repo.ErrNotFound, err)
+	}
+	if err := tc.PutTransformResult("key", "/map/QmResult"); err != nil {
+		t.Errorf("error putting transform result: %s", err)
+		return
+	}
+	got, err := tc.TransformResult("key")
+	if err != nil {
+		t.Errorf("error getting transform result: %s", err)
+		return
+	}
+	if got != "/map/QmResult" {
+		t.Errorf("transform result mismatch. expected: '%s', got: '%s'", "/map/QmResult", got)
+	}
+}
@@ -80,7 +80,7 @@ func NewTestRepo(rc *regclient.Client) (mr *repo.MemRepo, err error) {
 
 		datafile := cafs.NewMemfileBytes(tc.BodyFilename, tc.Body)
 
-		if _, err = act.CreateDataset(tc.Name, tc.Input, datafile, nil, true); err != nil {
+		if _, err = act.CreateDataset(tc.Name, tc.Input, datafile, actions.CreateDatasetOptions{Pin: true}); err != nil {
 			return nil, fmt.Errorf("%s error creating dataset: %s", k, err.Error())
 		}
 	}
@@ -119,7 +119,7 @@ func NewTestRepoFromProfileID(id profile.ID, peerNum int, dataIndex int) (repo.R
 	}
 
 	datafile := cafs.NewMemfileBytes(tc.BodyFilename, tc.Body)
-	if _, err = act.CreateDataset(tc.Name, tc.Input, datafile, nil, true); err != nil {
+	if _, err = act.CreateDataset(tc.Name, tc.Input, datafile, actions.CreateDatasetOptions{Pin: true}); err != nil {
 		return nil, fmt.Errorf("error creating dataset: %s", err.Error())
 	}
 
@@ -153,7 +153,7 @@ func NewMemRepoFromDir(path string) (repo.Repo, crypto.PrivKey, error) {
 	}
 
 	for _, c := range tc {
-		if _, err := act.CreateDataset(c.Name, c.Input, c.BodyFile(), nil, true); err != nil {
+		if _, err := act.CreateDataset(c.Name, c.Input, c.BodyFile(), actions.CreateDatasetOptions{Pin: true}); err != nil {
 			return mr, pk, err
 		}
 	}
@@ -0,0 +1,244 @@
+package repo
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// ErrTokensNotSupported is the expected error for when the TokenStore
+// interface is *not* implemented
+var ErrTokensNotSupported = fmt.Errorf("repo: api tokens not supported")
+
+// ErrInvalidToken is returned when a token secret doesn't match any stored token
+var ErrInvalidToken = fmt.Errorf("invalid api token")
+
+// TokenScope is a level of access granted by an api token. each scope
+// includes all access granted by the scopes below it
+type TokenScope string
+
+const (
+	// TSRead allows reading datasets, profiles & peers
+	TSRead = TokenScope("read")
+	// TSWrite allows creating & updating datasets in addition to reading
+	TSWrite = TokenScope("write")
+	// TSAdmin allows all access, including deleting datasets, editing the
+	// profile & connecting to peers
+	TSAdmin = TokenScope("admin")
+)
+
+var scopeLevels = map[TokenScope]int{
+	TSRead:  1,
+	TSWrite: 2,
+	TSAdmin: 3,
+}
+
+// ParseTokenScope reads a scope from a string
+func ParseTokenScope(s string) (TokenScope, error) {
+	ts := TokenScope(strings.ToLower(s))
+	if _, ok := scopeLevels[ts]; !ok {
+		return "", fmt.Errorf("invalid token scope '%s', must be one of read, write, admin", s)
+	}
+	return ts, nil
+}
+
+// Allows checks if a scope grants at least the access of required
+func (ts TokenScope) Allows(required TokenScope) bool {
+	return scopeLevels[ts] > 0 && scopeLevels[ts] >= scopeLevels[required]
+}
+
+// Token is an api access token. Only a hash of the token secret is stored,
+// the secret itself is only available when the token is created
+type Token struct {
+	// ID identifies the token, and is the first part of the token secret
+	ID string `json:"id"`
+	// Hash is the hex-encoded sha256 hash of the token secret
+	Hash string `json:"hash,omitempty"`
+	// Scope is the level of access this token grants
+	Scope TokenScope `json:"scope"`
+	// Datasets optionally limits the token to datasets with aliases that match
+	// any of a list of patterns, eg: "peer/*" or "peer/cities". an empty list
+	// allows all datasets
+	Datasets []string `json:"datasets,omitempty"`
+	// ProfileID grants this token to a peer, who authenticates by signing
+	// requests with their profile key instead of presenting a secret
+	ProfileID string `json:"profileID,omitempty"`
+	// Created is when this token was created
+	Created time.Time `json:"created"`
+}
+
+// NewToken creates a token, returning the token & its secret
+func NewToken(scope TokenScope, datasets []string) (*Token, string, error) {
+	t, err := newToken(scope, datasets)
+	if err != nil {
+		return nil, "", err
+	}
+	key, err := randomHex(24)
+	if err != nil {
+		return nil, "", err
+	}
+	secret := t.ID + "." + key
+	t.Hash = hashTokenSecret(secret)
+	return t, secret, nil
+}
+
+// NewPeerGrant creates a token for the peer with profileID. Grants have no
+// secret, peers use them by signing requests with their profile key
+func NewPeerGrant(profileID string, scope TokenScope, datasets []string) (*Token, error) {
+	if profileID == "" {
+		return nil, fmt.Errorf("profile ID is required")
+	}
+	t, err := newToken(scope, datasets)
+	if err != nil {
+		return nil, err
+	}
+	t.ProfileID = profileID
+	return t, nil
+}
+
+func newToken(scope TokenScope, datasets []string) (*Token, error) {
+	if _, ok := scopeLevels[scope]; !ok {
+		return nil, fmt.Errorf("invalid token scope '%s', must be one of read, write, admin", scope)
+	}
+	for _, pattern := range datasets {
+		if err := validDatasetPattern(pattern); err != nil {
+			return nil, err
+		}
+	}
+
+	id, err := randomHex(8)
+	if err != nil {
+		return nil, err
+	}
+	t := &Token{
+		ID:       id,
+		Scope:    scope,
+		Datasets: datasets,
+		Created:  time.Now(),
+	}
+	return t, nil
+}
+
+// MatchesDataset checks if a token grants access to a dataset
+func (t *Token) MatchesDataset(peername, name string) bool {
+	if len(t.Datasets) == 0 {
+		return true
+	}
+	alias := peername + "/" + name
+	for _, pattern := range t.Datasets {
+		if ok, _ := path.Match(pattern, alias); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// TokenStore is an opt-in interface for repos that can persist api tokens
+type TokenStore interface {
+	// PutToken adds a token, replacing any existing token with the same ID
+	PutToken(t *Token) error
+	// DeleteToken removes a token
+	DeleteToken(id string) error
+	// Tokens lists all tokens
+	Tokens() ([]*Token, error)
+}
+
+// VerifyToken finds the token in a store that matches a secret
+func VerifyToken(ts TokenStore, secret string) (*Token, error) {
+	dot := strings.Index(secret, ".")
+	if dot < 1 {
+		return nil, ErrInvalidToken
+	}
+	id := secret[:dot]
+
+	tokens, err := ts.Tokens()
+	if err != nil {
+		return nil, err
+	}
+	hash := hashTokenSecret(secret)
+	for _, t := range tokens {
+		if t.ID == id && t.Hash != "" {
+			if subtle.ConstantTimeCompare([]byte(t.Hash), []byte(hash)) == 1 {
+				return t, nil
+			}
+			break
+		}
+	}
+	return nil, ErrInvalidToken
+}
+
+// PeerGrant finds the token granted to a peer
+func PeerGrant(ts TokenStore, profileID string) (*Token, error) {
+	tokens, err := ts.Tokens()
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tokens {
+		if profileID != "" && t.ProfileID == profileID {
+			return t, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// MemTokenStore is an in-memory implementation of the TokenStore interface
+type MemTokenStore []*Token
+
+// PutToken adds a token to the store
+func (ms *MemTokenStore) PutToken(t *Token) error {
+	if t.ID == "" {
+		return fmt.Errorf("token ID is required")
+	}
+	for i, tok := range *ms {
+		if tok.ID == t.ID {
+			(*ms)[i] = t
+			return nil
+		}
+	}
+	*ms = append(*ms, t)
+	return nil
+}
+
+// DeleteToken removes a token from the store
+func (ms *MemTokenStore) DeleteToken(id string) error {
+	for i, tok := range *ms {
+		if tok.ID == id {
+			*ms = append((*ms)[:i], (*ms)[i+1:]...)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+// Tokens lists all tokens in the store
+func (ms MemTokenStore) Tokens() ([]*Token, error) {
+	return ms, nil
+}
+
+func validDatasetPattern(pattern string) error {
+	if strings.Count(pattern, "/") != 1 {
+		return fmt.Errorf("invalid dataset pattern '%s', must be in the form peername/dataset_name", pattern)
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid dataset pattern '%s': %s", pattern, err.Error())
+	}
+	return nil
+}
+
+func hashTokenSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
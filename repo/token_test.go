@@ -0,0 +1,93 @@
+package repo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTokenScope(t *testing.T) {
+	cases := []struct {
+		scope, required TokenScope
+		expect          bool
+	}{
+		{TSRead, TSRead, true},
+		{TSRead, TSWrite, false},
+		{TSWrite, TSRead, true},
+		{TSWrite, TSAdmin, false},
+		{TSAdmin, TSWrite, true},
+		{TokenScope(""), TSRead, false},
+	}
+	for i, c := range cases {
+		if got := c.scope.Allows(c.required); got != c.expect {
+			t.Errorf("case %d: expected '%s' allows '%s' to be %t", i, c.scope, c.required, c.expect)
+		}
+	}
+
+	if _, err := ParseTokenScope("root"); err == nil || err.Error() != "invalid token scope 'root', must be one of read, write, admin" {
+		t.Errorf("expected parsing an invalid scope to error, got: %v", err)
+	}
+	if ts, err := ParseTokenScope("Admin"); err != nil || ts != TSAdmin {
+		t.Errorf("expected 'Admin' to parse as admin scope, got: %s, %v", ts, err)
+	}
+}
+
+func TestNewToken(t *testing.T) {
+	if _, _, err := NewToken(TSRead, []string{"cities"}); err == nil {
+		t.Errorf("expected a dataset pattern without a peername to error")
+	}
+
+	tok, secret, err := NewToken(TSRead, []string{"peer/*", "other/cities"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(secret, tok.ID+".") {
+		t.Errorf("expected secret to start with the token id")
+	}
+	if strings.Contains(tok.Hash, secret) {
+		t.Errorf("token must not store its secret")
+	}
+
+	cases := []struct {
+		peername, name string
+		expect         bool
+	}{
+		{"peer", "movies", true},
+		{"other", "cities", true},
+		{"other", "movies", false},
+	}
+	for i, c := range cases {
+		if got := tok.MatchesDataset(c.peername, c.name); got != c.expect {
+			t.Errorf("case %d: expected %s/%s match to be %t", i, c.peername, c.name, c.expect)
+		}
+	}
+}
+
+func TestPeerGrant(t *testing.T) {
+	if _, err := NewPeerGrant("", TSRead, nil); err == nil {
+		t.Errorf("expected a grant without a profile ID to error")
+	}
+
+	ts := &MemTokenStore{}
+	grant, err := NewPeerGrant("QmZePf5LeXow3RW5U1AgEiNbW46YnRGhZ7HPvm1UmPFPwt", TSWrite, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ts.PutToken(grant); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := PeerGrant(ts, "QmZePf5LeXow3RW5U1AgEiNbW46YnRGhZ7HPvm1UmPFPwt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != grant.ID {
+		t.Errorf("grant mismatch. expected: %s, got: %s", grant.ID, got.ID)
+	}
+	if _, err := PeerGrant(ts, "QmNope"); err != ErrNotFound {
+		t.Errorf("expected missing grant to error with: '%s', got: '%v'", ErrNotFound, err)
+	}
+	// grants have no secret, so can't be used as bearer tokens
+	if _, err := VerifyToken(ts, grant.ID+"."); err != ErrInvalidToken {
+		t.Errorf("expected a grant to fail verification as a token, got: %v", err)
+	}
+}
@@ -0,0 +1,31 @@
+package repo
+
+// TransformCache is an opt-in interface for repos that can remember the
+// results of executing transforms. Keys are hashes of everything that can
+// change a transform's output, values are the path to the dataset a transform
+// run produced
+type TransformCache interface {
+	// PutTransformResult records the dataset path a transform run produced
+	PutTransformResult(key, path string) error
+	// TransformResult gets a cached dataset path for a key, returning
+	// ErrNotFound if no result is cached
+	TransformResult(key string) (path string, err error)
+}
+
+// MemTransformCache is an in-memory implementation of the TransformCache interface
+type MemTransformCache map[string]string
+
+// PutTransformResult adds a transform result to the cache
+func (c MemTransformCache) PutTransformResult(key, path string) error {
+	c[key] = path
+	return nil
+}
+
+// TransformResult gets a transform result from the cache
+func (c MemTransformCache) TransformResult(key string) (string, error) {
+	path, ok := c[key]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return path, nil
+}
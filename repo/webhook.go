@@ -0,0 +1,95 @@
+package repo
+
+import (
+	"fmt"
+	"time"
+)
+
+// ErrWebhooksNotSupported is the expected error for when the WebhookLog
+// interface is *not* implemented
+var ErrWebhooksNotSupported = fmt.Errorf("repo: webhook delivery log not supported")
+
+// WebhookDelivery records the outcome of sending an event to a webhook
+type WebhookDelivery struct {
+	// ID identifies the delivery, and is sent with each attempt so receivers
+	// can ignore duplicates
+	ID string `json:"id"`
+	// URL is the webhook address the event was sent to
+	URL string `json:"url"`
+	// Event is the type of event that was sent
+	Event EventType `json:"event"`
+	// Ref is the dataset the event concerns, if any
+	Ref DatasetRef `json:"ref,omitempty"`
+	// Attempts is the number of requests made
+	Attempts int `json:"attempts"`
+	// Status is the http status code of the last response, 0 if no response
+	// was received
+	Status int `json:"status,omitempty"`
+	// Error describes why the last attempt failed
+	Error string `json:"error,omitempty"`
+	// Delivered is true if the webhook accepted the event
+	Delivered bool `json:"delivered"`
+	// Time is when the event happened
+	Time time.Time `json:"time"`
+	// Completed is when the last attempt finished
+	Completed time.Time `json:"completed"`
+}
+
+// WebhookLog is an opt-in interface for repos that can persist a log of
+// webhook deliveries
+type WebhookLog interface {
+	// LogDelivery adds a delivery to the log
+	LogDelivery(d *WebhookDelivery) error
+	// Deliveries lists deliveries, most recent first
+	Deliveries(limit, offset int) ([]*WebhookDelivery, error)
+}
+
+// MemWebhookLog is an in-memory implementation of the WebhookLog interface
+type MemWebhookLog []*WebhookDelivery
+
+// LogDelivery adds a delivery to the log
+func (ml *MemWebhookLog) LogDelivery(d *WebhookDelivery) error {
+	*ml = append([]*WebhookDelivery{d}, *ml...)
+	return nil
+}
+
+// Deliveries lists deliveries, most recent first
+func (ml MemWebhookLog) Deliveries(limit, offset int) ([]*WebhookDelivery, error) {
+	if offset > len(ml) {
+		offset = len(ml)
+	}
+	stop := len(ml)
+	if limit > 0 && offset+limit < stop {
+		stop = offset + limit
+	}
+	return ml[offset:stop], nil
+}
+
+// WebhookCursor is an opt-in interface for repos that can persist how far
+// through the event log webhooks have been sent, so events logged while
+// webhooks aren't running are sent once they start
+type WebhookCursor interface {
+	// WebhookCursor gives the time of the last logged event sent to webhooks,
+	// the zero time if webhooks have never run
+	WebhookCursor() (time.Time, error)
+	// SetWebhookCursor records the time of the last logged event sent to
+	// webhooks
+	SetWebhookCursor(t time.Time) error
+}
+
+// MemWebhookCursor is an in-memory implementation of the WebhookCursor
+// interface
+type MemWebhookCursor struct {
+	t time.Time
+}
+
+// WebhookCursor gives the time of the last event sent to webhooks
+func (c *MemWebhookCursor) WebhookCursor() (time.Time, error) {
+	return c.t, nil
+}
+
+// SetWebhookCursor records the time of the last event sent to webhooks
+func (c *MemWebhookCursor) SetWebhookCursor(t time.Time) error {
+	c.t = t
+	return nil
+}
@@ -0,0 +1,45 @@
+package validation
+
+import "fmt"
+
+// Policy sets how saving a dataset treats a body with validation errors
+type Policy string
+
+const (
+	// PolicyStrict refuses to save bodies with validation errors
+	PolicyStrict = Policy("strict")
+	// PolicyWarn saves bodies with validation errors, recording the number of
+	// errors in Structure.ErrCount
+	PolicyWarn = Policy("warn")
+	// PolicyOff saves bodies without warning about validation errors. It only
+	// silences output: bodies are still validated as they're stored, and the
+	// number of errors is still recorded in Structure.ErrCount
+	PolicyOff = Policy("off")
+)
+
+// DefaultPolicy is the policy used when no policy is set
+const DefaultPolicy = PolicyWarn
+
+// ParsePolicy reads a policy from a string. the empty string parses to
+// DefaultPolicy
+func ParsePolicy(s string) (Policy, error) {
+	switch Policy(s) {
+	case "":
+		return DefaultPolicy, nil
+	case PolicyStrict, PolicyWarn, PolicyOff:
+		return Policy(s), nil
+	}
+	return "", fmt.Errorf("invalid validation policy: '%s'. policy must be one of: strict, warn, off", s)
+}
+
+// PolicyError is returned when a policy rejects a body, carrying the report
+// of validation errors that caused the rejection
+type PolicyError struct {
+	Policy Policy
+	Report *Report
+}
+
+// Error implements the error interface
+func (e PolicyError) Error() string {
+	return fmt.Sprintf("dataset body has %d validation errors, which aren't allowed by the '%s' validation policy", e.Report.ErrCount, e.Policy)
+}
@@ -0,0 +1,302 @@
+package validation
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/qri-io/jsonschema"
+)
+
+// ChangeKind classifies a change between two versions of a schema
+type ChangeKind string
+
+const (
+	// ChangeColumnAdded is a new column. Adding a required column is breaking
+	ChangeColumnAdded = ChangeKind("column_added")
+	// ChangeColumnRemoved is a column that no longer exists
+	ChangeColumnRemoved = ChangeKind("column_removed")
+	// ChangeColumnMoved is a column in an array row that changed position
+	ChangeColumnMoved = ChangeKind("column_moved")
+	// ChangeTypeWidened is a column that accepts all values it did before,
+	// and more. eg: integer -> number
+	ChangeTypeWidened = ChangeKind("type_widened")
+	// ChangeTypeNarrowed is a column that accepts a subset of the values it
+	// did before. eg: number -> integer
+	ChangeTypeNarrowed = ChangeKind("type_narrowed")
+	// ChangeTypeChanged is a column with an incompatible type. eg: string -> number
+	ChangeTypeChanged = ChangeKind("type_changed")
+	// ChangeRequiredAdded is an existing column that's now required
+	ChangeRequiredAdded = ChangeKind("required_added")
+	// ChangeRequiredRemoved is a column that's no longer required
+	ChangeRequiredRemoved = ChangeKind("required_removed")
+)
+
+// SchemaChange is a single difference between two schemas
+type SchemaChange struct {
+	Kind ChangeKind `json:"kind"`
+	// Column is the title of the changed column, empty for changes to the
+	// body as a whole
+	Column string `json:"column,omitempty"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+	// Breaking is true if data or consumers that depend on the previous schema
+	// may not work with the new one
+	Breaking bool `json:"breaking"`
+}
+
+// String implements the stringer interface
+func (c SchemaChange) String() string {
+	subject := "body"
+	if c.Column != "" {
+		subject = fmt.Sprintf("column '%s'", c.Column)
+	}
+
+	switch c.Kind {
+	case ChangeColumnAdded:
+		if c.Breaking {
+			return subject + " added as a required column"
+		}
+		return subject + " added"
+	case ChangeColumnRemoved:
+		return subject + " removed"
+	case ChangeColumnMoved:
+		return fmt.Sprintf("%s moved from position %s to %s", subject, c.Before, c.After)
+	case ChangeTypeWidened:
+		return fmt.Sprintf("%s type widened from %s to %s", subject, c.Before, c.After)
+	case ChangeTypeNarrowed:
+		return fmt.Sprintf("%s type narrowed from %s to %s", subject, c.Before, c.After)
+	case ChangeTypeChanged:
+		return fmt.Sprintf("%s type changed from %s to %s", subject, c.Before, c.After)
+	case ChangeRequiredAdded:
+		return subject + " is now required"
+	case ChangeRequiredRemoved:
+		return subject + " is no longer required"
+	}
+	return fmt.Sprintf("%s: %s", subject, c.Kind)
+}
+
+// BreakingChanges filters a list of changes to only those that are breaking
+func BreakingChanges(changes []SchemaChange) (breaking []SchemaChange) {
+	for _, c := range changes {
+		if c.Breaking {
+			breaking = append(breaking, c)
+		}
+	}
+	return
+}
+
+// SchemaChangeError is returned when a save is refused for making breaking
+// schema changes
+type SchemaChangeError struct {
+	Changes []SchemaChange
+}
+
+// Error implements the error interface
+func (e SchemaChangeError) Error() string {
+	strs := make([]string, len(e.Changes))
+	for i, c := range e.Changes {
+		strs[i] = c.String()
+	}
+	return fmt.Sprintf("schema changes break compatibility with the previous version: %s", strings.Join(strs, ", "))
+}
+
+// CompareSchemas classifies the changes from a previous schema to the next,
+// comparing the top level body type & the columns of body rows. Columns are
+// matched by title, falling back to position for untitled array columns.
+// Changes that only relax a schema (added optional columns, widened types,
+// dropped requirements) are compatible, all others are breaking
+func CompareSchemas(prev, next *jsonschema.RootSchema) ([]SchemaChange, error) {
+	if prev == nil || next == nil {
+		return nil, nil
+	}
+
+	ps, err := schemaMap(prev)
+	if err != nil {
+		return nil, err
+	}
+	ns, err := schemaMap(next)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []SchemaChange{}
+	if c, ok := compareTypes("", ps, ns); ok {
+		return append(changes, c), nil
+	}
+
+	pcols, ncols := schemaColumns(ps), schemaColumns(ns)
+	for _, title := range columnTitles(pcols) {
+		pc := pcols[title]
+		nc, ok := ncols[title]
+		if !ok {
+			changes = append(changes, SchemaChange{Kind: ChangeColumnRemoved, Column: title, Breaking: true})
+			continue
+		}
+
+		if pc.index >= 0 && nc.index >= 0 && pc.index != nc.index {
+			changes = append(changes, SchemaChange{Kind: ChangeColumnMoved, Column: title, Before: strconv.Itoa(pc.index), After: strconv.Itoa(nc.index), Breaking: true})
+		}
+		if c, ok := compareTypes(title, pc.schema, nc.schema); ok {
+			changes = append(changes, c)
+		}
+		if !pc.required && nc.required {
+			changes = append(changes, SchemaChange{Kind: ChangeRequiredAdded, Column: title, Breaking: true})
+		} else if pc.required && !nc.required {
+			changes = append(changes, SchemaChange{Kind: ChangeRequiredRemoved, Column: title})
+		}
+	}
+
+	for _, title := range columnTitles(ncols) {
+		if _, ok := pcols[title]; !ok {
+			nc := ncols[title]
+			changes = append(changes, SchemaChange{Kind: ChangeColumnAdded, Column: title, After: typeString(schemaTypes(nc.schema)), Breaking: nc.required})
+		}
+	}
+
+	return changes, nil
+}
+
+// schemaCol is a column of body rows described by a schema
+type schemaCol struct {
+	// index is the position of array row columns, -1 for object row columns
+	index    int
+	required bool
+	schema   map[string]interface{}
+}
+
+// schemaColumns reads the columns of body rows from a schema, keyed by title.
+// array row columns are required if they fall within the row's "minItems",
+// object row columns if they're listed in the row's "required"
+func schemaColumns(sch map[string]interface{}) map[string]schemaCol {
+	cols := map[string]schemaCol{}
+
+	row, _ := sch["items"].(map[string]interface{})
+	if row == nil {
+		row, _ = sch["additionalProperties"].(map[string]interface{})
+	}
+	if row == nil {
+		return cols
+	}
+
+	if items, ok := row["items"].([]interface{}); ok {
+		minItems, _ := row["minItems"].(float64)
+		for i, it := range items {
+			col, _ := it.(map[string]interface{})
+			title, _ := col["title"].(string)
+			if title == "" {
+				title = strconv.Itoa(i)
+			}
+			cols[title] = schemaCol{index: i, required: float64(i) < minItems, schema: col}
+		}
+	}
+
+	if props, ok := row["properties"].(map[string]interface{}); ok {
+		required := map[string]bool{}
+		if req, ok := row["required"].([]interface{}); ok {
+			for _, key := range req {
+				if s, ok := key.(string); ok {
+					required[s] = true
+				}
+			}
+		}
+		for key, p := range props {
+			col, _ := p.(map[string]interface{})
+			cols[key] = schemaCol{index: -1, required: required[key], schema: col}
+		}
+	}
+
+	return cols
+}
+
+// columnTitles gives column titles in a stable order, array columns by
+// position followed by object columns alphabetically
+func columnTitles(cols map[string]schemaCol) []string {
+	titles := make([]string, 0, len(cols))
+	for title := range cols {
+		titles = append(titles, title)
+	}
+	sort.Slice(titles, func(i, j int) bool {
+		a, b := cols[titles[i]], cols[titles[j]]
+		if a.index != b.index {
+			if a.index < 0 || b.index < 0 {
+				return a.index > b.index
+			}
+			return a.index < b.index
+		}
+		return titles[i] < titles[j]
+	})
+	return titles
+}
+
+// compareTypes classifies the difference between the "type" keywords of two
+// schemas, returning false if they accept the same types
+func compareTypes(column string, prev, next map[string]interface{}) (SchemaChange, bool) {
+	pt, nt := schemaTypes(prev), schemaTypes(next)
+	widens, narrows := acceptsAll(nt, pt), acceptsAll(pt, nt)
+	if widens && narrows {
+		return SchemaChange{}, false
+	}
+
+	c := SchemaChange{Column: column, Before: typeString(pt), After: typeString(nt)}
+	switch {
+	case widens:
+		c.Kind = ChangeTypeWidened
+	case narrows:
+		c.Kind, c.Breaking = ChangeTypeNarrowed, true
+	default:
+		c.Kind, c.Breaking = ChangeTypeChanged, true
+	}
+	return c, true
+}
+
+// schemaTypes reads the "type" keyword of a schema as a list. an empty list
+// means any type is accepted
+func schemaTypes(sch map[string]interface{}) []string {
+	switch t := sch["type"].(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		types := []string{}
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+// acceptsAll checks if a set of types accepts every type in another set
+func acceptsAll(types, other []string) bool {
+	if len(types) == 0 {
+		return true
+	}
+	if len(other) == 0 {
+		return false
+	}
+	for _, o := range other {
+		if !accepts(types, o) {
+			return false
+		}
+	}
+	return true
+}
+
+func accepts(types []string, t string) bool {
+	for _, a := range types {
+		if a == t || a == "number" && t == "integer" {
+			return true
+		}
+	}
+	return false
+}
+
+func typeString(types []string) string {
+	if len(types) == 0 {
+		return "any"
+	}
+	return strings.Join(types, "|")
+}
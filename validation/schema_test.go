@@ -0,0 +1,77 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/qri-io/jsonschema"
+)
+
+func TestCompareSchemas(t *testing.T) {
+	cases := []struct {
+		description string
+		prev, next  string
+		expect      []SchemaChange
+	}{
+		{"no changes", `{"type":"array","items":{"type":"array","items":[{"title":"city","type":"string"}]}}`,
+			`{"type":"array","items":{"type":"array","items":[{"title":"city","type":"string"}]}}`,
+			[]SchemaChange{}},
+		{"body type changed", `{"type":"array"}`, `{"type":"object"}`,
+			[]SchemaChange{{Kind: ChangeTypeChanged, Before: "array", After: "object", Breaking: true}}},
+		{"added optional column", `{"type":"array","items":{"type":"array","items":[{"title":"city","type":"string"}]}}`,
+			`{"type":"array","items":{"type":"array","items":[{"title":"city","type":"string"},{"title":"pop","type":"integer"}]}}`,
+			[]SchemaChange{{Kind: ChangeColumnAdded, Column: "pop", After: "integer"}}},
+		{"added required column", `{"type":"array","items":{"type":"array","items":[{"title":"city","type":"string"}]}}`,
+			`{"type":"array","items":{"type":"array","minItems":2,"items":[{"title":"city","type":"string"},{"title":"pop","type":"integer"}]}}`,
+			[]SchemaChange{
+				{Kind: ChangeRequiredAdded, Column: "city", Breaking: true},
+				{Kind: ChangeColumnAdded, Column: "pop", After: "integer", Breaking: true},
+			}},
+		{"removed & moved columns", `{"type":"array","items":{"type":"array","items":[{"title":"city","type":"string"},{"title":"pop","type":"integer"}]}}`,
+			`{"type":"array","items":{"type":"array","items":[{"title":"pop","type":"integer"}]}}`,
+			[]SchemaChange{
+				{Kind: ChangeColumnRemoved, Column: "city", Breaking: true},
+				{Kind: ChangeColumnMoved, Column: "pop", Before: "1", After: "0", Breaking: true},
+			}},
+		{"widened & narrowed", `{"type":"array","items":{"type":"object","properties":{"a":{"type":"integer"},"b":{"type":["number","null"]},"c":{"type":"string"}}}}`,
+			`{"type":"array","items":{"type":"object","properties":{"a":{"type":"number"},"b":{"type":"integer"},"c":{"type":"boolean"}}}}`,
+			[]SchemaChange{
+				{Kind: ChangeTypeWidened, Column: "a", Before: "integer", After: "number"},
+				{Kind: ChangeTypeNarrowed, Column: "b", Before: "number|null", After: "integer", Breaking: true},
+				{Kind: ChangeTypeChanged, Column: "c", Before: "string", After: "boolean", Breaking: true},
+			}},
+		{"required changes", `{"type":"array","items":{"type":"object","required":["a"],"properties":{"a":{},"b":{}}}}`,
+			`{"type":"array","items":{"type":"object","required":["b"],"properties":{"a":{},"b":{}}}}`,
+			[]SchemaChange{
+				{Kind: ChangeRequiredRemoved, Column: "a"},
+				{Kind: ChangeRequiredAdded, Column: "b", Breaking: true},
+			}},
+	}
+
+	for _, c := range cases {
+		got, err := CompareSchemas(jsonschema.Must(c.prev), jsonschema.Must(c.next))
+		if err != nil {
+			t.Errorf("case '%s' unexpected error: %s", c.description, err)
+			continue
+		}
+		if len(got) != len(c.expect) {
+			t.Errorf("case '%s' expected %d changes, got %d: %v", c.description, len(c.expect), len(got), got)
+			continue
+		}
+		for i, e := range c.expect {
+			if got[i] != e {
+				t.Errorf("case '%s' change %d mismatch. expected: %#v, got: %#v", c.description, i, e, got[i])
+			}
+		}
+	}
+}
+
+func TestSchemaChangeError(t *testing.T) {
+	err := SchemaChangeError{Changes: []SchemaChange{
+		{Kind: ChangeColumnRemoved, Column: "city", Breaking: true},
+		{Kind: ChangeTypeNarrowed, Column: "pop", Before: "number", After: "integer", Breaking: true},
+	}}
+	expect := "schema changes break compatibility with the previous version: column 'city' removed, column 'pop' type narrowed from number to integer"
+	if err.Error() != expect {
+		t.Errorf("error mismatch. expected: '%s', got: '%s'", expect, err.Error())
+	}
+}
@@ -0,0 +1,378 @@
+// Package validation checks dataset bodies against their schemas one entry
+// at a time, so bodies of any size can be validated in constant memory.
+// Errors are located by row & column, and attributed to the schema rule
+// (jsonschema keyword) they break
+package validation
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/jsonschema"
+)
+
+// DefaultMaxErrors is the default cap on the number of errors a report
+// will record
+const DefaultMaxErrors = 1000
+
+// Error is a single validation error
+type Error struct {
+	// Row is the index of the entry with the error
+	Row int `json:"row"`
+	// Key is the key of the entry for object bodies
+	Key string `json:"key,omitempty"`
+	// Column is the title of the column with the error, empty for errors
+	// that apply to the whole row
+	Column string `json:"column,omitempty"`
+	// Value is the offending value
+	Value interface{} `json:"value"`
+	// Rule is the schema keyword that was broken, eg: "type", "maximum"
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Error implements the error interface
+func (e Error) Error() string {
+	loc := fmt.Sprintf("row %d", e.Row)
+	if e.Key != "" {
+		loc = fmt.Sprintf("key %s", e.Key)
+	}
+	if e.Column != "" {
+		loc += fmt.Sprintf(", column %s", e.Column)
+	}
+	val, err := json.Marshal(e.Value)
+	if err != nil {
+		val = []byte(fmt.Sprint(e.Value))
+	}
+	return fmt.Sprintf("%s: %s (rule: %s, value: %s)", loc, e.Message, e.Rule, val)
+}
+
+// Report is the result of validating a body
+type Report struct {
+	// Entries is the number of entries checked
+	Entries int `json:"entries"`
+	// ErrCount is the total number of errors found, which can be larger than
+	// len(Errors) when errors are capped
+	ErrCount int `json:"errCount"`
+	// Errors lists errors in the order they were found
+	Errors []Error `json:"errors"`
+	// Truncated is true when more errors were found than recorded
+	Truncated bool `json:"truncated"`
+	// Summary counts errors by rule
+	Summary map[string]int `json:"summary"`
+}
+
+// Rules lists the rules in the summary, ordered by error count
+func (r *Report) Rules() []string {
+	rules := make([]string, 0, len(r.Summary))
+	for rule := range r.Summary {
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if r.Summary[rules[i]] == r.Summary[rules[j]] {
+			return rules[i] < rules[j]
+		}
+		return r.Summary[rules[i]] > r.Summary[rules[j]]
+	})
+	return rules
+}
+
+// WriteCSV writes report errors as CSV, with a header row
+func (r *Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"row", "key", "column", "value", "rule", "message"}); err != nil {
+		return err
+	}
+	for _, e := range r.Errors {
+		val, err := json.Marshal(e.Value)
+		if err != nil {
+			return err
+		}
+		if err := cw.Write([]string{strconv.Itoa(e.Row), e.Key, e.Column, string(val), e.Rule, e.Message}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// EntryReader validates each entry read from r against schema. maxErrors caps
+// the number of errors recorded, errors past the cap are still counted.
+// a maxErrors of zero or less records all errors
+func EntryReader(r dsio.EntryReader, schema *jsonschema.RootSchema, maxErrors int) (*Report, error) {
+	v, err := NewValidator(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Summary: map[string]int{}}
+	for {
+		ent, err := r.ReadEntry()
+		if err != nil {
+			if err.Error() == "EOF" {
+				break
+			}
+			return report, fmt.Errorf("reading entry %d: %s", report.Entries, err.Error())
+		}
+		report.Entries++
+
+		for _, e := range v.ValidateEntry(ent) {
+			report.ErrCount++
+			report.Summary[e.Rule]++
+			if maxErrors > 0 && len(report.Errors) >= maxErrors {
+				report.Truncated = true
+				continue
+			}
+			report.Errors = append(report.Errors, e)
+		}
+	}
+	return report, nil
+}
+
+// Validator checks entries against a schema. Each entry is validated against
+// the whole row schema, so keywords that depend on their siblings (like
+// "additionalProperties" & "properties") and references keep working.
+// Errors are located by the path to the value that broke a rule
+type Validator struct {
+	// row is the schema entries are validated against, nil if the schema
+	// doesn't describe rows
+	row *jsonschema.RootSchema
+	// titles maps array row column indexes to column titles
+	titles map[string]string
+	// tupleLen is the number of columns array rows declare with a list of
+	// "items", -1 if items aren't a list
+	tupleLen int
+	// properties is the set of keys object rows declare
+	properties map[string]bool
+	// closedArray & closedObject are true when rows can't have columns they
+	// don't declare: "additionalItems" or "additionalProperties" is false
+	closedArray, closedObject bool
+}
+
+// NewValidator creates a validator from a schema. For array bodies, rows are
+// validated against the schema's "items", for object bodies against
+// "additionalProperties"
+func NewValidator(schema *jsonschema.RootSchema) (*Validator, error) {
+	v := &Validator{titles: map[string]string{}, tupleLen: -1, properties: map[string]bool{}}
+	if schema == nil {
+		return v, nil
+	}
+
+	sch, err := schemaMap(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	row, _ := sch["items"].(map[string]interface{})
+	if row == nil {
+		row, _ = sch["additionalProperties"].(map[string]interface{})
+	}
+	if row == nil {
+		return v, nil
+	}
+
+	// rows are validated against their schema alone, carry definitions over
+	// so references to them still resolve
+	if defs, ok := sch["definitions"]; ok {
+		if _, ok := row["definitions"]; !ok {
+			row["definitions"] = defs
+		}
+	}
+	data, err := json.Marshal(row)
+	if err != nil {
+		return nil, fmt.Errorf("encoding row schema: %s", err.Error())
+	}
+	v.row = &jsonschema.RootSchema{}
+	if err := v.row.UnmarshalJSON(data); err != nil {
+		return nil, fmt.Errorf("invalid row schema: %s", err.Error())
+	}
+
+	if items, ok := row["items"].([]interface{}); ok {
+		v.tupleLen = len(items)
+		for i, it := range items {
+			col, _ := it.(map[string]interface{})
+			if title, _ := col["title"].(string); title != "" {
+				v.titles[strconv.Itoa(i)] = title
+			}
+		}
+	}
+	if props, ok := row["properties"].(map[string]interface{}); ok {
+		for key := range props {
+			v.properties[key] = true
+		}
+	}
+	v.closedArray = row["additionalItems"] == false
+	v.closedObject = row["additionalProperties"] == false
+
+	return v, nil
+}
+
+// ValidateEntry checks a single entry
+func (v *Validator) ValidateEntry(ent dsio.Entry) (errs []Error) {
+	if v.row == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(ent.Value)
+	if err != nil {
+		return []Error{{Row: ent.Index, Key: ent.Key, Value: ent.Value, Rule: "encoding", Message: err.Error()}}
+	}
+	verrs, err := v.row.ValidateBytes(data)
+	if err != nil {
+		return []Error{{Row: ent.Index, Key: ent.Key, Value: ent.Value, Rule: "encoding", Message: err.Error()}}
+	}
+
+	for _, verr := range verrs {
+		column, nested := v.column(verr.PropertyPath)
+		e := Error{
+			Row:     ent.Index,
+			Key:     ent.Key,
+			Column:  column,
+			Value:   verr.InvalidValue,
+			Rule:    ruleForMessage(verr.Message),
+			Message: verr.Message,
+		}
+		if column == "" {
+			e.Value = ent.Value
+		}
+		// values of undeclared columns are checked against a false schema,
+		// which reports the rule as "not"
+		if e.Rule == "not" && !nested {
+			if rule := v.undeclared(ent.Value, verr.PropertyPath); rule != "" {
+				e.Rule = rule
+				e.Message = "column isn't allowed by the schema"
+			}
+		}
+		errs = append(errs, e)
+	}
+
+	// jsonschema checks keywords in no particular order, sort errors so
+	// reports are stable: whole-row errors first, then by column
+	sort.SliceStable(errs, func(i, j int) bool {
+		if errs[i].Column == errs[j].Column {
+			return errs[i].Rule < errs[j].Rule
+		}
+		return columnLess(errs[i].Column, errs[j].Column)
+	})
+	return errs
+}
+
+// column gives the column title for an error's property path, a json pointer
+// to the value that broke a rule. values nested inside a column are given as
+// the column title followed by the rest of the path, and nested is true
+func (v *Validator) column(path string) (title string, nested bool) {
+	path = strings.TrimPrefix(path, "/")
+	if path == "" {
+		return "", false
+	}
+	segments := strings.SplitN(path, "/", 2)
+	title = segments[0]
+	if t, ok := v.titles[title]; ok {
+		title = t
+	}
+	if len(segments) > 1 {
+		return title + "/" + segments[1], true
+	}
+	return title, false
+}
+
+// undeclared gives the keyword that refuses the column at path if the row
+// schema doesn't allow it, and an empty string otherwise
+func (v *Validator) undeclared(row interface{}, path string) string {
+	key := strings.TrimPrefix(path, "/")
+	switch row.(type) {
+	case []interface{}:
+		if i, err := strconv.Atoi(key); err == nil && v.closedArray && v.tupleLen >= 0 && i >= v.tupleLen {
+			return "additionalItems"
+		}
+	case map[string]interface{}:
+		if v.closedObject && !v.properties[key] {
+			return "additionalProperties"
+		}
+	}
+	return ""
+}
+
+// columnLess orders columns, whole-row errors (an empty column) first.
+// numeric columns are ordered by index
+func columnLess(a, b string) bool {
+	if a == "" || b == "" {
+		return a == ""
+	}
+	ai, aerr := strconv.Atoi(a)
+	bi, berr := strconv.Atoi(b)
+	if aerr == nil && berr == nil {
+		return ai < bi
+	}
+	return a < b
+}
+
+// messageRules maps the start of jsonschema error messages to the keyword
+// that produced them. longer prefixes are listed before shorter prefixes
+// they start with
+var messageRules = []struct {
+	prefix, rule string
+}{
+	{"type should be", "type"},
+	{"should be one of", "enum"},
+	{"must equal", "const"},
+	{"must be a multiple of", "multipleOf"},
+	{"must be less than or equal to", "maximum"},
+	{"must be less than", "exclusiveMaximum"},
+	{"must be greater than or equal to", "minimum"},
+	{"must be greater than", "exclusiveMinimum"},
+	{"max length of", "maxLength"},
+	{"min length of", "minLength"},
+	{"regexp pattrn", "pattern"},
+	{"invalid property path", "schema"},
+	{"invalid ", "format"},
+	{"array items must be unique", "uniqueItems"},
+	{"must contain at least one of", "contains"},
+	{"did Not match any specified AnyOf", "anyOf"},
+	{"matched more than one specified OneOf", "oneOf"},
+	{"did not match any of the specified OneOf", "oneOf"},
+	{"cannot match schema", "not"},
+	{"Dependency property", "dependencies"},
+}
+
+// ruleForMessage names the schema keyword a jsonschema error message comes
+// from. jsonschema errors don't record their keyword, so it's inferred from
+// the message
+func ruleForMessage(msg string) string {
+	for _, mr := range messageRules {
+		if strings.HasPrefix(msg, mr.prefix) {
+			return mr.rule
+		}
+	}
+	switch {
+	case strings.HasPrefix(msg, "array length") && strings.Contains(msg, "exceeds"):
+		return "maxItems"
+	case strings.HasPrefix(msg, "array length") && strings.Contains(msg, "minimum items"):
+		return "minItems"
+	case strings.Contains(msg, "object Properties exceed"):
+		return "maxProperties"
+	case strings.Contains(msg, "object Properties below"):
+		return "minProperties"
+	case strings.HasSuffix(msg, "value is required"):
+		return "required"
+	}
+	return "schema"
+}
+
+func schemaMap(schema *jsonschema.RootSchema) (map[string]interface{}, error) {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("encoding schema: %s", err.Error())
+	}
+	sch := map[string]interface{}{}
+	if err := json.Unmarshal(data, &sch); err != nil {
+		return nil, fmt.Errorf("decoding schema: %s", err.Error())
+	}
+	return sch, nil
+}
@@ -0,0 +1,163 @@
+package validation
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/qri-io/dataset"
+	"github.com/qri-io/dataset/dsio"
+	"github.com/qri-io/jsonschema"
+)
+
+var citiesSchema = jsonschema.Must(`{
+  "type": "array",
+  "items": {
+    "type": "array",
+    "minItems": 2,
+    "items": [
+      { "title": "city", "type": "string" },
+      { "title": "pop", "type": "integer", "minimum": 0 }
+    ]
+  }
+}`)
+
+func TestEntryReader(t *testing.T) {
+	body := `[
+    ["toronto", 40000000],
+    ["new york", "lots"],
+    ["chatham", -1],
+    ["atlantis"]
+  ]`
+
+	st := &dataset.Structure{Format: dataset.JSONDataFormat, Schema: citiesSchema}
+	r, err := dsio.NewEntryReader(st, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := EntryReader(r, citiesSchema, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Entries != 4 {
+		t.Errorf("expected 4 entries, got: %d", report.Entries)
+	}
+
+	expect := []struct {
+		row          int
+		column, rule string
+	}{
+		{1, "pop", "type"},
+		{2, "pop", "minimum"},
+		{3, "", "minItems"},
+	}
+	if len(report.Errors) != len(expect) {
+		t.Fatalf("expected %d errors, got: %d. %v", len(expect), len(report.Errors), report.Errors)
+	}
+	for i, e := range expect {
+		got := report.Errors[i]
+		if got.Row != e.row || got.Column != e.column || got.Rule != e.rule {
+			t.Errorf("error %d mismatch. expected: row %d, column '%s', rule %s. got: row %d, column '%s', rule %s", i, e.row, e.column, e.rule, got.Row, got.Column, got.Rule)
+		}
+	}
+
+	if report.Summary["type"] != 1 || report.Summary["minimum"] != 1 || report.Summary["minItems"] != 1 {
+		t.Errorf("unexpected summary: %v", report.Summary)
+	}
+}
+
+func TestEntryReaderSiblingKeywords(t *testing.T) {
+	schema := jsonschema.Must(`{
+  "type": "array",
+  "definitions": {
+    "population": { "type": "integer", "minimum": 0 }
+  },
+  "items": {
+    "type": "object",
+    "required": ["city"],
+    "additionalProperties": false,
+    "properties": {
+      "city": { "type": "string" },
+      "pop": { "$ref": "#/definitions/population" },
+      "location": {
+        "type": "object",
+        "properties": {
+          "lat": { "type": "number", "maximum": 90 }
+        }
+      }
+    }
+  }
+}`)
+	body := `[
+    { "city": "toronto", "pop": 40000000, "location": { "lat": 43.6 } },
+    { "city": "chatham", "pop": -1, "mayor": "someone" },
+    { "pop": 10, "location": { "lat": 200 } }
+  ]`
+
+	st := &dataset.Structure{Format: dataset.JSONDataFormat, Schema: schema}
+	r, err := dsio.NewEntryReader(st, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := EntryReader(r, schema, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []struct {
+		row          int
+		column, rule string
+	}{
+		{1, "mayor", "additionalProperties"},
+		{1, "pop", "minimum"},
+		{2, "", "required"},
+		{2, "location/lat", "maximum"},
+	}
+	if len(report.Errors) != len(expect) {
+		t.Fatalf("expected %d errors, got: %d. %v", len(expect), len(report.Errors), report.Errors)
+	}
+	for i, e := range expect {
+		got := report.Errors[i]
+		if got.Row != e.row || got.Column != e.column || got.Rule != e.rule {
+			t.Errorf("error %d mismatch. expected: row %d, column '%s', rule %s. got: row %d, column '%s', rule %s", i, e.row, e.column, e.rule, got.Row, got.Column, got.Rule)
+		}
+	}
+}
+
+func TestEntryReaderMaxErrors(t *testing.T) {
+	body := `[["a", -1], ["b", -2], ["c", -3]]`
+	st := &dataset.Structure{Format: dataset.JSONDataFormat, Schema: citiesSchema}
+	r, err := dsio.NewEntryReader(st, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := EntryReader(r, citiesSchema, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Errors) != 2 || report.ErrCount != 3 || !report.Truncated {
+		t.Errorf("expected 2 of 3 errors to be recorded, got %d of %d", len(report.Errors), report.ErrCount)
+	}
+	if report.Summary["minimum"] != 3 {
+		t.Errorf("expected summary to count all errors, got: %v", report.Summary)
+	}
+}
+
+func TestReportWriteCSV(t *testing.T) {
+	report := &Report{
+		Errors: []Error{
+			{Row: 1, Column: "pop", Value: "lots", Rule: "type", Message: "type should be integer"},
+		},
+	}
+	buf := &bytes.Buffer{}
+	if err := report.WriteCSV(buf); err != nil {
+		t.Fatal(err)
+	}
+	expect := "row,key,column,value,rule,message\n1,,pop,\"\"\"lots\"\"\",type,type should be integer\n"
+	if buf.String() != expect {
+		t.Errorf("csv mismatch. expected:\n%s\ngot:\n%s", expect, buf.String())
+	}
+}